@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/proxymanager"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/list"
+)
+
+// dryRun function prints the providers and the proxies that would be created
+// from the configuration, and returns the errors of the list files, including
+// hostname conflicts between lists. Proxies of Docker, Kubernetes and Podman
+// providers are only known at runtime.
+func dryRun(w io.Writer) error {
+	var errs error
+
+	// owner list of each hostname
+	hostnames := make(map[string]string)
+	cfg := config.Get()
+
+	fmt.Fprintln(w, "Proxy providers:")
+	for _, name := range slices.Sorted(maps.Keys(cfg.Tailscale.Providers)) {
+		if name == cfg.DefaultProxyProvider {
+			fmt.Fprintf(w, "  %s (default)\n", name)
+			continue
+		}
+		fmt.Fprintf(w, "  %s\n", name)
+	}
+
+	fmt.Fprintln(w, "Target providers:")
+	for _, name := range slices.Sorted(maps.Keys(cfg.Docker)) {
+		fmt.Fprintf(w, "  docker %s: proxies from containers of %s\n", name, cfg.Docker[name].Host)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Kubernetes)) {
+		fmt.Fprintf(w, "  kubernetes %s: proxies from annotated services and ingresses\n", name)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Podman)) {
+		fmt.Fprintf(w, "  podman %s: proxies from containers of %s\n", name, cfg.Podman[name].Host)
+	}
+	for _, name := range slices.Sorted(maps.Keys(cfg.Lists)) {
+		fmt.Fprintf(w, "  list %s: %s\n", name, cfg.Lists[name].Filename)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Lists)) {
+		// errors are returned by Validate, don't log them twice
+		c, err := list.New(zerolog.Nop(), name, cfg.Lists[name])
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("list %s: %w", name, err))
+			continue
+		}
+
+		errs = errors.Join(errs, c.Validate())
+
+		for _, id := range c.Names() {
+			pcfg, err := c.AddTarget(id)
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("list %s: %w", name, err))
+				continue
+			}
+			printProxy(w, pcfg)
+
+			owner, used := hostnames[pcfg.Hostname]
+			switch {
+			case !used:
+				hostnames[pcfg.Hostname] = name
+			case cfg.HostnameConflict == config.HostnameConflictSuffix:
+				fmt.Fprintf(w, "  hostname used by list %s, a suffix will be added\n", owner)
+			default:
+				errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: %w: used by list %s",
+					name, id, proxymanager.ErrHostnameConflict, owner))
+			}
+		}
+	}
+
+	return errs
+}
+
+// printProxy function prints a proxy with its ports and targets.
+func printProxy(w io.Writer, pcfg *model.Config) {
+	fmt.Fprintf(w, "Proxy %s (target provider %s, proxy provider %s)\n",
+		pcfg.Hostname, pcfg.TargetProvider, pcfg.ProxyProvider)
+
+	for _, name := range slices.Sorted(maps.Keys(pcfg.Ports)) {
+		port := pcfg.Ports[name]
+
+		targets := make([]string, 0, len(port.GetTargets()))
+		for _, target := range port.GetTargets() {
+			targets = append(targets, target.String())
+		}
+
+		kind := ""
+		if port.IsRedirect {
+			kind = " (redirect)"
+		}
+		fmt.Fprintf(w, "  %s -> %s%s\n", port.String(), strings.Join(targets, ", "), kind)
+
+		for _, route := range port.Routes {
+			routeTargets := make([]string, 0, len(route.GetTargets()))
+			for _, target := range route.GetTargets() {
+				routeTargets = append(routeTargets, target.String())
+			}
+			fmt.Fprintf(w, "    %s -> %s\n", route.Path, strings.Join(routeTargets, ", "))
+		}
+	}
+}
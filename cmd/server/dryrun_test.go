@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/proxymanager"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/list"
+)
+
+// testConfig is the configuration of the tests, with the data directory and
+// the two list files.
+const testConfig = `
+tailscale:
+  dataDir: %[1]s
+  providers:
+    default: {}
+hostnameConflict: %[2]s
+lists:
+  media:
+    filename: %[1]s/media.yaml
+  nas:
+    filename: %[1]s/nas.yaml
+`
+
+// loadTestConfig function writes the list files and loads the configuration.
+func loadTestConfig(t *testing.T, hostnameConflict, media, nas string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"tsdproxy.yaml": fmt.Sprintf(testConfig, dir, hostnameConflict),
+		"media.yaml":    media,
+		"nas.yaml":      nas,
+	}
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := config.LoadFile(filepath.Join(dir, "tsdproxy.yaml")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+const (
+	mediaList = `
+media:
+  ports:
+    443/https:
+      targets:
+        - http://192.168.1.10:3789
+      routes:
+        - path: /api
+          targets:
+            - http://192.168.1.11:8080
+`
+	nasList = `
+media:
+  ports:
+    443/https:
+      targets:
+        - http://nas.local:5001
+`
+)
+
+func TestDryRun(t *testing.T) {
+	loadTestConfig(t, config.HostnameConflictSuffix, mediaList, "")
+
+	var out bytes.Buffer
+	if err := dryRun(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{
+		"  default (default)\n",
+		"  list media: ",
+		"Proxy media (target provider media, proxy provider default)\n",
+		"  443/https -> http://192.168.1.10:3789\n",
+		"    /api -> http://192.168.1.11:8080\n",
+	} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("output doesn't contain %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestDryRunErrors(t *testing.T) {
+	loadTestConfig(t, config.HostnameConflictError, mediaList, nasList+`
+video:
+  ports:
+    443/https:
+      loadBalance: fastest
+      targets:
+        - video.local
+`)
+
+	var out bytes.Buffer
+	err := dryRun(&out)
+
+	if !errors.Is(err, proxymanager.ErrHostnameConflict) {
+		t.Errorf("hostname conflict not reported: %v", err)
+	}
+	if !errors.Is(err, list.ErrInvalidLoadBalance) || !errors.Is(err, list.ErrInvalidTarget) {
+		t.Errorf("invalid port not reported: %v", err)
+	}
+}
+
+func TestDryRunSuffix(t *testing.T) {
+	loadTestConfig(t, config.HostnameConflictSuffix, mediaList, nasList)
+
+	var out bytes.Buffer
+	if err := dryRun(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out.String(), "hostname used by list media, a suffix will be added") {
+		t.Errorf("hostname conflict not reported:\n%s", out.String())
+	}
+}
@@ -10,53 +10,80 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/rs/zerolog"
 
 	"github.com/xybydy/tsdproxy/internal/consts"
 
+	"github.com/xybydy/tsdproxy/internal/api"
 	"github.com/xybydy/tsdproxy/internal/config"
 	"github.com/xybydy/tsdproxy/internal/core"
 	"github.com/xybydy/tsdproxy/internal/dashboard"
+	"github.com/xybydy/tsdproxy/internal/history"
+	"github.com/xybydy/tsdproxy/internal/metrics"
 	pm "github.com/xybydy/tsdproxy/internal/proxymanager"
+	"github.com/xybydy/tsdproxy/internal/tracing"
 )
 
 type WebApp struct {
-	Log          zerolog.Logger
-	HTTP         *core.HTTPServer
-	Health       *core.Health
-	ProxyManager *pm.ProxyManager
-	Dashboard    *dashboard.Dashboard
-	cancel       context.CancelFunc
+	Log             zerolog.Logger
+	HTTP            *core.HTTPServer
+	Health          *core.Health
+	ProxyManager    *pm.ProxyManager
+	Dashboard       *dashboard.Dashboard
+	API             *api.API
+	History         *history.Store
+	cancel          context.CancelFunc
+	shutdownTracing tracing.Shutdown
 }
 
 func InitializeApp() (*WebApp, error) {
-	err := config.InitializeConfig()
+	logger := core.NewLog()
+
+	// init OpenTelemetry tracing
+	//
+	shutdownTracing, err := tracing.Init(context.Background(), config.Get().Tracing)
 	if err != nil {
 		return nil, err
 	}
-	logger := core.NewLog()
 
 	httpServer := core.NewHTTPServer(logger)
 	httpServer.Use(core.SessionMiddleware)
 
 	health := core.NewHealthHandler(httpServer, logger)
 
+	// open proxies history, proxies run without history on error
+	//
+	historyStore, err := history.Open(logger,
+		filepath.Join(config.Get().Tailscale.DataDir, history.Filename), history.DefaultMaxEvents)
+	if err != nil {
+		logger.Error().Err(err).Msg("Proxy history disabled")
+		historyStore = nil
+	}
+
 	// Start ProxyManager
 	//
-	proxymanager := pm.NewProxyManager(logger)
+	proxymanager := pm.NewProxyManager(logger, historyStore)
+
+	// init management API
+	//
+	managementAPI := api.NewAPI(httpServer, logger, proxymanager)
 
-	// init Dashboard
+	// init Dashboard, with the protection of the management API
 	//
-	dash := dashboard.NewDashboard(httpServer, logger, proxymanager)
+	dash := dashboard.NewDashboard(httpServer, logger, proxymanager, managementAPI.Protect)
 
 	webApp := &WebApp{
-		Log:          logger,
-		HTTP:         httpServer,
-		Health:       health,
-		ProxyManager: proxymanager,
-		Dashboard:    dash,
+		Log:             logger,
+		HTTP:            httpServer,
+		Health:          health,
+		ProxyManager:    proxymanager,
+		Dashboard:       dash,
+		API:             managementAPI,
+		History:         historyStore,
+		shutdownTracing: shutdownTracing,
 	}
 	return webApp, nil
 }
@@ -65,6 +92,21 @@ func main() {
 	fmt.Println("Initializing server")
 	fmt.Println("Version", core.GetVersion())
 
+	if err := config.InitializeConfig(); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// validate the configuration without starting the proxies
+	if config.DryRun() {
+		if err := dryRun(os.Stdout); err != nil {
+			fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Configuration is valid")
+		return
+	}
+
 	app, err := InitializeApp()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "error: %v\n", err)
@@ -104,8 +146,9 @@ func (app *WebApp) Start(ctx context.Context) {
 	go func() {
 		app.Log.Info().Msg("Initializing WebServer")
 
+		httpConfig := config.Get().HTTP
 		srv := http.Server{
-			Addr:              fmt.Sprintf("%s:%d", config.Config.HTTP.Hostname, config.Config.HTTP.Port),
+			Addr:              fmt.Sprintf("%s:%d", httpConfig.Hostname, httpConfig.Port),
 			ReadHeaderTimeout: core.ReadHeaderTimeout,
 		}
 
@@ -124,9 +167,14 @@ func (app *WebApp) Start(ctx context.Context) {
 	// Start watching docker events with cancelable context
 	app.ProxyManager.WatchEvents(ctx)
 
+	// Reload providers on configuration file changes
+	config.WatchConfig(app.ProxyManager.Reload)
+
 	// Add Routes
 	app.Dashboard.AddRoutes()
+	app.API.AddRoutes()
 	core.PprofAddRoutes(app.HTTP)
+	metrics.AddRoutes(app.HTTP)
 }
 
 func (app *WebApp) Stop() {
@@ -140,5 +188,15 @@ func (app *WebApp) Stop() {
 
 	app.HTTP.Shutdown()
 
+	if app.History != nil {
+		if err := app.History.Close(); err != nil {
+			app.Log.Error().Err(err).Msg("Error closing proxy history")
+		}
+	}
+
+	if err := app.shutdownTracing(context.Background()); err != nil {
+		app.Log.Error().Err(err).Msg("Tracing shutdown failed")
+	}
+
 	app.Log.Info().Msg("Server was shutdown successfully")
 }
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	apiPrefix      = "/api/v1"
+	headerRequest  = "X-Tsdproxy-Request"
+	requestTimeout = 10 * time.Second
+	eventsDataLine = "data: "
+)
+
+type (
+	// client struct is a client of the TSDProxy management API.
+	client struct {
+		http    *http.Client
+		baseURL string
+		token   string
+	}
+
+	// proxy struct is the part of the API proxy used by the commands.
+	proxy struct {
+		Name        string `json:"name"`
+		Status      string `json:"status"`
+		URL         string `json:"url"`
+		AuthURL     string `json:"authUrl"`
+		Error       string `json:"error"`
+		Maintenance bool   `json:"maintenance"`
+	}
+
+	// event struct is a proxy status event of the events stream.
+	event struct {
+		Time    time.Time `json:"time"`
+		Proxy   string    `json:"proxy"`
+		Status  string    `json:"status"`
+		Port    string    `json:"port"`
+		Target  string    `json:"target"`
+		Health  string    `json:"health"`
+		AuthURL string    `json:"authUrl"`
+		Error   string    `json:"error"`
+		Attempt int       `json:"attempt"`
+	}
+
+	// shareLink struct is a one-time Funnel share link.
+	shareLink struct {
+		Expires time.Time `json:"expires"`
+		URL     string    `json:"url"`
+		Port    string    `json:"port"`
+	}
+
+	// cachePurge struct is the result of a cache purge.
+	cachePurge struct {
+		Purged int `json:"purged"`
+	}
+
+	// apiError struct is the body of the API errors.
+	apiError struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	}
+)
+
+var ErrRequest = errors.New("request failed")
+
+// newClient function returns a client of the server at baseURL. The token is
+// sent to the API if not empty.
+func newClient(baseURL, token string) *client {
+	return &client{
+		http:    &http.Client{},
+		baseURL: strings.TrimRight(baseURL, "/") + apiPrefix,
+		token:   token,
+	}
+}
+
+// listProxies method returns all proxies.
+func (c *client) listProxies(ctx context.Context) ([]proxy, error) {
+	var proxies []proxy
+
+	err := c.do(ctx, http.MethodGet, "/proxies", &proxies)
+
+	return proxies, err
+}
+
+// restartProxy method requests the restart of a proxy.
+func (c *client) restartProxy(ctx context.Context, name string) error {
+	return c.do(ctx, http.MethodPost, "/proxies/"+url.PathEscape(name)+"/restart", nil)
+}
+
+// setMaintenance method enables or disables the maintenance mode of a proxy.
+func (c *client) setMaintenance(ctx context.Context, name string, enabled bool) error {
+	method := http.MethodDelete
+	if enabled {
+		method = http.MethodPost
+	}
+
+	return c.do(ctx, method, "/proxies/"+url.PathEscape(name)+"/maintenance", nil)
+}
+
+// createShareLink method creates a one-time share link of a Funnel port of a
+// proxy. The port can be empty if only one port has share links enabled.
+func (c *client) createShareLink(ctx context.Context, name, port string) (shareLink, error) {
+	var link shareLink
+
+	path := "/proxies/" + url.PathEscape(name) + "/share"
+	if port != "" {
+		path += "?" + url.Values{"port": {port}}.Encode()
+	}
+	err := c.do(ctx, http.MethodPost, path, &link)
+
+	return link, err
+}
+
+// purgeCache method removes the cached responses of a proxy, of all ports if
+// port is empty and of all paths if path is empty. Returns the number of
+// responses removed.
+func (c *client) purgeCache(ctx context.Context, name, port, path string) (int, error) {
+	var result cachePurge
+
+	query := url.Values{}
+	if port != "" {
+		query.Set("port", port)
+	}
+	if path != "" {
+		query.Set("path", path)
+	}
+	u := "/proxies/" + url.PathEscape(name) + "/cache"
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	err := c.do(ctx, http.MethodDelete, u, &result)
+
+	return result.Purged, err
+}
+
+// streamEvents method calls fn for each event of the events stream, until the
+// context is canceled or the server closes the stream.
+func (c *client) streamEvents(ctx context.Context, fn func(event)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), eventsDataLine)
+		if !ok {
+			continue
+		}
+
+		var e event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return fmt.Errorf("invalid event: %w", err)
+		}
+		fn(e)
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+
+	return scanner.Err()
+}
+
+// do method sends a request to the API and decodes the response into v, if not nil.
+func (c *client) do(ctx context.Context, method, path string, v any) error {
+	ctx, cancel := context.WithTimeout(ctx, requestTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	c.authorize(req)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp); err != nil {
+		return err
+	}
+
+	if v == nil {
+		return nil
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid response: %w", err)
+	}
+
+	return nil
+}
+
+// authorize method adds the headers required by the routes that change the
+// proxies.
+func (c *client) authorize(req *http.Request) {
+	req.Header.Set(headerRequest, "1")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// checkResponse function returns the API error of unsuccessful responses.
+func checkResponse(resp *http.Response) error {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	var e apiError
+	if err := json.Unmarshal(body, &e); err == nil && e.Message != "" {
+		return fmt.Errorf("%w: %s", ErrRequest, e.Message)
+	}
+
+	return fmt.Errorf("%w: %s", ErrRequest, resp.Status)
+}
@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const testToken = "secret"
+
+// fakeAPI struct is a management API that records the requests.
+type fakeAPI struct {
+	requests []string
+	mtx      sync.Mutex
+}
+
+// startFakeAPI function starts a fake management API and returns a client of it.
+func startFakeAPI(t *testing.T) (*client, *fakeAPI) {
+	t.Helper()
+
+	api := &fakeAPI{}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/proxies", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode([]proxy{
+			{Name: "web", Status: "Running", URL: "https://web.example.ts.net"},
+			{Name: "api", Status: statusAuth, AuthURL: "https://login.tailscale.com/a/1"},
+		})
+	})
+	mux.HandleFunc("POST /api/v1/proxies/{name}/share", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(shareLink{URL: "https://web.example.ts.net/?share=1", Port: r.URL.Query().Get("port")})
+	})
+	mux.HandleFunc("DELETE /api/v1/proxies/{name}/cache", func(w http.ResponseWriter, _ *http.Request) {
+		_ = json.NewEncoder(w).Encode(cachePurge{Purged: 3}) //nolint:mnd
+	})
+	mux.HandleFunc("/api/v1/proxies/{name}/{action}", func(w http.ResponseWriter, r *http.Request) {
+		if r.PathValue("name") != "web" {
+			w.WriteHeader(http.StatusNotFound)
+			_ = json.NewEncoder(w).Encode(apiError{Code: http.StatusNotFound, Message: "proxy not found"})
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	})
+	mux.HandleFunc("GET /api/v1/events", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, ": connected\n\n")
+		fmt.Fprint(w, `data: {"proxy":"web","status":"Running"}`+"\n\n")
+		fmt.Fprint(w, `data: {"proxy":"web","status":"Restarting","attempt":2}`+"\n\n")
+	})
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		api.mtx.Lock()
+		api.requests = append(api.requests, fmt.Sprintf("%s %s %s %s",
+			r.Method, r.URL.RequestURI(), r.Header.Get(headerRequest), r.Header.Get("Authorization")))
+		api.mtx.Unlock()
+
+		mux.ServeHTTP(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	return newClient(srv.URL+"/", testToken), api
+}
+
+// last method returns the last request received.
+func (api *fakeAPI) last() string {
+	api.mtx.Lock()
+	defer api.mtx.Unlock()
+
+	if len(api.requests) == 0 {
+		return ""
+	}
+
+	return api.requests[len(api.requests)-1]
+}
+
+func TestClient(t *testing.T) {
+	c, api := startFakeAPI(t)
+	ctx := context.Background()
+	auth := " 1 Bearer " + testToken
+
+	proxies, err := c.listProxies(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(proxies) != 2 || proxies[1].AuthURL == "" {
+		t.Errorf("proxies: got %+v", proxies)
+	}
+
+	if err := c.restartProxy(ctx, "web"); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.last(); got != "POST /api/v1/proxies/web/restart"+auth {
+		t.Errorf("restart request: got %q", got)
+	}
+
+	if err := c.setMaintenance(ctx, "web", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := api.last(); got != "DELETE /api/v1/proxies/web/maintenance"+auth {
+		t.Errorf("maintenance request: got %q", got)
+	}
+
+	link, err := c.createShareLink(ctx, "web", "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Port != "admin" || link.URL == "" {
+		t.Errorf("share link: got %+v", link)
+	}
+
+	purged, err := c.purgeCache(ctx, "web", "admin", "/static")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := api.last(); purged != 3 || got != "DELETE /api/v1/proxies/web/cache?path=%2Fstatic&port=admin"+auth {
+		t.Errorf("purge: got %d, %q", purged, got)
+	}
+
+	// the message of API errors is returned
+	err = c.restartProxy(ctx, "missing")
+	if !errors.Is(err, ErrRequest) || !strings.Contains(err.Error(), "proxy not found") {
+		t.Errorf("unknown proxy: got %v", err)
+	}
+}
+
+func TestClientEvents(t *testing.T) {
+	c, _ := startFakeAPI(t)
+
+	var events []event
+	if err := c.streamEvents(context.Background(), func(e event) { events = append(events, e) }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 || events[0].Status != "Running" || events[1].Attempt != 2 {
+		t.Errorf("events: got %+v", events)
+	}
+}
+
+func TestCommandArgs(t *testing.T) {
+	c, api := startFakeAPI(t)
+
+	tests := map[string][]string{
+		"list":        {"web"},
+		"restart":     {},
+		"maintenance": {"web", "maybe"},
+		"share":       {"web", "admin", "extra"},
+		"purge":       {},
+		"validate":    {"a.yaml", "b.yaml"},
+	}
+
+	for _, cmd := range commands {
+		args, ok := tests[cmd.name]
+		if !ok {
+			continue
+		}
+		if err := cmd.run(context.Background(), c, args); !errors.Is(err, ErrInvalidArgs) {
+			t.Errorf("%s %v: got %v", cmd.name, args, err)
+		}
+	}
+
+	if got := api.last(); got != "" {
+		t.Errorf("request sent with invalid arguments: %q", got)
+	}
+}
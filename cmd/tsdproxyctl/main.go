@@ -0,0 +1,285 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"text/tabwriter"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+)
+
+const (
+	defaultServer = "http://127.0.0.1:8080"
+	serverEnv     = "TSDPROXY_SERVER"
+	tokenEnv      = "TSDPROXY_API_TOKEN"
+	statusAuth    = "Authenticating"
+	exitUsage     = 2
+)
+
+type command struct {
+	run   func(ctx context.Context, c *client, args []string) error
+	name  string
+	args  string
+	usage string
+}
+
+var ErrInvalidArgs = errors.New("invalid arguments")
+
+var commands = []command{
+	{name: "list", usage: "list proxies with status and URL", run: listCmd},
+	{name: "events", usage: "print proxy status events as they happen", run: eventsCmd},
+	{name: "restart", args: "<proxy>", usage: "restart a proxy", run: restartCmd},
+	{name: "maintenance", args: "<proxy> on|off", usage: "enable or disable the maintenance page of a proxy", run: maintenanceCmd},
+	{name: "share", args: "<proxy> [port]", usage: "create a one-time share link of a funnel port", run: shareCmd},
+	{name: "purge", args: "<proxy> [port [path]]", usage: "remove the cached responses of a proxy", run: purgeCmd},
+	{name: "auth", usage: "print the auth URLs of proxies waiting for authentication", run: authCmd},
+	{name: "validate", args: "[file]", usage: "validate a configuration file offline", run: validateCmd},
+}
+
+func main() {
+	server := os.Getenv(serverEnv)
+	if server == "" {
+		server = defaultServer
+	}
+
+	token := os.Getenv(tokenEnv)
+
+	flag.StringVar(&server, "server", server, "address of the TSDProxy server (env "+serverEnv+")")
+	flag.StringVar(&token, "token", token, "token of the management API (env "+tokenEnv+")")
+	flag.Usage = usage
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		usage()
+		os.Exit(exitUsage)
+	}
+
+	name := flag.Arg(0)
+	for _, cmd := range commands {
+		if cmd.name != name {
+			continue
+		}
+
+		ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		err := cmd.run(ctx, newClient(server, token), flag.Args()[1:])
+		cancel()
+
+		if errors.Is(err, ErrInvalidArgs) {
+			fmt.Fprintf(os.Stderr, "usage: tsdproxyctl %s %s\n", cmd.name, cmd.args)
+			os.Exit(exitUsage)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Fprintf(os.Stderr, "unknown command %q\n\n", name)
+	usage()
+	os.Exit(exitUsage)
+}
+
+// usage function prints the commands and flags.
+func usage() {
+	out := flag.CommandLine.Output()
+
+	fmt.Fprintln(out, "usage: tsdproxyctl [-server url] <command> [arguments]")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "commands:")
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0) //nolint:mnd
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %s %s\t%s\n", cmd.name, cmd.args, cmd.usage)
+	}
+	w.Flush()
+
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "flags:")
+	flag.PrintDefaults()
+}
+
+// listCmd function prints the proxies with status and URL.
+func listCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) != 0 {
+		return ErrInvalidArgs
+	}
+
+	proxies, err := c.listProxies(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+	fmt.Fprintln(w, "NAME\tSTATUS\tURL")
+	for _, p := range proxies {
+		url := p.URL
+		if p.Status == statusAuth {
+			url = p.AuthURL
+		}
+		status := p.Status
+		if p.Maintenance {
+			status += " (maintenance)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", p.Name, status, url)
+	}
+
+	return w.Flush()
+}
+
+// eventsCmd function prints the proxy status events until interrupted.
+func eventsCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) != 0 {
+		return ErrInvalidArgs
+	}
+
+	return c.streamEvents(ctx, func(e event) {
+		fields := []string{e.Time.Local().Format(time.DateTime), e.Proxy, e.Status}
+
+		switch {
+		case e.Target != "":
+			fields = append(fields, e.Port, e.Target, e.Health)
+		case e.Port != "":
+			fields = append(fields, e.Port)
+		}
+		if e.Attempt > 0 {
+			fields = append(fields, fmt.Sprintf("attempt=%d", e.Attempt))
+		}
+		if e.AuthURL != "" {
+			fields = append(fields, e.AuthURL)
+		}
+		if e.Error != "" {
+			fields = append(fields, "error="+e.Error)
+		}
+
+		fmt.Println(strings.Join(fields, " "))
+	})
+}
+
+// restartCmd function restarts a proxy.
+func restartCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) != 1 {
+		return ErrInvalidArgs
+	}
+
+	if err := c.restartProxy(ctx, args[0]); err != nil {
+		return err
+	}
+
+	fmt.Printf("restarting %s\n", args[0])
+
+	return nil
+}
+
+// maintenanceCmd function enables or disables the maintenance mode of a proxy.
+func maintenanceCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) != 2 || (args[1] != "on" && args[1] != "off") { //nolint:mnd
+		return ErrInvalidArgs
+	}
+
+	if err := c.setMaintenance(ctx, args[0], args[1] == "on"); err != nil {
+		return err
+	}
+
+	fmt.Printf("maintenance %s %s\n", args[1], args[0])
+
+	return nil
+}
+
+// shareCmd function prints a new one-time share link of a Funnel port.
+func shareCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return ErrInvalidArgs
+	}
+
+	port := ""
+	if len(args) == 2 { //nolint:mnd
+		port = args[1]
+	}
+
+	link, err := c.createShareLink(ctx, args[0], port)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(link.URL)
+	fmt.Fprintf(os.Stderr, "port %s, expires %s\n", link.Port, link.Expires.Local().Format(time.DateTime))
+
+	return nil
+}
+
+// purgeCmd function removes the cached responses of a proxy, of a port and
+// a path prefix if set.
+func purgeCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) < 1 || len(args) > 3 { //nolint:mnd
+		return ErrInvalidArgs
+	}
+
+	var port, path string
+	if len(args) > 1 {
+		port = args[1]
+	}
+	if len(args) > 2 { //nolint:mnd
+		path = args[2]
+	}
+
+	purged, err := c.purgeCache(ctx, args[0], port, path)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("purged %d cached responses of %s\n", purged, args[0])
+
+	return nil
+}
+
+// authCmd function prints the auth URLs of the proxies in Authenticating status.
+func authCmd(ctx context.Context, c *client, args []string) error {
+	if len(args) != 0 {
+		return ErrInvalidArgs
+	}
+
+	proxies, err := c.listProxies(ctx)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:mnd
+	for _, p := range proxies {
+		if p.Status == statusAuth && p.AuthURL != "" {
+			fmt.Fprintf(w, "%s\t%s\n", p.Name, p.AuthURL)
+		}
+	}
+
+	return w.Flush()
+}
+
+// validateCmd function validates a configuration file without a server.
+func validateCmd(_ context.Context, _ *client, args []string) error {
+	if len(args) > 1 {
+		return ErrInvalidArgs
+	}
+
+	file := "/config/tsdproxy.yaml"
+	if len(args) == 1 {
+		file = args[0]
+	}
+
+	if err := config.ValidateFile(file); err != nil {
+		return fmt.Errorf("invalid configuration %s: %w", file, err)
+	}
+
+	fmt.Printf("%s is valid\n", file)
+
+	return nil
+}
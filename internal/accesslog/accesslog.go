@@ -0,0 +1,176 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package accesslog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// contextKeyEntry is the context key of the access log entry of a request.
+const contextKeyEntry model.ContextKey = "contextkey.accesslog"
+
+// bytesPerMegabyte is the unit of the maximum size of the access log files.
+const bytesPerMegabyte = 1024 * 1024
+
+var ErrSyslogNotSupported = errors.New("syslog is not supported on this system")
+
+type (
+	// Logger struct writes the access log of the HTTP requests of a proxy.
+	Logger struct {
+		log    zerolog.Logger
+		out    io.WriteCloser
+		proxy  string
+		format string
+		mtx    sync.Mutex
+	}
+
+	// entry struct stores a request of the access log.
+	entry struct {
+		Time      time.Time `json:"time"`
+		Proxy     string    `json:"proxy"`
+		Port      string    `json:"port"`
+		Client    string    `json:"client"`
+		User      string    `json:"user,omitempty"`
+		Method    string    `json:"method"`
+		Host      string    `json:"host"`
+		URI       string    `json:"uri"`
+		Proto     string    `json:"proto"`
+		Referer   string    `json:"referer,omitempty"`
+		UserAgent string    `json:"userAgent,omitempty"`
+		Target    string    `json:"target,omitempty"`
+		Error     string    `json:"error,omitempty"`
+		Latency   float64   `json:"latency"`
+		Bytes     int64     `json:"bytes"`
+		Status    int       `json:"status"`
+	}
+)
+
+// IsEnabled function returns true if the access log of the proxy is enabled
+// and the access logs aren't disabled globally.
+func IsEnabled(cfg model.AccessLog) bool {
+	return cfg.Enabled && !config.Get().ProxyAccessLog.Disabled
+}
+
+// New function returns the access logger of a proxy. The settings not
+// configured in the proxy use the global defaults.
+func New(log zerolog.Logger, proxyName string, cfg model.AccessLog) (*Logger, error) {
+	defaults := config.Get().ProxyAccessLog
+
+	cfg = cfg.WithDefaults(model.AccessLog{
+		Format: defaults.Format,
+		Output: defaults.Output,
+		File:   filepath.Join(defaults.Dir, proxyName+".log"),
+	})
+
+	l := &Logger{
+		log:    log,
+		proxy:  proxyName,
+		format: cfg.Format,
+	}
+
+	var err error
+
+	switch cfg.Output {
+	case model.AccessLogOutputFile:
+		l.out, err = newRotatingFile(cfg.File, int64(defaults.MaxSize)*bytesPerMegabyte, defaults.MaxBackups)
+	case model.AccessLogOutputSyslog:
+		l.out, err = newSyslogWriter(defaults.Syslog.Network, defaults.Syslog.Address, defaults.Syslog.Tag)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening %s access log: %w", cfg.Output, err)
+	}
+
+	return l, nil
+}
+
+// NewLog function returns an access logger that writes to the TSDProxy log.
+func NewLog(log zerolog.Logger, proxyName string) *Logger {
+	return &Logger{
+		log:   log,
+		proxy: proxyName,
+	}
+}
+
+// Close method closes the access log file or syslog connection. Requests
+// logged after closing are written to the TSDProxy log.
+func (l *Logger) Close() error {
+	if l == nil {
+		return nil
+	}
+
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.out == nil {
+		return nil
+	}
+
+	err := l.out.Close()
+	l.out = nil
+
+	return err
+}
+
+// write method writes a request to the access log.
+func (l *Logger) write(e *entry) {
+	l.mtx.Lock()
+	defer l.mtx.Unlock()
+
+	if l.out == nil {
+		l.writeLog(e)
+		return
+	}
+
+	line, err := l.formatEntry(e)
+	if err != nil {
+		l.log.Error().Err(err).Msg("error formatting access log")
+		return
+	}
+
+	if _, err := l.out.Write(line); err != nil {
+		l.log.Error().Err(err).Msg("error writing access log")
+	}
+}
+
+// writeLog method writes a request to the TSDProxy log.
+func (l *Logger) writeLog(e *entry) {
+	event := l.log.Info()
+	msg := "request"
+	if e.Status >= http.StatusBadRequest {
+		event = l.log.Error().Str("error", e.Error)
+		msg = "error"
+	}
+
+	event.
+		Int("status", e.Status).
+		Str("method", e.Method).
+		Str("host", e.Host).
+		Str("client", e.Client).
+		Str("user", e.User).
+		Str("url", e.URI).
+		Int64("bytes", e.Bytes).
+		Float64("latency", e.Latency).
+		Str("userAgent", e.UserAgent).
+		Str("target", e.Target).
+		Msg(msg)
+}
+
+// SetTarget function records the target of the request in its access log entry.
+func SetTarget(ctx context.Context, target string) {
+	if e, ok := ctx.Value(contextKeyEntry).(*entry); ok {
+		e.Target = target
+	}
+}
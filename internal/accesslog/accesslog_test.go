@@ -0,0 +1,210 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package accesslog
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// testConfig is the configuration of the tests, with the data directory and
+// the access log settings.
+const testConfig = `
+tailscale:
+  dataDir: %[1]s
+  providers:
+    default: {}
+proxyAccessLog:
+  dir: %[1]s/accesslog
+%[2]s`
+
+// loadTestConfig function loads the configuration of the tests, with the yaml
+// added to the access log settings, and returns the data directory.
+func loadTestConfig(t *testing.T, yaml string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tsdproxy.yaml")
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(testConfig, dir, yaml)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+// nopCloser struct is a buffer used as the output of a logger.
+type nopCloser struct {
+	bytes.Buffer
+}
+
+func (nopCloser) Close() error {
+	return nil
+}
+
+func TestFormatEntry(t *testing.T) {
+	e := &entry{
+		Time:      time.Date(2026, 1, 2, 15, 4, 5, 0, time.UTC),
+		Proxy:     "web",
+		Client:    "100.64.0.1",
+		User:      "Alice Smith",
+		Method:    http.MethodGet,
+		URI:       "/index.html",
+		Proto:     "HTTP/1.1",
+		UserAgent: `curl "8"`,
+		Status:    http.StatusOK,
+		Bytes:     512, //nolint:mnd
+	}
+
+	tests := map[string]string{
+		model.AccessLogFormatCommon:   `100.64.0.1 - Alice_Smith [02/Jan/2026:15:04:05 +0000] "GET /index.html HTTP/1.1" 200 512` + "\n",
+		model.AccessLogFormatCombined: `100.64.0.1 - Alice_Smith [02/Jan/2026:15:04:05 +0000] "GET /index.html HTTP/1.1" 200 512 "-" "curl \"8\""` + "\n",
+	}
+
+	for format, want := range tests {
+		l := &Logger{format: format}
+		line, err := l.formatEntry(e)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != want {
+			t.Errorf("%s: got %q, want %q", format, line, want)
+		}
+	}
+
+	l := &Logger{format: model.AccessLogFormatJSON}
+	line, err := l.formatEntry(e)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded entry
+	if err := json.Unmarshal(line, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != *e {
+		t.Errorf("json: got %+v, want %+v", decoded, *e)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	out := &nopCloser{}
+	l := &Logger{proxy: "web", format: model.AccessLogFormatJSON, out: out}
+
+	handler := l.Middleware("443/https", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetTarget(r.Context(), "http://web:8080")
+		w.WriteHeader(http.StatusTeapot)
+		_, _ = io.WriteString(w, "teapot")
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "https://web.example.ts.net/brew?cups=2", nil)
+	r.RemoteAddr = "100.64.0.1:40000"
+	r = r.WithContext(model.WhoisNewContext(r.Context(), model.Whois{Username: "alice@example.com"}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	var e entry
+	if err := json.Unmarshal(out.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Proxy != "web" || e.Port != "443/https" || e.Client != "100.64.0.1" || e.User != "alice@example.com" ||
+		e.Method != http.MethodPost || e.URI != "https://web.example.ts.net/brew?cups=2" ||
+		e.Target != "http://web:8080" || e.Status != http.StatusTeapot || e.Bytes != 6 {
+		t.Errorf("entry: got %+v", e)
+	}
+}
+
+func TestFileOutput(t *testing.T) {
+	dir := loadTestConfig(t, "  output: file\n  format: common\n")
+
+	l, err := New(zerolog.Nop(), "web", model.AccessLog{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := l.Middleware("443/https", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if err := l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the file of the proxy is in the directory of the access logs
+	data, err := os.ReadFile(filepath.Join(dir, "accesslog", "web.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(data), "192.0.2.1 - - [") || !strings.HasSuffix(string(data), `"GET / HTTP/1.1" 200 -`+"\n") {
+		t.Errorf("access log: got %q", data)
+	}
+}
+
+func TestIsEnabled(t *testing.T) {
+	loadTestConfig(t, "")
+	if !IsEnabled(model.AccessLog{Enabled: true}) || IsEnabled(model.AccessLog{}) {
+		t.Error("access log of the proxy not used")
+	}
+
+	loadTestConfig(t, "  disabled: true\n")
+	if IsEnabled(model.AccessLog{Enabled: true}) {
+		t.Error("access log enabled while disabled globally")
+	}
+}
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "logs", "web.log")
+
+	f, err := newRotatingFile(path, 20, 2) //nolint:mnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+
+	for i := range 4 {
+		if _, err := fmt.Fprintf(f, "request number %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// each line fills a file, the oldest is removed
+	for name, want := range map[string]string{
+		path:        "request number 3\n",
+		path + ".1": "request number 2\n",
+		path + ".2": "request number 1\n",
+	} {
+		data, err := os.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("%s: got %q, want %q", filepath.Base(name), data, want)
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("backup after the maximum: %v", err)
+	}
+
+	// the size of the existing file is kept when reopened
+	f.Close()
+	f, err = newRotatingFile(path, 20, 2) //nolint:mnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.size != int64(len("request number 3\n")) {
+		t.Errorf("size after reopening: got %d", f.size)
+	}
+}
@@ -0,0 +1,130 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package accesslog
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+// file permissions of the access logs
+const (
+	dirMode  = 0o755
+	fileMode = 0o640
+)
+
+// rotatingFile struct is an access log file rotated by size. When the file is
+// bigger than maxSize, it's renamed to <file>.1, older files are renamed to the
+// next number and the files after maxBackups are removed.
+type rotatingFile struct {
+	file       *os.File
+	path       string
+	maxSize    int64
+	size       int64
+	maxBackups int
+}
+
+// newRotatingFile function opens the access log file, creating its directory.
+// The file isn't rotated if maxSize is 0.
+func newRotatingFile(path string, maxSize int64, maxBackups int) (*rotatingFile, error) {
+	if err := os.MkdirAll(filepath.Dir(path), dirMode); err != nil {
+		return nil, err
+	}
+
+	f := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// open method opens the file to append, keeping its current size.
+func (f *rotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, fileMode)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	f.file = file
+	f.size = info.Size()
+
+	return nil
+}
+
+// Write method implements io.Writer, rotating the file before writing if it
+// would be bigger than the maximum size. If the rotation fails, the line is
+// still written to the current file.
+func (f *rotatingFile) Write(p []byte) (int, error) {
+	var rotateErr error
+	if f.maxSize > 0 && f.size > 0 && f.size+int64(len(p)) > f.maxSize {
+		rotateErr = f.rotate()
+	}
+	if f.file == nil {
+		return 0, rotateErr
+	}
+
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+
+	return n, errors.Join(rotateErr, err)
+}
+
+// rotate method closes the file, renames it and the backups and opens a new file.
+func (f *rotatingFile) rotate() error {
+	err := f.file.Close()
+	f.file = nil
+
+	err = errors.Join(err, f.shiftBackups())
+
+	return errors.Join(err, f.open())
+}
+
+// shiftBackups method renames the file and the backups to the next number,
+// removing the oldest backup. The file is removed if there are no backups.
+func (f *rotatingFile) shiftBackups() error {
+	if err := os.Remove(f.backup(f.maxBackups)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for i := f.maxBackups - 1; i >= 0; i-- {
+		if err := os.Rename(f.backup(i), f.backup(i+1)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// backup method returns the name of the backup file n, or the file itself if
+// n is 0.
+func (f *rotatingFile) backup(n int) string {
+	if n == 0 {
+		return f.path
+	}
+
+	return f.path + "." + strconv.Itoa(n)
+}
+
+// Close method implements io.Closer.
+func (f *rotatingFile) Close() error {
+	if f.file == nil {
+		return nil
+	}
+
+	return f.file.Close()
+}
@@ -0,0 +1,75 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package accesslog
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// clfTimeFormat is the time format of the Common Log Format.
+const clfTimeFormat = "02/Jan/2006:15:04:05 -0700"
+
+// formatEntry method returns the access log line of a request, with the
+// format of the logger.
+func (l *Logger) formatEntry(e *entry) ([]byte, error) {
+	switch l.format {
+	case model.AccessLogFormatJSON:
+		line, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+
+		return append(line, '\n'), nil
+	case model.AccessLogFormatCommon:
+		return []byte(formatCommon(e) + "\n"), nil
+	default:
+		return []byte(formatCommon(e) + " " + quote(e.Referer) + " " + quote(e.UserAgent) + "\n"), nil
+	}
+}
+
+// formatCommon function returns the request in the Common Log Format, with the
+// Tailscale username as the authenticated user.
+func formatCommon(e *entry) string {
+	var b strings.Builder
+
+	b.WriteString(orDash(e.Client))
+	b.WriteString(" - ")
+	b.WriteString(orDash(strings.ReplaceAll(e.User, " ", "_")))
+	b.WriteString(" [")
+	b.WriteString(e.Time.Format(clfTimeFormat))
+	b.WriteString("] ")
+	b.WriteString(quote(e.Method + " " + e.URI + " " + e.Proto))
+	b.WriteString(" ")
+	b.WriteString(strconv.Itoa(e.Status))
+	b.WriteString(" ")
+	if e.Bytes > 0 {
+		b.WriteString(strconv.FormatInt(e.Bytes, 10))
+	} else {
+		b.WriteString("-")
+	}
+
+	return b.String()
+}
+
+// quote function returns the value quoted and escaped, or "-" if empty.
+func quote(s string) string {
+	if s == "" {
+		return `"-"`
+	}
+
+	return strconv.Quote(s)
+}
+
+// orDash function returns the value, or - if empty.
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+
+	return s
+}
@@ -0,0 +1,65 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package accesslog
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// Middleware method writes the requests of a port to the access log. It must
+// run after ProviderUserMiddleware, to log the Tailscale user and the address
+// of Funnel clients.
+func (l *Logger) Middleware(port string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		e := &entry{
+			Proxy:     l.proxy,
+			Port:      port,
+			Client:    clientHost(r.RemoteAddr),
+			Method:    r.Method,
+			Host:      r.Host,
+			URI:       r.RequestURI,
+			Proto:     r.Proto,
+			Referer:   r.Referer(),
+			UserAgent: r.UserAgent(),
+		}
+		if e.URI == "" {
+			e.URI = r.URL.RequestURI()
+		}
+		if who, ok := model.WhoisFromContext(r.Context()); ok {
+			e.User = who.Username
+		}
+
+		rw := core.NewResponseRecorder(w)
+		ctx := context.WithValue(r.Context(), contextKeyEntry, e)
+
+		start := time.Now()
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		e.Time = start
+		e.Latency = time.Since(start).Seconds()
+		e.Status = rw.Status
+		e.Bytes = rw.Bytes
+		if rw.Err != nil {
+			e.Error = rw.Err.Error()
+		}
+
+		l.write(e)
+	})
+}
+
+// clientHost function returns the host of a client address.
+func clientHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+
+	return host
+}
@@ -0,0 +1,14 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+//go:build windows || plan9
+
+package accesslog
+
+import "io"
+
+// newSyslogWriter function returns ErrSyslogNotSupported, syslog isn't
+// available on this system.
+func newSyslogWriter(_, _, _ string) (io.WriteCloser, error) {
+	return nil, ErrSyslogNotSupported
+}
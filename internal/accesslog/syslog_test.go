@@ -0,0 +1,54 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+//go:build !windows && !plan9
+
+package accesslog
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func TestSyslogOutput(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	loadTestConfig(t, "  output: syslog\n  format: common\n  syslog:\n    network: udp\n    address: "+
+		server.LocalAddr().String()+"\n    tag: tsdproxy\n")
+
+	l, err := New(zerolog.Nop(), "web", model.AccessLog{Enabled: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	handler := l.Middleware("443/https", http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := server.SetReadDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	buf := make([]byte, 1024) //nolint:mnd
+	n, _, err := server.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the message has the daemon facility, info severity and the tag
+	msg := string(buf[:n])
+	if !strings.HasPrefix(msg, "<30>") || !strings.Contains(msg, " tsdproxy[") || !strings.Contains(msg, `"GET / HTTP/1.1" 200 -`) {
+		t.Errorf("syslog message: got %q", msg)
+	}
+}
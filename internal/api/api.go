@@ -0,0 +1,648 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/history"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/proxymanager"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var (
+	// ErrProxyNotStopped is returned when starting a proxy that wasn't stopped from the API.
+	ErrProxyNotStopped = errors.New("proxy not found or not stopped")
+	// ErrInvalidLimit is returned when the limit query parameter isn't a positive number.
+	ErrInvalidLimit = errors.New("invalid limit")
+)
+
+const (
+	// Prefix is the path prefix of all management API routes.
+	Prefix = "/api/v1"
+
+	// DefaultHistoryLimit is the number of events returned by the history endpoint
+	// without the limit query parameter.
+	DefaultHistoryLimit = 50
+)
+
+// Target provider types returned by the providers endpoint.
+const (
+	TargetProviderTypeDocker     = "docker"
+	TargetProviderTypeKubernetes = "kubernetes"
+	TargetProviderTypePodman     = "podman"
+	TargetProviderTypeList       = "list"
+)
+
+type (
+	// API struct exposes the proxies and providers managed by the ProxyManager
+	// as a versioned JSON API.
+	API struct {
+		Log   zerolog.Logger
+		HTTP  *core.HTTPServer
+		pm    *proxymanager.ProxyManager
+		token string
+	}
+
+	// Proxy struct is the JSON representation of a proxy.
+	Proxy struct {
+		Name           string    `json:"name"`
+		Status         string    `json:"status"`
+		URL            string    `json:"url,omitempty"`
+		AuthURL        string    `json:"authUrl,omitempty"`
+		Error          string    `json:"error,omitempty"`
+		TargetProvider string    `json:"targetProvider"`
+		TargetID       string    `json:"targetId"`
+		ProxyProvider  string    `json:"proxyProvider"`
+		Ports          []Port    `json:"ports"`
+		Dashboard      Dashboard `json:"dashboard"`
+		Tailscale      Tailscale `json:"tailscale"`
+		AccessLog      AccessLog `json:"accessLog"`
+		Maintenance    bool      `json:"maintenance"`
+	}
+
+	// Port struct is the JSON representation of a proxy port.
+	Port struct {
+		Name              string   `json:"name"`
+		Protocol          string   `json:"protocol"`
+		LoadBalance       string   `json:"loadBalance,omitempty"`
+		Targets           []Target `json:"targets"`
+		Routes            []Route  `json:"routes,omitempty"`
+		Port              int      `json:"port"`
+		ActiveConnections int64    `json:"activeConnections"`
+		IsRedirect        bool     `json:"isRedirect"`
+		TLSValidate       bool     `json:"tlsValidate"`
+		Funnel            bool     `json:"funnel"`
+		Cache             bool     `json:"cache"`
+	}
+
+	// Route struct is the JSON representation of a port path route.
+	Route struct {
+		Path        string   `json:"path"`
+		Rewrite     string   `json:"rewrite,omitempty"`
+		Targets     []string `json:"targets"`
+		StripPrefix bool     `json:"stripPrefix"`
+	}
+
+	// Target struct is the JSON representation of a port target.
+	Target struct {
+		URL    string `json:"url"`
+		Health string `json:"health"`
+	}
+
+	// Dashboard struct is the JSON representation of the proxy dashboard options.
+	Dashboard struct {
+		Label   string `json:"label"`
+		Icon    string `json:"icon"`
+		Visible bool   `json:"visible"`
+	}
+
+	// Tailscale struct is the JSON representation of the proxy Tailscale options.
+	// The auth key is never returned.
+	Tailscale struct {
+		Tags         string `json:"tags,omitempty"`
+		Ephemeral    bool   `json:"ephemeral"`
+		RunWebClient bool   `json:"runWebClient"`
+		Verbose      bool   `json:"verbose"`
+	}
+
+	// AccessLog struct is the JSON representation of the proxy access log
+	// options. Empty values use the global defaults.
+	AccessLog struct {
+		Format  string `json:"format,omitempty"`
+		Output  string `json:"output,omitempty"`
+		File    string `json:"file,omitempty"`
+		Enabled bool   `json:"enabled"`
+	}
+
+	// Providers struct is the JSON representation of the configured providers.
+	Providers struct {
+		TargetProviders []TargetProvider `json:"targetProviders"`
+		ProxyProviders  []ProxyProvider  `json:"proxyProviders"`
+	}
+
+	// TargetProvider struct is the JSON representation of a target provider.
+	TargetProvider struct {
+		Name                 string `json:"name"`
+		Type                 string `json:"type"`
+		DefaultProxyProvider string `json:"defaultProxyProvider,omitempty"`
+	}
+
+	// ProxyProvider struct is the JSON representation of a proxy provider.
+	ProxyProvider struct {
+		Name       string `json:"name"`
+		ControlURL string `json:"controlUrl"`
+		Default    bool   `json:"default"`
+	}
+
+	// Event struct is the JSON representation of a proxy status event.
+	Event struct {
+		Time    time.Time `json:"time"`
+		Proxy   string    `json:"proxy"`
+		Status  string    `json:"status"`
+		Port    string    `json:"port,omitempty"`
+		Target  string    `json:"target,omitempty"`
+		Health  string    `json:"health,omitempty"`
+		AuthURL string    `json:"authUrl,omitempty"`
+		Error   string    `json:"error,omitempty"`
+		Attempt int       `json:"attempt,omitempty"`
+	}
+
+	// ShareLink struct is the JSON representation of a one-time Funnel share link.
+	ShareLink struct {
+		Expires time.Time `json:"expires"`
+		URL     string    `json:"url"`
+		Port    string    `json:"port"`
+	}
+
+	// CachePurge struct is returned after the cache of a proxy is purged.
+	CachePurge struct {
+		Purged int `json:"purged"`
+	}
+
+	// ActionResponse struct is returned after a proxy action is accepted.
+	ActionResponse struct {
+		Name   string `json:"name"`
+		Action string `json:"action"`
+	}
+)
+
+// NewAPI function returns a new management API. Changes to the API token
+// require a restart, like the other HTTP settings.
+func NewAPI(http *core.HTTPServer, log zerolog.Logger, pm *proxymanager.ProxyManager) *API {
+	return &API{
+		Log:   log.With().Str("module", "api").Logger(),
+		HTTP:  http,
+		pm:    pm,
+		token: config.Get().HTTP.APIToken,
+	}
+}
+
+// AddRoutes method add management API routes to the http server
+func (api *API) AddRoutes() {
+	api.HTTP.Get(Prefix+"/proxies", api.listProxies())
+	api.HTTP.Get(Prefix+"/proxies/{name}", api.getProxy())
+	api.HTTP.Get(Prefix+"/proxies/{name}/history", api.getHistory())
+	api.HTTP.Post(Prefix+"/proxies/{name}/start", api.Protect(api.proxyAction("start", api.pm.StartProxy)))
+	api.HTTP.Post(Prefix+"/proxies/{name}/stop", api.Protect(api.proxyAction("stop", api.pm.StopProxy)))
+	api.HTTP.Post(Prefix+"/proxies/{name}/restart", api.Protect(api.proxyAction("restart", api.pm.RestartProxy)))
+	api.HTTP.Post(Prefix+"/proxies/{name}/maintenance", api.Protect(api.setMaintenance(true)))
+	api.HTTP.Delete(Prefix+"/proxies/{name}/maintenance", api.Protect(api.setMaintenance(false)))
+	api.HTTP.Post(Prefix+"/proxies/{name}/share", api.Protect(api.createShareLink()))
+	api.HTTP.Delete(Prefix+"/proxies/{name}/cache", api.Protect(api.purgeCache()))
+	api.HTTP.Get(Prefix+"/providers", api.listProviders())
+	api.HTTP.Get(Prefix+"/events", api.streamEvents())
+}
+
+// listProxies method returns the handler that lists all proxies, including the
+// ones stopped from the API and the targets not started because of a hostname conflict.
+func (api *API) listProxies() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		proxies := make([]Proxy, 0)
+
+		for _, p := range api.pm.GetProxies() {
+			proxies = append(proxies, newProxy(p))
+		}
+
+		for name, cfg := range api.pm.GetStoppedProxies() {
+			proxies = append(proxies, newStoppedProxy(name, cfg))
+		}
+
+		for _, c := range api.pm.GetConflicts() {
+			proxies = append(proxies, newConflictProxy(c))
+		}
+
+		slices.SortStableFunc(proxies, func(a, b Proxy) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		api.HTTP.JSONResponse(w, r, proxies)
+	}
+}
+
+// getProxy method returns the handler that returns a single proxy.
+func (api *API) getProxy() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		if p, ok := api.pm.GetProxy(name); ok {
+			api.HTTP.JSONResponse(w, r, newProxy(p))
+			return
+		}
+
+		if cfg, ok := api.pm.GetStoppedProxies()[name]; ok {
+			api.HTTP.JSONResponse(w, r, newStoppedProxy(name, cfg))
+			return
+		}
+
+		api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()),
+			proxymanager.ErrProxyNotFound.Error(), http.StatusNotFound)
+	}
+}
+
+// getHistory method returns the handler that returns the status events of a
+// proxy, newest first. Events are kept after the proxy is removed.
+func (api *API) getHistory() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		limit := DefaultHistoryLimit
+		if v := r.URL.Query().Get("limit"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), ErrInvalidLimit.Error(), http.StatusBadRequest)
+				return
+			}
+			limit = n
+		}
+
+		events, err := api.pm.GetHistory(name, limit)
+		switch {
+		case errors.Is(err, history.ErrProxyNotFound):
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusNotFound)
+		case err != nil:
+			api.Log.Error().Err(err).Str("proxy", name).Msg("error reading proxy history")
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusInternalServerError)
+		default:
+			api.HTTP.JSONResponse(w, r, events)
+		}
+	}
+}
+
+// streamEvents method returns the handler that streams the proxy status events
+// as server-sent events, one JSON event per message, until the client disconnects.
+func (api *API) streamEvents() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+
+		events := api.pm.SubscribeStatusEvents()
+		defer api.pm.UnsubscribeStatusEvents(events)
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+
+		if err := rc.Flush(); err != nil {
+			api.Log.Error().Err(err).Msg("events stream not supported")
+			return
+		}
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					return
+				}
+
+				if err := api.writeEvent(w, rc, event); err != nil {
+					api.Log.Debug().Err(err).Msg("events client disconnected")
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeEvent method writes a proxy event message to the events stream.
+func (api *API) writeEvent(w http.ResponseWriter, rc *http.ResponseController, event model.ProxyEvent) error {
+	e := Event{
+		Time:    time.Now(),
+		Proxy:   event.ID,
+		Status:  event.Status.String(),
+		Port:    event.Port,
+		Target:  event.Target,
+		AuthURL: event.AuthURL,
+		Error:   event.Error,
+		Attempt: event.Attempt,
+	}
+	if event.Target != "" {
+		e.Health = event.Health.String()
+	}
+	if event.Status == model.ProxyStatusAuthenticating && e.AuthURL == "" {
+		if p, ok := api.pm.GetProxy(event.ID); ok {
+			e.AuthURL = p.GetAuthURL()
+		}
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if _, err := fmt.Fprintf(w, "event: status\ndata: %s\n\n", data); err != nil {
+		return err
+	}
+
+	return rc.Flush()
+}
+
+// proxyAction method returns the handler that applies an action to a proxy.
+// Actions run asynchronously, the proxy status can be followed on the proxy endpoint.
+func (api *API) proxyAction(action string, fn func(name string) error) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		api.Log.Info().Str("proxy", name).Str("action", action).Msg("proxy action requested")
+
+		// check before running the action in background to return the error to the client
+		if err := api.validateAction(action, name); err != nil {
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusNotFound)
+			return
+		}
+
+		go func() {
+			if err := fn(name); err != nil {
+				api.Log.Error().Err(err).Str("proxy", name).Str("action", action).Msg("error running proxy action")
+			}
+		}()
+
+		api.HTTP.JSONResponseCode(w, r, ActionResponse{Name: name, Action: action}, http.StatusAccepted)
+	}
+}
+
+// setMaintenance method returns the handler that enables or disables the
+// maintenance mode of a proxy, returning the proxy.
+func (api *API) setMaintenance(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		if err := api.pm.SetMaintenance(name, enabled); err != nil {
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusNotFound)
+			return
+		}
+
+		p, ok := api.pm.GetProxy(name)
+		if !ok {
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()),
+				proxymanager.ErrProxyNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		api.HTTP.JSONResponse(w, r, newProxy(p))
+	}
+}
+
+// createShareLink method returns the handler that creates a one-time share
+// link of a Funnel port, selected with the port query parameter.
+func (api *API) createShareLink() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		link, err := api.pm.CreateShareLink(name, r.URL.Query().Get("port"))
+		switch {
+		case errors.Is(err, proxymanager.ErrProxyNotFound), errors.Is(err, proxymanager.ErrPortNotFound):
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusNotFound)
+		case err != nil:
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusBadRequest)
+		default:
+			api.Log.Info().Str("proxy", name).Str("port", link.Port).Msg("funnel share link created")
+			api.HTTP.JSONResponseCode(w, r, ShareLink{URL: link.URL, Port: link.Port, Expires: link.Expires}, http.StatusCreated)
+		}
+	}
+}
+
+// purgeCache method returns the handler that removes the cached responses of
+// a proxy, of the port and path prefix query parameters if set.
+func (api *API) purgeCache() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+		query := r.URL.Query()
+
+		purged, err := api.pm.PurgeCache(name, query.Get("port"), query.Get("path"))
+		switch {
+		case errors.Is(err, proxymanager.ErrProxyNotFound), errors.Is(err, proxymanager.ErrPortNotFound):
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusNotFound)
+		case err != nil:
+			api.HTTP.ErrorResponse(w, r, trace.SpanFromContext(r.Context()), err.Error(), http.StatusBadRequest)
+		default:
+			api.Log.Info().Str("proxy", name).Int("purged", purged).Msg("cache purged")
+			api.HTTP.JSONResponse(w, r, CachePurge{Purged: purged})
+		}
+	}
+}
+
+// validateAction method returns an error if the action can't be applied to the proxy.
+func (api *API) validateAction(action, name string) error {
+	if action == "start" {
+		if _, ok := api.pm.GetStoppedProxies()[name]; !ok {
+			return ErrProxyNotStopped
+		}
+		return nil
+	}
+
+	if _, ok := api.pm.GetProxy(name); !ok {
+		return proxymanager.ErrProxyNotFound
+	}
+
+	return nil
+}
+
+// listProviders method returns the handler that lists the configured providers.
+func (api *API) listProviders() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		providers := Providers{
+			TargetProviders: make([]TargetProvider, 0),
+			ProxyProviders:  make([]ProxyProvider, 0),
+		}
+		cfg := config.Get()
+
+		for name, p := range cfg.Docker {
+			providers.TargetProviders = append(providers.TargetProviders, TargetProvider{
+				Name:                 name,
+				Type:                 TargetProviderTypeDocker,
+				DefaultProxyProvider: p.DefaultProxyProvider,
+			})
+		}
+
+		for name, p := range cfg.Kubernetes {
+			providers.TargetProviders = append(providers.TargetProviders, TargetProvider{
+				Name:                 name,
+				Type:                 TargetProviderTypeKubernetes,
+				DefaultProxyProvider: p.DefaultProxyProvider,
+			})
+		}
+
+		for name, p := range cfg.Podman {
+			providers.TargetProviders = append(providers.TargetProviders, TargetProvider{
+				Name:                 name,
+				Type:                 TargetProviderTypePodman,
+				DefaultProxyProvider: p.DefaultProxyProvider,
+			})
+		}
+
+		for name, p := range cfg.Lists {
+			providers.TargetProviders = append(providers.TargetProviders, TargetProvider{
+				Name:                 name,
+				Type:                 TargetProviderTypeList,
+				DefaultProxyProvider: p.DefaultProxyProvider,
+			})
+		}
+
+		for name, p := range cfg.Tailscale.Providers {
+			providers.ProxyProviders = append(providers.ProxyProviders, ProxyProvider{
+				Name:       name,
+				ControlURL: p.ControlURL,
+				Default:    name == cfg.DefaultProxyProvider,
+			})
+		}
+
+		slices.SortFunc(providers.TargetProviders, func(a, b TargetProvider) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+		slices.SortFunc(providers.ProxyProviders, func(a, b ProxyProvider) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+
+		api.HTTP.JSONResponse(w, r, providers)
+	}
+}
+
+// newProxy function returns the JSON representation of a running proxy.
+func newProxy(p *proxymanager.Proxy) Proxy {
+	proxy := newStoppedProxy(p.Config.Hostname, p.Config)
+
+	status := p.GetStatus()
+
+	proxy.Status = status.String()
+	proxy.URL = p.GetURL()
+	proxy.AuthURL = p.GetAuthURL()
+	proxy.Error = p.GetError()
+	proxy.Maintenance = p.IsMaintenance()
+
+	health := p.GetTargetsHealth()
+	connections := p.GetActiveConnections()
+
+	for i, port := range proxy.Ports {
+		if targets, ok := health[port.Name]; ok {
+			proxy.Ports[i].Targets = newTargets(targets)
+		}
+		proxy.Ports[i].ActiveConnections = connections[port.Name]
+	}
+
+	return proxy
+}
+
+// newStoppedProxy function returns the JSON representation of a proxy from its configuration.
+func newStoppedProxy(name string, cfg *model.Config) Proxy {
+	stopped := model.ProxyStatusStopped
+	unknown := model.HealthStatusUnknown
+
+	proxy := Proxy{
+		Name:           name,
+		Status:         stopped.String(),
+		TargetProvider: cfg.TargetProvider,
+		TargetID:       cfg.TargetID,
+		ProxyProvider:  cfg.ProxyProvider,
+		Ports:          make([]Port, 0, len(cfg.Ports)),
+		AccessLog: AccessLog{
+			Format:  cfg.AccessLog.Format,
+			Output:  cfg.AccessLog.Output,
+			File:    cfg.AccessLog.File,
+			Enabled: cfg.AccessLog.Enabled,
+		},
+		Dashboard: Dashboard{
+			Label:   cfg.Dashboard.Label,
+			Icon:    cfg.Dashboard.Icon,
+			Visible: cfg.Dashboard.Visible,
+		},
+		Tailscale: Tailscale{
+			Tags:         cfg.Tailscale.Tags,
+			Ephemeral:    cfg.Tailscale.Ephemeral,
+			RunWebClient: cfg.Tailscale.RunWebClient,
+			Verbose:      cfg.Tailscale.Verbose,
+		},
+	}
+
+	for name, port := range cfg.Ports {
+		targets := make([]Target, 0, len(port.GetTargets()))
+		for _, target := range port.GetTargets() {
+			targets = append(targets, Target{
+				URL:    target.String(),
+				Health: unknown.String(),
+			})
+		}
+
+		proxy.Ports = append(proxy.Ports, Port{
+			Name:        name,
+			Protocol:    port.ProxyProtocol,
+			Port:        port.ProxyPort,
+			LoadBalance: port.LoadBalance,
+			IsRedirect:  port.IsRedirect,
+			TLSValidate: port.TLSValidate,
+			Funnel:      port.Tailscale.Funnel,
+			Cache:       port.Cache.Enabled,
+			Targets:     targets,
+			Routes:      newRoutes(port.Routes),
+		})
+	}
+
+	slices.SortFunc(proxy.Ports, func(a, b Port) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return proxy
+}
+
+// newConflictProxy function returns the JSON representation of a target not
+// started because its hostname is used by another target.
+func newConflictProxy(c proxymanager.Conflict) Proxy {
+	status := model.ProxyStatusError
+
+	proxy := newStoppedProxy(c.Config.Hostname, c.Config)
+	proxy.Status = status.String()
+	proxy.Error = c.Error()
+
+	return proxy
+}
+
+// newRoutes function returns the JSON representation of the port routes.
+func newRoutes(routes []model.Route) []Route {
+	if len(routes) == 0 {
+		return nil
+	}
+
+	list := make([]Route, 0, len(routes))
+	for _, route := range routes {
+		targets := make([]string, 0, len(route.GetTargets()))
+		for _, target := range route.GetTargets() {
+			targets = append(targets, target.String())
+		}
+
+		list = append(list, Route{
+			Path:        route.Path,
+			Rewrite:     route.Rewrite,
+			StripPrefix: route.StripPrefix,
+			Targets:     targets,
+		})
+	}
+
+	return list
+}
+
+// newTargets function returns the JSON representation of the targets health.
+func newTargets(health []model.TargetHealth) []Target {
+	targets := make([]Target, 0, len(health))
+	for _, target := range health {
+		targets = append(targets, Target{
+			URL:    target.URL,
+			Health: target.Status.String(),
+		})
+	}
+
+	return targets
+}
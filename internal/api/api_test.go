@@ -0,0 +1,182 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/history"
+	"github.com/xybydy/tsdproxy/internal/proxymanager"
+)
+
+// testConfig is the server configuration of the tests, with the data
+// directory and the list file.
+const testConfig = `
+defaultProxyProvider: main
+tailscale:
+  dataDir: %[1]s
+  providers:
+    main: {}
+    backup:
+      controlUrl: https://headscale.example.com
+lists:
+  services:
+    filename: %[2]s
+    defaultProxyProvider: backup
+`
+
+// newTestAPI function returns the API of a proxy manager without proxies,
+// with the history store.
+func newTestAPI(t *testing.T) (*API, *history.Store) {
+	t.Helper()
+
+	dir := t.TempDir()
+	list := filepath.Join(dir, "services.yaml")
+	file := filepath.Join(dir, "tsdproxy.yaml")
+	if err := os.WriteFile(list, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(testConfig, dir, list)), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+
+	store, err := history.Open(zerolog.Nop(), filepath.Join(dir, history.Filename), history.DefaultMaxEvents)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+
+	api := NewAPI(core.NewHTTPServer(zerolog.Nop()), zerolog.Nop(), proxymanager.NewProxyManager(zerolog.Nop(), store))
+	api.AddRoutes()
+
+	return api, store
+}
+
+// serve method sends the request to the API from a loopback address and
+// returns the response.
+func (api *API) serve(method, path string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://tsdproxy:8080"+Prefix+path, nil)
+	r.RemoteAddr = loopback
+	r.Header.Set(HeaderRequest, "1")
+	w := httptest.NewRecorder()
+	api.HTTP.Mux.ServeHTTP(w, r)
+
+	return w
+}
+
+// decode function decodes the JSON body of the response.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+
+	if err := json.NewDecoder(w.Body).Decode(v); err != nil {
+		t.Fatalf("invalid JSON response: %v", err)
+	}
+}
+
+func TestListProxiesEmpty(t *testing.T) {
+	api, _ := newTestAPI(t)
+
+	w := api.serve(http.MethodGet, "/proxies")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d", w.Code)
+	}
+
+	var proxies []Proxy
+	decode(t, w, &proxies)
+	if proxies == nil || len(proxies) != 0 {
+		t.Errorf("proxies: got %v, want an empty list", proxies)
+	}
+}
+
+func TestProxyNotFound(t *testing.T) {
+	api, _ := newTestAPI(t)
+
+	routes := []struct{ method, path string }{
+		{http.MethodGet, "/proxies/nginx"},
+		{http.MethodPost, "/proxies/nginx/start"},
+		{http.MethodPost, "/proxies/nginx/stop"},
+		{http.MethodPost, "/proxies/nginx/restart"},
+		{http.MethodPost, "/proxies/nginx/maintenance"},
+		{http.MethodPost, "/proxies/nginx/share"},
+		{http.MethodDelete, "/proxies/nginx/cache"},
+	}
+
+	for _, route := range routes {
+		if w := api.serve(route.method, route.path); w.Code != http.StatusNotFound {
+			t.Errorf("%s %s: got %d, want %d", route.method, route.path, w.Code, http.StatusNotFound)
+		}
+	}
+}
+
+func TestListProviders(t *testing.T) {
+	api, _ := newTestAPI(t)
+
+	w := api.serve(http.MethodGet, "/providers")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d", w.Code)
+	}
+
+	var providers Providers
+	decode(t, w, &providers)
+
+	want := []TargetProvider{{Name: "services", Type: TargetProviderTypeList, DefaultProxyProvider: "backup"}}
+	if len(providers.TargetProviders) != 1 || providers.TargetProviders[0] != want[0] {
+		t.Errorf("target providers: got %v, want %v", providers.TargetProviders, want)
+	}
+
+	if len(providers.ProxyProviders) != 2 { //nolint:mnd
+		t.Fatalf("proxy providers: got %v", providers.ProxyProviders)
+	}
+	backup, main := providers.ProxyProviders[0], providers.ProxyProviders[1]
+	if backup.Name != "backup" || backup.Default || backup.ControlURL != "https://headscale.example.com" {
+		t.Errorf("backup provider: got %v", backup)
+	}
+	if main.Name != "main" || !main.Default {
+		t.Errorf("main provider: got %v", main)
+	}
+}
+
+func TestGetHistory(t *testing.T) {
+	api, store := newTestAPI(t)
+
+	start := time.Now()
+	for i, status := range []string{"Starting", "Running", "Stopped"} {
+		if err := store.Add("nginx", history.Event{Time: start.Add(time.Duration(i) * time.Second), Status: status}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	w := api.serve(http.MethodGet, "/proxies/nginx/history?limit=2")
+	if w.Code != http.StatusOK {
+		t.Fatalf("status: got %d", w.Code)
+	}
+	var events []history.Event
+	decode(t, w, &events)
+	if len(events) != 2 || events[0].Status != "Stopped" || events[1].Status != "Running" {
+		t.Errorf("events: got %v, want the last 2 events, newest first", events)
+	}
+
+	if w := api.serve(http.MethodGet, "/proxies/other/history"); w.Code != http.StatusNotFound {
+		t.Errorf("unknown proxy: got %d, want %d", w.Code, http.StatusNotFound)
+	}
+	for _, limit := range []string{"0", "-1", "all"} {
+		if w := api.serve(http.MethodGet, "/proxies/nginx/history?limit="+limit); w.Code != http.StatusBadRequest {
+			t.Errorf("limit %s: got %d, want %d", limit, w.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,97 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"crypto/subtle"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderRequest is the header required in the requests of the mutating routes
+// when there is no API token. Browsers can't send it in cross-site requests
+// without a CORS preflight, which the API doesn't allow.
+const HeaderRequest = "X-Tsdproxy-Request"
+
+// bearerPrefix is the prefix of the API token in the Authorization header.
+const bearerPrefix = "Bearer "
+
+// Protect method returns a handler that only runs next for the requests
+// allowed to change the proxies. Cross-site browser requests are always
+// rejected. If http.apiToken is set, requests must send it as a bearer token,
+// otherwise they must come from a loopback address with the HeaderRequest
+// header.
+func (api *API) Protect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		span := trace.SpanFromContext(r.Context())
+
+		if crossSite(r) {
+			api.Log.Warn().Str("origin", r.Header.Get("Origin")).Str("path", r.URL.Path).Msg("cross-site API request rejected")
+			api.HTTP.ErrorResponse(w, r, span, "cross-site request", http.StatusForbidden)
+			return
+		}
+
+		if api.token != "" {
+			if !validToken(r, api.token) {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				api.HTTP.ErrorResponse(w, r, span, "invalid API token", http.StatusUnauthorized)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !isLoopback(r.RemoteAddr) {
+			api.HTTP.ErrorResponse(w, r, span, "API token required", http.StatusForbidden)
+			return
+		}
+		if r.Header.Get(HeaderRequest) == "" {
+			api.HTTP.ErrorResponse(w, r, span, HeaderRequest+" header required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// crossSite function returns true if a browser sent the request from another
+// site, from the Sec-Fetch-Site header or the host of the Origin header.
+func crossSite(r *http.Request) bool {
+	switch r.Header.Get("Sec-Fetch-Site") {
+	case "", "same-origin", "none":
+	default:
+		return true
+	}
+
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return false
+	}
+	u, err := url.Parse(origin)
+
+	return err != nil || !strings.EqualFold(u.Host, r.Host)
+}
+
+// validToken function returns true if the request has the bearer token.
+func validToken(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), bearerPrefix)
+
+	return ok && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// isLoopback function returns true if the remote address is a loopback address.
+func isLoopback(remoteAddr string) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+
+	return ip != nil && ip.IsLoopback()
+}
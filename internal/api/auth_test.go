@@ -0,0 +1,141 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/core"
+)
+
+const (
+	testToken  = "0123456789abcdef"
+	loopback   = "127.0.0.1:41000"
+	remoteAddr = "192.0.2.10:41000"
+)
+
+func TestProtect(t *testing.T) {
+	tests := []struct {
+		header     http.Header
+		name       string
+		token      string
+		remoteAddr string
+		status     int
+	}{
+		{
+			name:       "loopback with header",
+			remoteAddr: loopback,
+			header:     http.Header{HeaderRequest: {"1"}},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "loopback ipv6 with header",
+			remoteAddr: "[::1]:41000",
+			header:     http.Header{HeaderRequest: {"1"}},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "loopback without header",
+			remoteAddr: loopback,
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "remote without token",
+			remoteAddr: remoteAddr,
+			header:     http.Header{HeaderRequest: {"1"}},
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "remote with token",
+			token:      testToken,
+			remoteAddr: remoteAddr,
+			header:     http.Header{"Authorization": {"Bearer " + testToken}},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "invalid token",
+			token:      testToken,
+			remoteAddr: loopback,
+			header:     http.Header{"Authorization": {"Bearer wrong"}, HeaderRequest: {"1"}},
+			status:     http.StatusUnauthorized,
+		},
+		{
+			name:       "missing token",
+			token:      testToken,
+			remoteAddr: loopback,
+			header:     http.Header{HeaderRequest: {"1"}},
+			status:     http.StatusUnauthorized,
+		},
+		{
+			name:       "cross-site origin",
+			token:      testToken,
+			remoteAddr: remoteAddr,
+			header:     http.Header{"Authorization": {"Bearer " + testToken}, "Origin": {"https://evil.example.com"}},
+			status:     http.StatusForbidden,
+		},
+		{
+			name:       "same origin",
+			remoteAddr: loopback,
+			header:     http.Header{HeaderRequest: {"1"}, "Origin": {"http://tsdproxy:8080"}},
+			status:     http.StatusOK,
+		},
+		{
+			name:       "cross-site fetch",
+			remoteAddr: loopback,
+			header:     http.Header{HeaderRequest: {"1"}, "Sec-Fetch-Site": {"cross-site"}},
+			status:     http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			api := &API{Log: zerolog.Nop(), HTTP: core.NewHTTPServer(zerolog.Nop()), token: tt.token}
+			handler := api.Protect(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodPost, "http://tsdproxy:8080"+Prefix+"/proxies/nginx/restart", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for name, values := range tt.header {
+				r.Header[name] = values
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+
+			if w.Code != tt.status {
+				t.Errorf("status: got %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestProtectedRoutes(t *testing.T) {
+	api := &API{Log: zerolog.Nop(), HTTP: core.NewHTTPServer(zerolog.Nop())}
+	api.AddRoutes()
+
+	routes := []struct{ method, path string }{
+		{http.MethodPost, "/proxies/nginx/start"},
+		{http.MethodPost, "/proxies/nginx/stop"},
+		{http.MethodPost, "/proxies/nginx/restart"},
+		{http.MethodPost, "/proxies/nginx/maintenance"},
+		{http.MethodDelete, "/proxies/nginx/maintenance"},
+		{http.MethodPost, "/proxies/nginx/share"},
+		{http.MethodDelete, "/proxies/nginx/cache"},
+	}
+
+	for _, route := range routes {
+		r := httptest.NewRequest(route.method, "http://tsdproxy:8080"+Prefix+route.path, nil)
+		r.RemoteAddr = remoteAddr
+		w := httptest.NewRecorder()
+		api.HTTP.Mux.ServeHTTP(w, r)
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s %s: got %d, want %d", route.method, route.path, w.Code, http.StatusForbidden)
+		}
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"flag"
 	"io/fs"
 	"os"
+	"sync/atomic"
+	"time"
 
 	"github.com/creasty/defaults"
 	"github.com/rs/zerolog/log"
@@ -17,16 +19,25 @@ type (
 	// config stores complete configuration.
 	//
 	config struct {
+		Docker     map[string]*DockerTargetProviderConfig     `validate:"dive,required" yaml:"docker"`
+		Kubernetes map[string]*KubernetesTargetProviderConfig `validate:"dive,required" yaml:"kubernetes,omitempty"`
+		Podman     map[string]*PodmanTargetProviderConfig     `validate:"dive,required" yaml:"podman,omitempty"`
+		Lists      map[string]*ListTargetProviderConfig       `validate:"dive,required" yaml:"lists"`
+
 		DefaultProxyProvider string `validate:"required" default:"default" yaml:"defaultProxyProvider"`
+		HostnameConflict     string `validate:"oneof=error suffix" default:"error" yaml:"hostnameConflict"`
+
+		Tailscale TailscaleProxyProviderConfig `yaml:"tailscale"`
 
-		Docker    map[string]*DockerTargetProviderConfig `validate:"dive,required" yaml:"docker"`
-		Lists     map[string]*ListTargetProviderConfig   `validate:"dive,required" yaml:"lists"`
-		Tailscale TailscaleProxyProviderConfig           `yaml:"tailscale"`
+		HTTP    HTTPConfig    `yaml:"http"`
+		Log     LogConfig     `yaml:"log"`
+		Tracing TracingConfig `yaml:"tracing"`
 
-		HTTP HTTPConfig `yaml:"http"`
-		Log  LogConfig  `yaml:"log"`
+		ProxyAccessLog AccessLogConfig `yaml:"proxyAccessLog"`
 
-		ProxyAccessLog bool `validate:"boolean" default:"true" yaml:"proxyAccessLog"`
+		Restart   RestartConfig   `yaml:"restart"`
+		Transport TransportConfig `yaml:"transport"`
+		RateLimit RateLimitConfig `yaml:"rateLimit"`
 	}
 
 	// LogConfig stores logging configuration.
@@ -35,9 +46,77 @@ type (
 		JSON  bool   `validate:"boolean" default:"false" yaml:"json"`
 	}
 
-	// HTTPConfig stores HTTP configuration.
+	// TracingConfig stores OpenTelemetry tracing configuration.
+	TracingConfig struct {
+		Headers     map[string]string `validate:"omitempty" yaml:"headers,omitempty"`
+		SampleRatio *float64          `validate:"omitempty,min=0,max=1" yaml:"sampleRatio,omitempty"`
+		Endpoint    string            `validate:"required_if=Enabled true,omitempty,hostname_port" yaml:"endpoint"`
+		Protocol    string            `validate:"oneof=grpc http" default:"grpc" yaml:"protocol"`
+		ServiceName string            `validate:"required" default:"tsdproxy" yaml:"serviceName"`
+		Enabled     bool              `validate:"boolean" default:"false" yaml:"enabled"`
+		Insecure    bool              `validate:"boolean" default:"false" yaml:"insecure"`
+	}
+
+	// RestartConfig stores the configuration of the automatic restart of failed
+	// proxies. The delay between attempts doubles from InitialDelay up to MaxDelay.
+	RestartConfig struct {
+		InitialDelay time.Duration `validate:"min=0" default:"5s" yaml:"initialDelay"`
+		MaxDelay     time.Duration `validate:"gtefield=InitialDelay" default:"5m" yaml:"maxDelay"`
+		MaxAttempts  int           `validate:"min=0" default:"5" yaml:"maxAttempts"`
+		Disabled     bool          `validate:"boolean" default:"false" yaml:"disabled"`
+	}
+
+	// TransportConfig stores the default timeouts and connection settings of
+	// the HTTP ports, used when not configured in the port. Zero or negative
+	// durations disable the timeout.
+	TransportConfig struct {
+		DialTimeout           time.Duration `default:"30s" yaml:"dialTimeout"`
+		KeepAlive             time.Duration `default:"30s" yaml:"keepAlive"`
+		ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout"`
+		IdleConnTimeout       time.Duration `default:"90s" yaml:"idleConnTimeout"`
+		ReadHeaderTimeout     time.Duration `default:"5s" yaml:"readHeaderTimeout"`
+		ReadTimeout           time.Duration `yaml:"readTimeout"`
+		WriteTimeout          time.Duration `yaml:"writeTimeout"`
+		MaxIdleConns          int           `validate:"min=0" default:"100" yaml:"maxIdleConns"`
+		MaxIdleConnsPerHost   int           `validate:"min=0" default:"10" yaml:"maxIdleConnsPerHost"`
+	}
+
+	// RateLimitConfig stores the global rate limit, shared by all proxies:
+	// Requests requests per Period for each user, with bursts of Burst
+	// requests. Requests are not limited if Requests is 0.
+	RateLimitConfig struct {
+		Requests int           `validate:"min=0" yaml:"requests"`
+		Period   time.Duration `validate:"min=0" default:"1s" yaml:"period"`
+		Burst    int           `validate:"min=0" yaml:"burst"`
+	}
+
+	// AccessLogConfig stores the defaults of the proxy access logs, used when
+	// not configured in the proxy. Files are rotated when bigger than MaxSize
+	// megabytes, keeping MaxBackups old files, and are not rotated if MaxSize
+	// is 0. The access logs of all proxies are disabled if Disabled is true.
+	AccessLogConfig struct {
+		Syslog     SyslogConfig `yaml:"syslog"`
+		Format     string       `validate:"oneof=common combined json" default:"combined" yaml:"format"`
+		Output     string       `validate:"oneof=log file syslog" default:"log" yaml:"output"`
+		Dir        string       `validate:"required" default:"/data/accesslog" yaml:"dir"`
+		MaxSize    int          `validate:"min=0" default:"100" yaml:"maxSize"`
+		MaxBackups int          `validate:"min=0" default:"5" yaml:"maxBackups"`
+		Disabled   bool         `validate:"boolean" default:"false" yaml:"disabled"`
+	}
+
+	// SyslogConfig stores the syslog server of the access logs. The local
+	// syslog server is used if Network is empty.
+	SyslogConfig struct {
+		Network string `validate:"omitempty,oneof=udp tcp unix unixgram" yaml:"network,omitempty"`
+		Address string `validate:"required_with=Network" yaml:"address,omitempty"`
+		Tag     string `validate:"required" default:"tsdproxy" yaml:"tag"`
+	}
+
+	// HTTPConfig stores HTTP configuration. APIToken is the bearer token of the
+	// management API routes that change the proxies.
 	HTTPConfig struct {
 		Hostname string `validate:"ip|hostname,required" default:"0.0.0.0" yaml:"hostname"`
+		APIToken string `validate:"omitempty,min=16" yaml:"apiToken,omitempty"`
 		Port     uint16 `validate:"numeric,min=1,max=65535,required" default:"8080" yaml:"port"`
 	}
 
@@ -49,6 +128,22 @@ type (
 		TryDockerInternalNetwork bool   `validate:"boolean" default:"true" yaml:"tryDockerInternalNetwork"`
 	}
 
+	// KubernetesTargetProviderConfig struct stores Kubernetes target provider configuration.
+	KubernetesTargetProviderConfig struct {
+		Kubeconfig           string `validate:"omitempty,file" yaml:"kubeconfig,omitempty"`
+		Namespace            string `validate:"omitempty" yaml:"namespace,omitempty"`
+		DefaultProxyProvider string `validate:"omitempty" yaml:"defaultProxyProvider,omitempty"`
+		ClusterDomain        string `validate:"hostname" default:"cluster.local" yaml:"clusterDomain"`
+	}
+
+	// PodmanTargetProviderConfig struct stores Podman target provider configuration.
+	PodmanTargetProviderConfig struct {
+		Host                 string `validate:"required,uri" default:"unix:///run/podman/podman.sock" yaml:"host"`
+		TargetHostname       string `validate:"ip|hostname" default:"host.containers.internal" yaml:"targetHostname"`
+		DefaultProxyProvider string `validate:"omitempty" yaml:"defaultProxyProvider,omitempty"`
+		TryInternalNetwork   bool   `validate:"boolean" default:"true" yaml:"tryInternalNetwork"`
+	}
+
 	// TailscaleProxyProviderConfig struct stores Tailscale ProxyProvider configuration
 	TailscaleProxyProviderConfig struct {
 		Providers map[string]*TailscaleServerConfig `validate:"dive,required" yaml:"providers"`
@@ -73,54 +168,151 @@ type (
 	}
 )
 
-// Config  is a global variable to store configuration.
-var Config *config
+// Policies of hostname conflicts between targets of different target providers
+// or containers.
+const (
+	// HostnameConflictError doesn't start the proxy of the new target.
+	HostnameConflictError = "error"
+	// HostnameConflictSuffix starts the proxy of the new target with a numeric
+	// suffix added to the hostname.
+	HostnameConflictSuffix = "suffix"
+)
+
+// current stores the configuration in use. Reloads replace it with a new
+// configuration, so it's read with Get.
+var current atomic.Pointer[config]
+
+// configFilename stores the path of the configuration file to be reloaded.
+var configFilename string
+
+// dryRun is true when the configuration is validated without starting the server.
+var dryRun bool
 
 // GetConfig loads, validates and returns configuration.
 func InitializeConfig() error {
-	Config = &config{}
-	Config.Tailscale.Providers = make(map[string]*TailscaleServerConfig)
-	Config.Docker = make(map[string]*DockerTargetProviderConfig)
-	Config.Lists = make(map[string]*ListTargetProviderConfig)
+	c := newConfig()
 
 	file := flag.String("config", "/config/tsdproxy.yaml", "loag configuration from file")
+	flag.BoolVar(&dryRun, "validate", false, "validate the configuration and list the proxies without starting the server")
+	flag.BoolVar(&dryRun, "dry-run", false, "alias of -validate")
 	flag.Parse()
 
-	fileConfig := NewConfigFile(log.Logger, *file, Config)
+	configFilename = *file
+
+	fileConfig := NewConfigFile(log.Logger, *file, c)
 
 	println("loading configuration from:", *file)
 
 	if err := fileConfig.Load(); err != nil {
-		if !errors.Is(err, fs.ErrNotExist) {
+		if !errors.Is(err, fs.ErrNotExist) || dryRun {
 			return err
 		}
 		println("Generating default configuration to:", *file)
 
-		if err := defaults.Set(Config); err != nil {
+		if err := defaults.Set(c); err != nil {
 			log.Error().Err(err).Msg("failed to load defaults")
 		}
 
-		Config.generateDefaultProviders()
+		c.generateDefaultProviders()
 		if err := fileConfig.Save(); err != nil {
 			return err
 		}
 	}
 
+	if err := c.setup(); err != nil {
+		return err
+	}
+	current.Store(c)
+
+	return nil
+}
+
+// Get function returns the configuration in use. The configuration must not
+// be modified, as it's shared by all goroutines until the next reload.
+func Get() *config { //nolint:revive
+	return current.Load()
+}
+
+// GetSampleRatio method returns the ratio of sampled traces, 1 if not set.
+// It isn't a default value, as a sample ratio of 0 is valid.
+func (t TracingConfig) GetSampleRatio() float64 {
+	if t.SampleRatio == nil {
+		return 1
+	}
+
+	return *t.SampleRatio
+}
+
+// DryRun function returns true if the server was started to validate the
+// configuration only.
+func DryRun() bool {
+	return dryRun
+}
+
+// ValidateFile function loads and validates a configuration file, without
+// changing the configuration in use.
+func ValidateFile(filename string) error {
+	_, err := loadFile(filename)
+
+	return err
+}
+
+// LoadFile function loads and validates a configuration file, and replaces
+// the configuration in use, without watching the file.
+func LoadFile(filename string) error {
+	c, err := loadFile(filename)
+	if err != nil {
+		return err
+	}
+	current.Store(c)
+
+	return nil
+}
+
+// loadFile function returns the validated configuration of a file.
+func loadFile(filename string) (*config, error) {
+	c := newConfig()
+
+	if err := NewConfigFile(log.Logger, filename, c).Load(); err != nil {
+		return nil, err
+	}
+	if err := c.setup(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// newConfig function returns an empty configuration with initialized maps.
+func newConfig() *config {
+	c := &config{}
+	c.Tailscale.Providers = make(map[string]*TailscaleServerConfig)
+	c.Docker = make(map[string]*DockerTargetProviderConfig)
+	c.Kubernetes = make(map[string]*KubernetesTargetProviderConfig)
+	c.Podman = make(map[string]*PodmanTargetProviderConfig)
+	c.Lists = make(map[string]*ListTargetProviderConfig)
+
+	return c
+}
+
+// setup method sets default values, loads auth keys from files and validates
+// the configuration loaded from file.
+func (c *config) setup() error {
 	// Load default values.
 	// Make sure to set default values after loading from file
 	// unless defaults of map type are not loaded.
-	if err := defaults.Set(Config); err != nil {
+	if err := defaults.Set(c); err != nil {
 		log.Error().Err(err).Msg("failed to load defaults")
 	}
 
 	// load auth keys from files
-	for _, d := range Config.Tailscale.Providers {
+	for _, d := range c.Tailscale.Providers {
 		if d != nil && d.ClientSecret != "" && d.ClientID != "" {
 			continue
 		}
 
 		if d != nil && d.AuthKeyFile != "" {
-			authkey, err := Config.getAuthKeyFromFile(d.AuthKeyFile)
+			authkey, err := c.getAuthKeyFromFile(d.AuthKeyFile)
 			if err != nil {
 				return err
 			}
@@ -129,11 +321,21 @@ func InitializeConfig() error {
 	}
 
 	// validate config
-	if err := Config.validate(); err != nil {
-		return err
+	return c.validate()
+}
+
+// UnmarshalYAML method loads the access log configuration, also accepting the
+// boolean of previous versions.
+func (a *AccessLogConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	var enabled bool
+	if err := unmarshal(&enabled); err == nil {
+		a.Disabled = !enabled
+		return nil
 	}
 
-	return nil
+	type plain AccessLogConfig
+
+	return unmarshal((*plain)(a))
 }
 
 func (c *config) getAuthKeyFromFile(authKeyFile string) (string, error) {
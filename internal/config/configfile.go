@@ -104,7 +104,7 @@ func (f *ConfigFile) Watch() {
 		// Start listening for events.
 		go func() {
 			defer eventsWG.Done()
-			f.watchEvents(watcher, file, &eventsWG)
+			f.watchEvents(watcher, file)
 		}()
 
 		err = watcher.Add(dir)
@@ -118,7 +118,7 @@ func (f *ConfigFile) Watch() {
 	initWG.Wait()
 }
 
-func (f *ConfigFile) watchEvents(watcher *fsnotify.Watcher, file string, eventsWG *sync.WaitGroup) {
+func (f *ConfigFile) watchEvents(watcher *fsnotify.Watcher, file string) {
 	realFile, err := filepath.EvalSymlinks(f.filename)
 	if err != nil {
 		f.log.Warn().Err(err).Msg("failed to evaluate symlinks, using raw filename")
@@ -131,7 +131,9 @@ func (f *ConfigFile) watchEvents(watcher *fsnotify.Watcher, file string, eventsW
 			if !ok {
 				return
 			}
-			f.handleEvent(event, file, &realFile, eventsWG)
+			if removed := f.handleEvent(event, file, &realFile); removed {
+				return
+			}
 		case err, ok := <-watcher.Errors:
 			if ok {
 				f.log.Error().Err(err).Msg("watching config file error")
@@ -141,19 +143,27 @@ func (f *ConfigFile) watchEvents(watcher *fsnotify.Watcher, file string, eventsW
 	}
 }
 
-func (f *ConfigFile) handleEvent(event fsnotify.Event, file string, realFile *string, eventsWG *sync.WaitGroup) {
+// handleEvent method calls the onChange handler on file changes, and returns
+// true if the file was removed.
+func (f *ConfigFile) handleEvent(event fsnotify.Event, file string, realFile *string) bool {
 	currentFile, _ := filepath.EvalSymlinks(f.filename)
 	if (filepath.Clean(event.Name) == file &&
 		(event.Has(fsnotify.Write) || event.Has(fsnotify.Create))) ||
 		(currentFile != "" && currentFile != *realFile) {
 		*realFile = currentFile
 
-		if f.onChange != nil {
-			f.onChange(event)
+		f.mtx.Lock()
+		onChange := f.onChange
+		f.mtx.Unlock()
+
+		if onChange != nil {
+			onChange(event)
 		}
 	} else if filepath.Clean(event.Name) == file && event.Has(fsnotify.Remove) {
-		eventsWG.Done()
+		return true
 	}
+
+	return false
 }
 
 func unmarshalStrict(data []byte, out any) error {
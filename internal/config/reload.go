@@ -0,0 +1,159 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"reflect"
+	"slices"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog/log"
+)
+
+type (
+	// Changes struct stores the providers changed by a configuration reload.
+	Changes struct {
+		TargetProviders      ProviderChanges
+		ProxyProviders       ProviderChanges
+		DefaultProxyProvider bool
+	}
+
+	// ProviderChanges struct stores the names of added, removed and changed providers.
+	ProviderChanges struct {
+		Added   []string
+		Removed []string
+		Changed []string
+	}
+
+	// targetProviderConfig struct identifies a target provider configuration,
+	// as docker, kubernetes, podman and list providers share the same names.
+	targetProviderConfig struct {
+		config any
+		kind   string
+	}
+)
+
+// IsEmpty method returns true if no provider has changed.
+func (c Changes) IsEmpty() bool {
+	return c.TargetProviders.IsEmpty() && c.ProxyProviders.IsEmpty() && !c.DefaultProxyProvider
+}
+
+// IsEmpty method returns true if no provider was added, removed or changed.
+func (p ProviderChanges) IsEmpty() bool {
+	return len(p.Added) == 0 && len(p.Removed) == 0 && len(p.Changed) == 0
+}
+
+// WatchConfig function watches the configuration file and replaces the
+// configuration when it changes. Invalid configurations are ignored. onReload is called
+// with the providers changed by the new configuration.
+func WatchConfig(onReload func(Changes)) {
+	logger := log.With().Str("module", "config").Logger()
+
+	file := NewConfigFile(logger, configFilename, nil)
+	file.OnChange(func(_ fsnotify.Event) {
+		logger.Info().Str("file", configFilename).Msg("configuration file changed, reloading")
+
+		newConfig, err := reloadConfig()
+		if err != nil {
+			logger.Error().Err(err).Msg("invalid configuration, keeping the current one")
+			return
+		}
+
+		changes := Get().diff(newConfig)
+		current.Store(newConfig)
+
+		if changes.IsEmpty() {
+			logger.Debug().Msg("no provider changed")
+			return
+		}
+
+		onReload(changes)
+	})
+	file.Watch()
+}
+
+// reloadConfig function loads a new configuration from the configuration file.
+func reloadConfig() (*config, error) {
+	c := newConfig()
+
+	if err := NewConfigFile(log.Logger, configFilename, c).Load(); err != nil {
+		return nil, err
+	}
+
+	if err := c.setup(); err != nil {
+		return nil, err
+	}
+
+	// sections used at startup keep their current values
+	//
+	old := Get()
+	if !reflect.DeepEqual(c.HTTP, old.HTTP) ||
+		!reflect.DeepEqual(c.Log, old.Log) ||
+		!reflect.DeepEqual(c.Tracing, old.Tracing) ||
+		c.Tailscale.DataDir != old.Tailscale.DataDir {
+		log.Warn().Msg("changes to http, log, tracing and tailscale.dataDir require a restart")
+	}
+	c.HTTP = old.HTTP
+	c.Log = old.Log
+	c.Tracing = old.Tracing
+	c.Tailscale.DataDir = old.Tailscale.DataDir
+
+	return c, nil
+}
+
+// diff method returns the providers changed from c to newConfig.
+func (c *config) diff(newConfig *config) Changes {
+	return Changes{
+		TargetProviders:      diffProviders(c.targetProviders(), newConfig.targetProviders()),
+		ProxyProviders:       diffProviders(c.Tailscale.Providers, newConfig.Tailscale.Providers),
+		DefaultProxyProvider: c.DefaultProxyProvider != newConfig.DefaultProxyProvider,
+	}
+}
+
+// targetProviders method returns the configuration of all target providers by name.
+func (c *config) targetProviders() map[string]targetProviderConfig {
+	providers := make(map[string]targetProviderConfig)
+
+	for name, p := range c.Docker {
+		providers[name] = targetProviderConfig{kind: "docker", config: p}
+	}
+	for name, p := range c.Kubernetes {
+		providers[name] = targetProviderConfig{kind: "kubernetes", config: p}
+	}
+	for name, p := range c.Podman {
+		providers[name] = targetProviderConfig{kind: "podman", config: p}
+	}
+	for name, p := range c.Lists {
+		providers[name] = targetProviderConfig{kind: "list", config: p}
+	}
+
+	return providers
+}
+
+// diffProviders function compares two maps of provider configurations.
+func diffProviders[T any](oldProviders, newProviders map[string]T) ProviderChanges {
+	var changes ProviderChanges
+
+	for name, p := range newProviders {
+		old, ok := oldProviders[name]
+		switch {
+		case !ok:
+			changes.Added = append(changes.Added, name)
+		case !reflect.DeepEqual(old, p):
+			changes.Changed = append(changes.Changed, name)
+		}
+	}
+
+	for name := range oldProviders {
+		if _, ok := newProviders[name]; !ok {
+			changes.Removed = append(changes.Removed, name)
+		}
+	}
+
+	slices.Sort(changes.Added)
+	slices.Sort(changes.Removed)
+	slices.Sort(changes.Changed)
+
+	return changes
+}
@@ -0,0 +1,137 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testConfig is the minimal configuration of the tests, with the data
+// directory.
+const testConfig = `
+tailscale:
+  dataDir: %s
+  providers:
+    default: {}
+`
+
+// writeConfig function writes the configuration file of the tests, with the
+// yaml added to the minimal configuration, and returns its path.
+func writeConfig(t *testing.T, dir, yaml string) string {
+	t.Helper()
+
+	file := filepath.Join(dir, "tsdproxy.yaml")
+	data := []byte(fmt.Sprintf(testConfig, dir) + yaml)
+	if err := os.WriteFile(file, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return file
+}
+
+// listConfig function returns the yaml of a list provider, creating its file.
+func listConfig(t *testing.T, dir, name string) string {
+	t.Helper()
+
+	file := filepath.Join(dir, name+".yaml")
+	if err := os.WriteFile(file, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return "\nlists:\n  " + name + ":\n    filename: " + file + "\n"
+}
+
+// loadTestConfig function loads the configuration file and sets it as the
+// file reloaded.
+func loadTestConfig(t *testing.T, file string) {
+	t.Helper()
+
+	if err := LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+	configFilename = file
+}
+
+func TestDiffProviders(t *testing.T) {
+	oldProviders := map[string]string{"a": "1", "b": "2", "c": "3"}
+	newProviders := map[string]string{"b": "2", "c": "4", "d": "5"}
+
+	got := diffProviders(oldProviders, newProviders)
+	want := ProviderChanges{Added: []string{"d"}, Removed: []string{"a"}, Changed: []string{"c"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+
+	if !diffProviders(oldProviders, oldProviders).IsEmpty() {
+		t.Error("changes without provider changes")
+	}
+}
+
+func TestDiffTargetProviderKind(t *testing.T) {
+	oldConfig, newConfig := newConfig(), newConfig()
+	oldConfig.Docker["local"] = &DockerTargetProviderConfig{Host: "unix:///var/run/docker.sock"}
+	newConfig.Podman["local"] = &PodmanTargetProviderConfig{Host: "unix:///var/run/docker.sock"}
+
+	changes := oldConfig.diff(newConfig)
+	if !reflect.DeepEqual(changes.TargetProviders.Changed, []string{"local"}) {
+		t.Errorf("provider of another kind not changed: %+v", changes.TargetProviders)
+	}
+}
+
+func TestReloadConfig(t *testing.T) {
+	dir := t.TempDir()
+	loadTestConfig(t, writeConfig(t, dir, "http:\n  port: 8080\n"))
+
+	writeConfig(t, dir, "http:\n  port: 9090\n"+listConfig(t, dir, "services"))
+	c, err := reloadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.HTTP.Port != 8080 { //nolint:mnd
+		t.Errorf("http port changed without restart: got %d", c.HTTP.Port)
+	}
+	changes := Get().diff(c)
+	if !reflect.DeepEqual(changes.TargetProviders.Added, []string{"services"}) || !changes.ProxyProviders.IsEmpty() {
+		t.Errorf("changes: got %+v", changes)
+	}
+
+	writeConfig(t, dir, "unknownField: true\n")
+	if _, err := reloadConfig(); err == nil {
+		t.Error("invalid configuration reloaded")
+	}
+}
+
+func TestWatchConfig(t *testing.T) {
+	dir := t.TempDir()
+	loadTestConfig(t, writeConfig(t, dir, ""))
+
+	reloads := make(chan Changes, 10) //nolint:mnd
+	WatchConfig(func(changes Changes) { reloads <- changes })
+
+	// invalid configurations are ignored
+	writeConfig(t, dir, "defaultProxyProvider: missing\n")
+	writeConfig(t, dir, listConfig(t, dir, "services"))
+
+	deadline := time.After(5 * time.Second)
+	for {
+		select {
+		case changes := <-reloads:
+			if !reflect.DeepEqual(changes.TargetProviders.Added, []string{"services"}) {
+				continue
+			}
+			if _, ok := Get().Lists["services"]; !ok {
+				t.Error("configuration not replaced")
+			}
+			return
+		case <-deadline:
+			t.Fatal("configuration not reloaded")
+		}
+	}
+}
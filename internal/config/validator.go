@@ -21,60 +21,80 @@ func (e *DefaultProxyProviderNotFoundError) Error() string {
 
 var ErrNoDefaultProxyProvider = errors.New("no default proxy provider")
 
-// validate method  Validate configurations.
+// validate method validates the configuration and the references between
+// providers, returning all the errors found.
 func (c *config) validate() error {
 	println("Validating configuration...")
 	validate := validator.New()
 
-	if err := validate.Struct(Config); err != nil {
-		// validationErrors := err.(validator.ValidationErrors)
-		var validationErrors validator.ValidationErrors
-		if errors.As(err, &validationErrors) {
-			for _, e := range validationErrors {
-				fmt.Println(e)
-			}
-			return err
-		}
-	}
+	var errs error
 
-	// TODO: add validation for each provider
-	// TODO: add default proxy provider to each proxy if not defined
-	//
+	if err := validate.Struct(c); err != nil {
+		errs = errors.Join(errs, err)
+	}
 
 	// Set default Proxy Provider if not set.
 	//
 	if c.DefaultProxyProvider != "" {
-		if !c.hasProxyProvider(c.DefaultProxyProvider) {
-			return &DefaultProxyProviderNotFoundError{ProviderName: c.DefaultProxyProvider}
+		if !c.HasProxyProvider(c.DefaultProxyProvider) {
+			errs = errors.Join(errs, &DefaultProxyProviderNotFoundError{ProviderName: c.DefaultProxyProvider})
 		}
 	} else {
-		var temp string
-		var err error
-		if temp, err = c.getDefaultProxyProvider(); err != nil {
-			return err
+		temp, err := c.getDefaultProxyProvider()
+		if err != nil {
+			return errors.Join(errs, err)
 		}
 		c.DefaultProxyProvider = strings.ToLower(temp)
 	}
 
-	// add default proxy provider to docker providers
+	// add default proxy provider to target providers, so all proxies have one
 	//
-	err := c.addDefaultProxyProviderToDockerProviders()
-	if err != nil {
-		return err
-	}
-	return nil
+	return errors.Join(errs, c.setTargetProvidersDefaultProxyProvider())
 }
 
-func (c *config) addDefaultProxyProviderToDockerProviders() error {
-	for _, p := range c.Docker {
-		if p.DefaultProxyProvider == "" {
-			p.DefaultProxyProvider = c.DefaultProxyProvider
-		} else {
-			if !c.hasProxyProvider(p.DefaultProxyProvider) {
-				return &DefaultProxyProviderNotFoundError{ProviderName: p.DefaultProxyProvider}
-			}
+// setTargetProvidersDefaultProxyProvider method sets the default proxy
+// provider of the target providers without one.
+func (c *config) setTargetProvidersDefaultProxyProvider() error {
+	var errs error
+
+	for name, p := range c.Docker {
+		if p != nil {
+			errs = errors.Join(errs, c.setDefaultProxyProvider("docker", name, &p.DefaultProxyProvider))
+		}
+	}
+	for name, p := range c.Kubernetes {
+		if p != nil {
+			errs = errors.Join(errs, c.setDefaultProxyProvider("kubernetes", name, &p.DefaultProxyProvider))
+		}
+	}
+	for name, p := range c.Podman {
+		if p != nil {
+			errs = errors.Join(errs, c.setDefaultProxyProvider("podman", name, &p.DefaultProxyProvider))
+		}
+	}
+	for name, p := range c.Lists {
+		if p != nil {
+			errs = errors.Join(errs, c.setDefaultProxyProvider("list", name, &p.DefaultProxyProvider))
 		}
 	}
+
+	return errs
+}
+
+// setDefaultProxyProvider method sets the default proxy provider of a target
+// provider if it has none, or returns an error if its proxy provider doesn't
+// exist.
+func (c *config) setDefaultProxyProvider(kind, name string, defaultProxyProvider *string) error {
+	if *defaultProxyProvider == "" {
+		*defaultProxyProvider = c.DefaultProxyProvider
+		return nil
+	}
+
+	if !c.HasProxyProvider(*defaultProxyProvider) {
+		return fmt.Errorf("%s %s: %w", kind, name,
+			&DefaultProxyProviderNotFoundError{ProviderName: *defaultProxyProvider})
+	}
+
 	return nil
 }
 
@@ -85,7 +105,8 @@ func (c *config) getDefaultProxyProvider() (string, error) {
 	return "", ErrNoDefaultProxyProvider
 }
 
-func (c *config) hasProxyProvider(name string) bool {
+// HasProxyProvider method returns true if the Tailscale provider is defined.
+func (c *config) HasProxyProvider(name string) bool {
 	for n := range c.Tailscale.Providers {
 		if strings.EqualFold(n, name) {
 			return true
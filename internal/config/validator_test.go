@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package config
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateFile(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := ValidateFile(writeConfig(t, dir, listConfig(t, dir, "services"))); err != nil {
+		t.Errorf("valid configuration: %v", err)
+	}
+
+	// all the unknown provider references are reported
+	file := writeConfig(t, dir, `
+defaultProxyProvider: missing
+lists:
+  services:
+    filename: `+dir+`/services.yaml
+    defaultProxyProvider: other
+`)
+	err := ValidateFile(file)
+
+	var notFound *DefaultProxyProviderNotFoundError
+	if !errors.As(err, &notFound) {
+		t.Fatalf("unknown proxy providers: got %v", err)
+	}
+	for _, want := range []string{"provider missing not found", "list services: Default proxy provider other not found"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("error %q doesn't report %q", err, want)
+		}
+	}
+
+	if err := ValidateFile(dir + "/missing.yaml"); err == nil {
+		t.Error("missing file validated")
+	}
+}
+
+func TestValidateDefaultProxyProvider(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := LoadFile(writeConfig(t, dir, listConfig(t, dir, "services"))); err != nil {
+		t.Fatal(err)
+	}
+
+	// the default proxy provider is set on the target providers without one
+	if c := Get(); c.DefaultProxyProvider != "default" || c.Lists["services"].DefaultProxyProvider != "default" {
+		t.Errorf("default proxy provider: got %q, list %q", c.DefaultProxyProvider, c.Lists["services"].DefaultProxyProvider)
+	}
+}
@@ -10,6 +10,7 @@ const (
 	HeaderUsername      = "X-tsdproxy-username"
 	HeaderDisplayName   = "x-tsdproxy-displayName"
 	HeaderProfilePicURL = "x-tsdproxy-profilePicUrl"
+	HeaderCacheStatus   = "X-Cache-Status"
 )
 
 // Concurrency and Buffer Sizes
@@ -41,3 +42,9 @@ const (
 	// StaleClientThreshold is the duration after which a client is considered stale
 	StaleClientThreshold = 15 * time.Minute
 )
+
+// Dashboard
+const (
+	// DashboardHistoryEvents is the number of status events shown in the proxy details
+	DashboardHistoryEvents = 10
+)
@@ -59,6 +59,11 @@ func (a *HTTPServer) Post(pattern string, handler http.Handler) {
 	a.Handle("POST "+pattern, handler)
 }
 
+// Delete method add a DELETE handler
+func (a *HTTPServer) Delete(pattern string, handler http.Handler) {
+	a.Handle("DELETE "+pattern, handler)
+}
+
 // StartServer starts a custom http server.
 func (a *HTTPServer) StartServer(s *http.Server) error {
 	a.Server = s
@@ -128,7 +133,7 @@ func (a *HTTPServer) ErrorResponse(w http.ResponseWriter, _ *http.Request, span
 
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.Header().Set("X-Content-Type-Options", "nosniff")
-	w.WriteHeader(http.StatusOK)
+	w.WriteHeader(code)
 	_, err = w.Write(a.prettyJSON(body))
 	if err != nil {
 		a.Log.Error().Err(err).Msg("Write failed in ErrorResponse")
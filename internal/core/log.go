@@ -4,9 +4,7 @@
 package core
 
 import (
-	"bufio"
 	"errors"
-	"net"
 	"net/http"
 	"os"
 
@@ -23,14 +21,15 @@ func NewLog() zerolog.Logger {
 
 	var logger zerolog.Logger
 
-	if config.Config.Log.JSON {
+	cfg := config.Get().Log
+	if cfg.JSON {
 		logger = zerolog.New(os.Stderr).With().Timestamp().Logger()
 	} else {
 		logger = zerolog.New(zerolog.ConsoleWriter{Out: os.Stdout}).With().Timestamp().Logger()
 	}
 
 	log.Logger = logger
-	logLevel, err := zerolog.ParseLevel(config.Config.Log.Level)
+	logLevel, err := zerolog.ParseLevel(cfg.Level)
 	if err != nil {
 		logger.Fatal().Err(err).Msg("Could not parse log level")
 	}
@@ -40,61 +39,23 @@ func NewLog() zerolog.Logger {
 	}
 
 	zerolog.SetGlobalLevel(logLevel)
-	logger.Info().Str("Log level", config.Config.Log.Level).Msg("Log Settings")
+	logger.Info().Str("Log level", cfg.Level).Msg("Log Settings")
 
 	return logger
 }
 
-// LogRecord warps a http.ResponseWriter and records the status.
-type LogRecord struct {
-	err error
-	http.ResponseWriter
-	status int
-}
-
-// WriteHeader overrides ResponseWriter.WriteHeader to keep track of the response code.
-func (r *LogRecord) WriteHeader(status int) {
-	r.status = status
-	r.ResponseWriter.WriteHeader(status)
-}
-
-func (r *LogRecord) Write(data []byte) (int, error) {
-	n, err := r.ResponseWriter.Write(data)
-	if err != nil {
-		r.err = err
-	}
-
-	return n, err
-}
-
-func (r *LogRecord) Hijack() (net.Conn, *bufio.ReadWriter, error) {
-	h, ok := r.ResponseWriter.(http.Hijacker)
-	if !ok {
-		return nil, nil, ErrHijackNotSupported
-	}
-	return h.Hijack()
-}
-
-func (r *LogRecord) Flush() {
-	r.ResponseWriter.(http.Flusher).Flush()
-}
-
 // LoggerMiddleware is a middleware function that logs incoming HTTP requests.
 func LoggerMiddleware(l zerolog.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		lw := &LogRecord{
-			ResponseWriter: w,
-			status:         http.StatusOK,
-		}
+		lw := NewResponseRecorder(w)
 
 		// Call the next handler in the chain
-		// lw := &loggingResponseWriter{ResponseWriter: w, status: http.StatusOK}
 		next.ServeHTTP(lw, r)
 		// Log the request method and URL
-		if lw.status >= http.StatusBadRequest {
+		if lw.Status >= http.StatusBadRequest {
 			l.Error().
-				Err(lw.err).
-				Int("status", lw.status).
+				Err(lw.Err).
+				Int("status", lw.Status).
 				Str("method", r.Method).
 				Str("host", r.Host).
 				Str("client", r.RemoteAddr).
@@ -102,7 +63,7 @@ func LoggerMiddleware(l zerolog.Logger, next http.Handler) http.Handler {
 				Msg("error")
 		} else {
 			l.Info().
-				Int("status", lw.status).
+				Int("status", lw.Status).
 				Str("method", r.Method).
 				Str("host", r.Host).
 				Str("client", r.RemoteAddr).
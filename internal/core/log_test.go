@@ -0,0 +1,105 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// syncBuffer struct is a buffer written and read by several goroutines.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mtx sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.buf.String()
+}
+
+// waitLog function waits until the log contains s.
+func waitLog(t *testing.T, logs *syncBuffer, s string) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), s) {
+		if time.Now().After(deadline) {
+			t.Fatalf("log without %s: %s", s, logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestLoggerMiddlewareStatus(t *testing.T) {
+	var logs syncBuffer
+	srv := httptest.NewServer(LoggerMiddleware(zerolog.New(&logs), http.NotFoundHandler()))
+	t.Cleanup(srv.Close)
+
+	resp, err := http.Get(srv.URL + "/missing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	waitLog(t, &logs, `"status":404`)
+	if !strings.Contains(logs.String(), `"level":"error"`) {
+		t.Errorf("error status not logged as error: %s", logs.String())
+	}
+}
+
+func TestLoggerMiddlewareHijack(t *testing.T) {
+	var logs syncBuffer
+	upgrade := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+
+		_, _ = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")
+		_ = rw.Flush()
+	})
+	srv := httptest.NewServer(LoggerMiddleware(zerolog.New(&logs), upgrade))
+	t.Cleanup(srv.Close)
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("GET / HTTP/1.1\r\nHost: test\r\nUpgrade: test\r\nConnection: Upgrade\r\n\r\n")); err != nil {
+		t.Fatal(err)
+	}
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status: got %d", resp.StatusCode)
+	}
+	waitLog(t, &logs, `"status":101`)
+}
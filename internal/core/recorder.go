@@ -0,0 +1,84 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package core
+
+import (
+	"bufio"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// ResponseRecorder struct wraps a http.ResponseWriter and records the status,
+// the bytes written and the last write error of a response. It's the wrapper of
+// all the middlewares, so streaming, upgrades and http.ResponseController
+// behave the same through all of them: Flush and Hijack reach the first writer
+// that supports them, and Unwrap returns the wrapped writer.
+type ResponseRecorder struct {
+	http.ResponseWriter
+	// Err is the last error returned by Write
+	Err error
+	// Status is the status of the response, http.StatusSwitchingProtocols
+	// for hijacked connections like WebSockets
+	Status int
+	// Bytes is the number of body bytes written
+	Bytes int64
+	// Hijacked is true if the connection was hijacked
+	Hijacked bool
+}
+
+// NewResponseRecorder function returns a recorder of w, with status 200 until
+// a header is written.
+func NewResponseRecorder(w http.ResponseWriter) *ResponseRecorder {
+	return &ResponseRecorder{
+		ResponseWriter: w,
+		Status:         http.StatusOK,
+	}
+}
+
+// WriteHeader method overrides ResponseWriter.WriteHeader to keep track of
+// the response code.
+func (r *ResponseRecorder) WriteHeader(status int) {
+	r.Status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *ResponseRecorder) Write(data []byte) (int, error) {
+	n, err := r.ResponseWriter.Write(data)
+	r.Bytes += int64(n)
+	if err != nil {
+		r.Err = err
+	}
+
+	return n, err
+}
+
+// Hijack method implements http.Hijacker.
+func (r *ResponseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if errors.Is(err, http.ErrNotSupported) {
+		return nil, nil, ErrHijackNotSupported
+	}
+	if err == nil {
+		r.Hijacked = true
+		r.Status = http.StatusSwitchingProtocols
+	}
+
+	return conn, rw, err
+}
+
+// Flush method implements http.Flusher.
+func (r *ResponseRecorder) Flush() {
+	_ = r.FlushError()
+}
+
+// FlushError method flushes the response, used by http.ResponseController.
+func (r *ResponseRecorder) FlushError() error {
+	return http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// Unwrap method returns the wrapped http.ResponseWriter, used by http.ResponseController.
+func (r *ResponseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,81 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package core
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestResponseRecorder(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := NewResponseRecorder(w)
+
+	rec.WriteHeader(http.StatusCreated)
+	if _, err := io.WriteString(rec, "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	if rec.Status != http.StatusCreated || rec.Bytes != 5 || rec.Err != nil || rec.Hijacked {
+		t.Errorf("recorder: got %+v", rec)
+	}
+	if w.Code != http.StatusCreated || w.Body.String() != "hello" {
+		t.Errorf("response: got %d, %q", w.Code, w.Body.String())
+	}
+
+	if _, _, err := rec.Hijack(); !errors.Is(err, ErrHijackNotSupported) {
+		t.Errorf("hijack of a recorder: got %v", err)
+	}
+}
+
+func TestResponseRecorderController(t *testing.T) {
+	// the recorders of several middlewares, like the port middlewares
+	handler := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(NewResponseRecorder(w), r)
+		})
+	}
+
+	flushed := make(chan struct{})
+	srv := httptest.NewServer(handler(handler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Now().Add(5 * time.Second)); err != nil {
+			t.Errorf("write deadline: %v", err)
+		}
+
+		_, _ = io.WriteString(w, "first")
+		if err := rc.Flush(); err != nil {
+			t.Errorf("flush: %v", err)
+		}
+		select {
+		case <-flushed:
+		case <-time.After(5 * time.Second):
+		}
+		_, _ = io.WriteString(w, " second")
+	}))))
+	t.Cleanup(srv.Close)
+
+	client := http.Client{Timeout: 5 * time.Second}
+	resp, err := client.Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	// the first part is received before the handler ends
+	buf := make([]byte, len("first"))
+	if _, err := io.ReadFull(resp.Body, buf); err != nil || string(buf) != "first" {
+		t.Fatalf("streamed part: got %q, %v", buf, err)
+	}
+	close(flushed)
+
+	rest, err := io.ReadAll(resp.Body)
+	if err != nil || string(rest) != " second" {
+		t.Errorf("rest: got %q, %v", rest, err)
+	}
+}
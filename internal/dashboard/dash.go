@@ -4,6 +4,7 @@
 package dashboard
 
 import (
+	"net/http"
 	"sync"
 	"time"
 
@@ -15,21 +16,28 @@ import (
 	"github.com/xybydy/tsdproxy/web"
 
 	"github.com/rs/zerolog"
+	datastar "github.com/starfederation/datastar/sdk/go"
 )
 
 type Dashboard struct {
 	Log        zerolog.Logger
 	HTTP       *core.HTTPServer
 	pm         *proxymanager.ProxyManager
+	protect    core.Middleware
 	sseClients map[string]*sseClient
 	mtx        sync.RWMutex
 }
 
-func NewDashboard(http *core.HTTPServer, log zerolog.Logger, pm *proxymanager.ProxyManager) *Dashboard {
+// NewDashboard function returns a new dashboard. The routes that change the
+// proxies are wrapped with protect, the check of the management API.
+func NewDashboard(http *core.HTTPServer, log zerolog.Logger, pm *proxymanager.ProxyManager,
+	protect core.Middleware,
+) *Dashboard {
 	dash := &Dashboard{
-		Log:  log.With().Str("module", "dashboard").Logger(),
-		HTTP: http,
-		pm:   pm,
+		Log:     log.With().Str("module", "dashboard").Logger(),
+		HTTP:    http,
+		pm:      pm,
+		protect: protect,
 	}
 	dash.sseClients = make(map[string]*sseClient)
 
@@ -42,6 +50,8 @@ func NewDashboard(http *core.HTTPServer, log zerolog.Logger, pm *proxymanager.Pr
 // AddRoutes method add dashboard related routes to the http server
 func (dash *Dashboard) AddRoutes() {
 	dash.HTTP.Get("/stream", dash.streamHandler())
+	dash.HTTP.Post("/proxies/{name}/maintenance", dash.protect(dash.setMaintenance(true)))
+	dash.HTTP.Delete("/proxies/{name}/maintenance", dash.protect(dash.setMaintenance(false)))
 	dash.HTTP.Get("/", web.Static)
 }
 
@@ -88,23 +98,33 @@ func (dash *Dashboard) renderProxy(ch chan SSEMessage, name string, ev EventType
 		label = name
 	}
 
-	ports := make([]model.PortConfig, len(p.Config.Ports))
-	i := 0
-	for _, target := range p.Config.Ports {
-		ports[i] = target
-		i++
+	health := p.GetTargetsHealth()
+
+	ports := make([]pages.PortData, 0, len(p.Config.Ports))
+	for name, port := range p.Config.Ports {
+		ports = append(ports, pages.PortData{
+			Name:    port.String(),
+			Targets: health[name],
+		})
+	}
+
+	events, err := dash.pm.GetHistory(name, consts.DashboardHistoryEvents)
+	if err != nil {
+		dash.Log.Debug().Err(err).Str("proxy", name).Msg("no proxy history")
 	}
 
 	enabled := status == model.ProxyStatusAuthenticating || status == model.ProxyStatusRunning
 
 	a := pages.ProxyData{
 		Enabled:     enabled,
+		Maintenance: p.IsMaintenance(),
 		Name:        name,
 		URL:         url,
 		ProxyStatus: status,
 		Icon:        icon,
 		Label:       label,
 		Ports:       ports,
+		History:     events,
 	}
 
 	ch <- SSEMessage{
@@ -113,6 +133,25 @@ func (dash *Dashboard) renderProxy(ch chan SSEMessage, name string, ev EventType
 	}
 }
 
+// setMaintenance is the HandlerFunc that enables or disables the maintenance
+// mode of a proxy. The proxy is rendered again with the status event.
+func (dash *Dashboard) setMaintenance(enabled bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		name := r.PathValue("name")
+
+		if _, ok := dash.pm.GetProxy(name); !ok {
+			http.Error(w, proxymanager.ErrProxyNotFound.Error(), http.StatusNotFound)
+			return
+		}
+
+		if err := dash.pm.SetMaintenance(name, enabled); err != nil {
+			dash.Log.Error().Err(err).Str("proxy", name).Msg("error changing maintenance mode")
+		}
+
+		datastar.NewSSE(w, r)
+	}
+}
+
 // startClientCleanup periodically removes stale SSE clients
 func (dash *Dashboard) startClientCleanup() {
 	ticker := time.NewTicker(consts.ClientCleanupInterval)
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package dashboard
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/api"
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/proxymanager"
+)
+
+// newTestDashboard function returns a dashboard with the web proxy, protected
+// by the management API without token.
+func newTestDashboard(t *testing.T) *Dashboard {
+	t.Helper()
+
+	httpServer := core.NewHTTPServer(zerolog.Nop())
+	pm := proxymanager.NewProxyManager(zerolog.Nop(), nil)
+	pm.Proxies["web"] = &proxymanager.Proxy{}
+
+	dash := &Dashboard{
+		Log:        zerolog.Nop(),
+		HTTP:       httpServer,
+		pm:         pm,
+		protect:    (&api.API{HTTP: httpServer}).Protect,
+		sseClients: make(map[string]*sseClient),
+	}
+	dash.AddRoutes()
+
+	return dash
+}
+
+// serve method sends the request to the dashboard and returns the response.
+func (dash *Dashboard) serve(method, path, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://tsdproxy:8080"+path, nil)
+	r.RemoteAddr = remoteAddr
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	w := httptest.NewRecorder()
+	dash.HTTP.Mux.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestSetMaintenance(t *testing.T) {
+	dash := newTestDashboard(t)
+	p, _ := dash.pm.GetProxy("web")
+	dashboardRequest := http.Header{api.HeaderRequest: {"dashboard"}}
+
+	// the route is protected like the management API
+	for name, tt := range map[string]struct {
+		header     http.Header
+		remoteAddr string
+	}{
+		"remote client":     {remoteAddr: "100.64.0.1:40000", header: dashboardRequest},
+		"without header":    {remoteAddr: "127.0.0.1:40000"},
+		"cross-site origin": {remoteAddr: "127.0.0.1:40000", header: http.Header{api.HeaderRequest: {"1"}, "Origin": {"https://evil.example.com"}}},
+	} {
+		if w := dash.serve(http.MethodPost, "/proxies/web/maintenance", tt.remoteAddr, tt.header); w.Code != http.StatusForbidden {
+			t.Errorf("%s: got %d", name, w.Code)
+		}
+	}
+	if p.IsMaintenance() {
+		t.Fatal("maintenance enabled by a rejected request")
+	}
+
+	// repeated requests keep the state
+	for _, tt := range []struct {
+		method string
+		want   bool
+	}{
+		{method: http.MethodPost, want: true},
+		{method: http.MethodPost, want: true},
+		{method: http.MethodDelete, want: false},
+		{method: http.MethodDelete, want: false},
+	} {
+		w := dash.serve(tt.method, "/proxies/web/maintenance", "127.0.0.1:40000", dashboardRequest)
+		if w.Code != http.StatusOK || p.IsMaintenance() != tt.want {
+			t.Errorf("%s: got %d, maintenance %v", tt.method, w.Code, p.IsMaintenance())
+		}
+	}
+
+	if w := dash.serve(http.MethodPost, "/proxies/missing/maintenance", "127.0.0.1:40000", dashboardRequest); w.Code != http.StatusNotFound {
+		t.Errorf("unknown proxy: got %d", w.Code)
+	}
+}
@@ -0,0 +1,150 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog"
+	bolt "go.etcd.io/bbolt"
+)
+
+type (
+	// Store struct persists the status timeline of each proxy in a bbolt
+	// database, with a bucket per proxy.
+	Store struct {
+		db        *bolt.DB
+		log       zerolog.Logger
+		maxEvents int
+	}
+
+	// Event struct is an entry of the status timeline of a proxy.
+	Event struct {
+		Time    time.Time `json:"time"`
+		Status  string    `json:"status"`
+		Port    string    `json:"port,omitempty"`
+		Target  string    `json:"target,omitempty"`
+		Health  string    `json:"health,omitempty"`
+		AuthURL string    `json:"authUrl,omitempty"`
+		Error   string    `json:"error,omitempty"`
+		Attempt int       `json:"attempt,omitempty"`
+	}
+)
+
+const (
+	// Filename is the name of the database file in the data directory.
+	Filename = "history.db"
+
+	// DefaultMaxEvents is the number of events kept for each proxy.
+	DefaultMaxEvents = 200
+
+	openTimeout = 2 * time.Second
+)
+
+var ErrProxyNotFound = errors.New("proxy has no history")
+
+// Open function opens or creates the history database.
+func Open(log zerolog.Logger, filename string, maxEvents int) (*Store, error) {
+	db, err := bolt.Open(filename, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("error opening history database: %w", err)
+	}
+
+	return &Store{
+		db:        db,
+		log:       log.With().Str("module", "history").Logger(),
+		maxEvents: maxEvents,
+	}, nil
+}
+
+// Close method closes the database.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Add method appends an event to the timeline of the proxy, removing the
+// oldest events above the limit.
+func (s *Store) Add(proxy string, event Event) error {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("error encoding history event: %w", err)
+	}
+
+	err = s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(proxy))
+		if err != nil {
+			return err
+		}
+
+		seq, err := b.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		if err := b.Put(itob(seq), value); err != nil {
+			return err
+		}
+
+		if seq <= uint64(s.maxEvents) { //nolint:gosec
+			return nil
+		}
+
+		// keys are sequential, remove the ones older than the last maxEvents
+		oldest := itob(seq - uint64(s.maxEvents) + 1) //nolint:gosec
+		c := b.Cursor()
+		for k, _ := c.First(); k != nil && bytes.Compare(k, oldest) < 0; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error saving history event: %w", err)
+	}
+
+	return nil
+}
+
+// Get method returns the last events of the proxy, newest first. All events
+// are returned if limit is zero.
+func (s *Store) Get(proxy string, limit int) ([]Event, error) {
+	events := make([]Event, 0)
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(proxy))
+		if b == nil {
+			return ErrProxyNotFound
+		}
+
+		c := b.Cursor()
+		for k, v := c.Last(); k != nil && (limit == 0 || len(events) < limit); k, v = c.Prev() {
+			var event Event
+			if err := json.Unmarshal(v, &event); err != nil {
+				s.log.Warn().Err(err).Str("proxy", proxy).Msg("invalid history event")
+				continue
+			}
+			events = append(events, event)
+		}
+
+		return nil
+	})
+
+	return events, err
+}
+
+// itob function returns the big endian key of a sequence, so keys are sorted
+// by insertion order.
+func itob(v uint64) []byte {
+	b := make([]byte, 8) //nolint:mnd
+	binary.BigEndian.PutUint64(b, v)
+
+	return b
+}
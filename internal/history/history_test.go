@@ -0,0 +1,80 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package history
+
+import (
+	"errors"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/rs/zerolog"
+)
+
+func TestStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), Filename)
+
+	s, err := Open(zerolog.Nop(), filename, 3) //nolint:mnd
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := s.Get("web", 0); !errors.Is(err, ErrProxyNotFound) {
+		t.Errorf("unknown proxy: got %v", err)
+	}
+
+	for i := range 5 {
+		if err := s.Add("web", Event{Status: "Running", Attempt: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := s.Add("api", Event{Status: "Error", Error: "failed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// only the last events are kept, newest first
+	events, err := s.Get("web", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts(events); got != "432" {
+		t.Errorf("events: got %s, want 432", got)
+	}
+
+	events, err = s.Get("web", 2) //nolint:mnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := attempts(events); got != "43" {
+		t.Errorf("limited events: got %s, want 43", got)
+	}
+
+	// events survive a restart
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	s, err = Open(zerolog.Nop(), filename, 3) //nolint:mnd
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	events, err = s.Get("api", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || events[0].Error != "failed" {
+		t.Errorf("events after reopening: got %+v", events)
+	}
+}
+
+// attempts function returns the attempts of the events as a string.
+func attempts(events []Event) string {
+	s := ""
+	for _, e := range events {
+		s += strconv.Itoa(e.Attempt)
+	}
+
+	return s
+}
@@ -0,0 +1,174 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "tsdproxy"
+
+// Labels used by the proxy metrics.
+const (
+	LabelProxy     = "proxy"
+	LabelPort      = "port"
+	LabelMethod    = "method"
+	LabelCode      = "code"
+	LabelStatus    = "status"
+	LabelDirection = "direction"
+)
+
+// MethodOther is the method label of the requests with non-standard methods.
+const MethodOther = "other"
+
+// Directions of the bytes transferred by a proxy port.
+const (
+	DirectionIn  = "in"
+	DirectionOut = "out"
+)
+
+var (
+	registry = prometheus.NewRegistry()
+
+	requestsTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_total",
+		Help:      "Total number of HTTP requests proxied by proxy, port, method and status code class.",
+	}, []string{LabelProxy, LabelPort, LabelMethod, LabelCode})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the HTTP requests proxied by proxy and port.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{LabelProxy, LabelPort})
+
+	requestsInFlight = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "requests_in_flight",
+		Help:      "Number of HTTP requests being proxied by proxy and port.",
+	}, []string{LabelProxy, LabelPort})
+
+	httpBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "bytes_total",
+		Help:      "Total bytes of the HTTP requests (in) and responses (out) by proxy and port.",
+	}, []string{LabelProxy, LabelPort, LabelDirection})
+
+	streamConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "connections_total",
+		Help:      "Total number of TCP and UDP connections proxied by proxy and port.",
+	}, []string{LabelProxy, LabelPort})
+
+	streamBytes = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "stream",
+		Name:      "bytes_total",
+		Help:      "Total bytes received from clients (in) and from targets (out) by proxy and port.",
+	}, []string{LabelProxy, LabelPort, LabelDirection})
+
+	rateLimited = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "rate_limited_total",
+		Help:      "Total number of HTTP requests rejected by the rate limits by proxy and port.",
+	}, []string{LabelProxy, LabelPort})
+
+	cacheRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Subsystem: "http",
+		Name:      "cache_requests_total",
+		Help:      "Total number of HTTP requests of the ports with cache by proxy, port and cache status.",
+	}, []string{LabelProxy, LabelPort, LabelStatus})
+
+	proxyStatus = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Subsystem: "proxy",
+		Name:      "status",
+		Help:      "Current status of the proxy, 1 for the current status and 0 for the others.",
+	}, []string{LabelProxy, LabelStatus})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		requestsTotal,
+		requestDuration,
+		requestsInFlight,
+		httpBytes,
+		rateLimited,
+		cacheRequests,
+		streamConnections,
+		streamBytes,
+		proxyStatus,
+	)
+}
+
+// AddRoutes function adds the metrics endpoint to the http server.
+func AddRoutes(http *core.HTTPServer) {
+	http.Get("/metrics", Handler())
+}
+
+// Handler function returns the Prometheus metrics handler.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// SetProxyStatus function sets the status gauge of a proxy.
+func SetProxyStatus(proxy string, status model.ProxyStatus) {
+	for s := model.ProxyStatusInitializing; s <= model.ProxyStatusRestarting; s++ {
+		value := 0.0
+		if s == status {
+			value = 1
+		}
+		proxyStatus.WithLabelValues(proxy, s.String()).Set(value)
+	}
+}
+
+// DeleteProxy function removes all metrics of a proxy.
+func DeleteProxy(proxy string) {
+	labels := prometheus.Labels{LabelProxy: proxy}
+
+	requestsTotal.DeletePartialMatch(labels)
+	requestDuration.DeletePartialMatch(labels)
+	requestsInFlight.DeletePartialMatch(labels)
+	httpBytes.DeletePartialMatch(labels)
+	rateLimited.DeletePartialMatch(labels)
+	cacheRequests.DeletePartialMatch(labels)
+	streamConnections.DeletePartialMatch(labels)
+	streamBytes.DeletePartialMatch(labels)
+	proxyStatus.DeletePartialMatch(labels)
+}
+
+// ObserveRateLimited function records a request rejected by a rate limit.
+func ObserveRateLimited(proxy, port string) {
+	rateLimited.WithLabelValues(proxy, port).Inc()
+}
+
+// ObserveCache function records a request of a port with cache, with its
+// cache status.
+func ObserveCache(proxy, port, status string) {
+	cacheRequests.WithLabelValues(proxy, port, status).Inc()
+}
+
+// ObserveStream function records a closed stream connection and the bytes transferred.
+func ObserveStream(proxy, port string, in, out int64) {
+	streamConnections.WithLabelValues(proxy, port).Inc()
+	streamBytes.WithLabelValues(proxy, port, DirectionIn).Add(float64(in))
+	streamBytes.WithLabelValues(proxy, port, DirectionOut).Add(float64(out))
+}
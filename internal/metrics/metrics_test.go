@@ -0,0 +1,116 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func TestMiddleware(t *testing.T) {
+	t.Cleanup(func() { DeleteProxy("web") })
+
+	handler := Middleware("web", "443/https", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.Copy(io.Discard, r.Body)
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = io.WriteString(w, "hello")
+	}))
+
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader("ping")))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	counters := map[string]float64{
+		"POST 2xx":  testutil.ToFloat64(requestsTotal.WithLabelValues("web", "443/https", http.MethodPost, "2xx")),
+		"GET 2xx":   testutil.ToFloat64(requestsTotal.WithLabelValues("web", "443/https", http.MethodGet, "2xx")),
+		"GET 4xx":   testutil.ToFloat64(requestsTotal.WithLabelValues("web", "443/https", http.MethodGet, "4xx")),
+		"in":        testutil.ToFloat64(httpBytes.WithLabelValues("web", "443/https", DirectionIn)),
+		"in flight": testutil.ToFloat64(requestsInFlight.WithLabelValues("web", "443/https")),
+	}
+	want := map[string]float64{"POST 2xx": 1, "GET 2xx": 1, "GET 4xx": 1, "in": 4, "in flight": 0}
+	for name, value := range want {
+		if counters[name] != value {
+			t.Errorf("%s: got %v, want %v", name, counters[name], value)
+		}
+	}
+	// two responses of 5 bytes and the not found message
+	if out := testutil.ToFloat64(httpBytes.WithLabelValues("web", "443/https", DirectionOut)); out <= 10 {
+		t.Errorf("bytes out: got %v", out)
+	}
+}
+
+func TestMethodLabel(t *testing.T) {
+	tests := map[string]string{
+		http.MethodGet:     http.MethodGet,
+		http.MethodOptions: http.MethodOptions,
+		"PROPFIND":         MethodOther,
+		"get":              MethodOther,
+		"X-RANDOM-1234":    MethodOther,
+	}
+
+	for method, want := range tests {
+		if got := methodLabel(method); got != want {
+			t.Errorf("%s: got %s, want %s", method, got, want)
+		}
+	}
+}
+
+func TestSetProxyStatus(t *testing.T) {
+	t.Cleanup(func() { DeleteProxy("web") })
+
+	SetProxyStatus("web", model.ProxyStatusStarting)
+	SetProxyStatus("web", model.ProxyStatusRunning)
+
+	running, starting := model.ProxyStatusRunning, model.ProxyStatusStarting
+	if v := testutil.ToFloat64(proxyStatus.WithLabelValues("web", running.String())); v != 1 {
+		t.Errorf("running: got %v, want 1", v)
+	}
+	if v := testutil.ToFloat64(proxyStatus.WithLabelValues("web", starting.String())); v != 0 {
+		t.Errorf("starting: got %v, want 0", v)
+	}
+}
+
+func TestDeleteProxy(t *testing.T) {
+	ObserveStream("db", "5432/tcp", 10, 20)
+	ObserveRateLimited("db", "5432/tcp")
+	SetProxyStatus("db", model.ProxyStatusRunning)
+
+	DeleteProxy("db")
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(w.Body.String(), `proxy="db"`) {
+		t.Errorf("metrics of the deleted proxy exported:\n%s", w.Body.String())
+	}
+}
+
+func TestHandler(t *testing.T) {
+	t.Cleanup(func() { DeleteProxy("db") })
+
+	ObserveStream("db", "5432/tcp", 10, 20)
+
+	w := httptest.NewRecorder()
+	Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	for _, metric := range []string{
+		`tsdproxy_stream_connections_total{port="5432/tcp",proxy="db"} 1`,
+		`tsdproxy_stream_bytes_total{direction="in",port="5432/tcp",proxy="db"} 10`,
+		`tsdproxy_stream_bytes_total{direction="out",port="5432/tcp",proxy="db"} 20`,
+		"go_goroutines",
+	} {
+		if !strings.Contains(w.Body.String(), metric) {
+			t.Errorf("metric %s not exported", metric)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/core"
+)
+
+// bodyCounter wraps the request body and counts the bytes read.
+type bodyCounter struct {
+	io.ReadCloser
+	bytes int64
+}
+
+// Middleware function records the metrics of the requests proxied by a port.
+func Middleware(proxy, port string, next http.Handler) http.Handler {
+	inFlight := requestsInFlight.WithLabelValues(proxy, port)
+	duration := requestDuration.WithLabelValues(proxy, port)
+	bytesIn := httpBytes.WithLabelValues(proxy, port, DirectionIn)
+	bytesOut := httpBytes.WithLabelValues(proxy, port, DirectionOut)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		inFlight.Inc()
+		defer inFlight.Dec()
+
+		rw := core.NewResponseRecorder(w)
+
+		var body *bodyCounter
+		if r.Body != nil && r.Body != http.NoBody {
+			body = &bodyCounter{ReadCloser: r.Body}
+			r.Body = body
+		}
+
+		start := time.Now()
+		next.ServeHTTP(rw, r)
+
+		duration.Observe(time.Since(start).Seconds())
+		requestsTotal.WithLabelValues(proxy, port, methodLabel(r.Method), statusClass(rw.Status)).Inc()
+		bytesOut.Add(float64(rw.Bytes))
+		if body != nil {
+			bytesIn.Add(float64(body.bytes))
+		}
+	})
+}
+
+// methodLabel function returns the method label of a request. Other methods
+// are counted as MethodOther, so the clients can't add label values.
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	default:
+		return MethodOther
+	}
+}
+
+// statusClass function returns the class of a status code, like 2xx.
+func statusClass(status int) string {
+	return strconv.Itoa(status/100) + "xx" //nolint:mnd
+}
+
+func (b *bodyCounter) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.bytes += int64(n)
+
+	return n, err
+}
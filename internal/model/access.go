@@ -0,0 +1,91 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"path"
+	"slices"
+)
+
+// CapabilityGroups is the Tailscale peer capability that lists the tailnet
+// groups of a user, granted in the tailnet policy file as
+// {"groups": ["group:admins"]}.
+const CapabilityGroups = "github.com/xybydy/tsdproxy/cap/groups"
+
+type (
+	// AccessControl struct stores the identity based access rules of a proxy or port.
+	// A request is denied if it matches any deny rule, or if allow rules are
+	// defined and it doesn't match any of them. Requests without Tailscale
+	// identity, like Funnel clients, are always denied.
+	AccessControl struct {
+		DeniedPage string      `validate:"omitempty,file" yaml:"deniedPage,omitempty"`
+		Allow      AccessRules `validate:"dive" yaml:"allow,omitempty"`
+		Deny       AccessRules `validate:"dive" yaml:"deny,omitempty"`
+	}
+
+	// AccessRules struct stores the identities matched by an access rule.
+	// Users and tags support glob patterns like *@example.com or tag:admin-*.
+	AccessRules struct {
+		Users   []string `yaml:"users,omitempty"`
+		UserIDs []string `yaml:"userIds,omitempty"`
+		Tags    []string `yaml:"tags,omitempty"`
+		Groups  []string `yaml:"groups,omitempty"`
+	}
+)
+
+// IsEnabled method returns true if any access rule is defined.
+func (a *AccessControl) IsEnabled() bool {
+	return !a.Allow.IsEmpty() || !a.Deny.IsEmpty()
+}
+
+// IsAllowed method returns true if the identity is allowed by the access rules.
+// Empty identities aren't allowed, as deny rules can't match them.
+func (a *AccessControl) IsAllowed(who Whois) bool {
+	if who.IsAnonymous() || a.Deny.Match(who) {
+		return false
+	}
+
+	return a.Allow.IsEmpty() || a.Allow.Match(who)
+}
+
+// IsEmpty method returns true if no rules are defined.
+func (r *AccessRules) IsEmpty() bool {
+	return len(r.Users) == 0 && len(r.UserIDs) == 0 && len(r.Tags) == 0 && len(r.Groups) == 0
+}
+
+// Match method returns true if the identity matches any of the rules.
+func (r *AccessRules) Match(who Whois) bool {
+	if who.Username != "" && matchAny(r.Users, who.Username) {
+		return true
+	}
+
+	if who.ID != "" && slices.Contains(r.UserIDs, who.ID) {
+		return true
+	}
+
+	for _, tag := range who.Tags {
+		if matchAny(r.Tags, tag) {
+			return true
+		}
+	}
+
+	for _, group := range who.Groups {
+		if slices.Contains(r.Groups, group) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchAny function returns true if the value matches any of the glob patterns.
+func matchAny(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if ok, err := path.Match(pattern, value); err == nil && ok {
+			return true
+		}
+	}
+
+	return false
+}
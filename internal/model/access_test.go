@@ -0,0 +1,55 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import "testing"
+
+func TestAccessControlIsAllowed(t *testing.T) {
+	alice := Whois{ID: "1", Username: "alice@example.com", Groups: []string{"group:admins"}}
+	bob := Whois{ID: "2", Username: "bob@guests.example.com"}
+	ci := Whois{ID: "3", Username: "tagged-devices", Tags: []string{"tag:ci-runner"}}
+
+	tests := []struct {
+		name    string
+		who     Whois
+		ac      AccessControl
+		allowed bool
+	}{
+		{name: "allow user", who: alice, ac: AccessControl{Allow: AccessRules{Users: []string{"alice@example.com"}}}, allowed: true},
+		{name: "allow pattern", who: alice, ac: AccessControl{Allow: AccessRules{Users: []string{"*@example.com"}}}, allowed: true},
+		{name: "allow not matched", who: bob, ac: AccessControl{Allow: AccessRules{Users: []string{"*@example.com"}}}},
+		{name: "allow user id", who: bob, ac: AccessControl{Allow: AccessRules{UserIDs: []string{"2"}}}, allowed: true},
+		{name: "allow group", who: alice, ac: AccessControl{Allow: AccessRules{Groups: []string{"group:admins"}}}, allowed: true},
+		{name: "allow tag pattern", who: ci, ac: AccessControl{Allow: AccessRules{Tags: []string{"tag:ci-*"}}}, allowed: true},
+		{name: "deny tag", who: ci, ac: AccessControl{Deny: AccessRules{Tags: []string{"tag:ci-*"}}}},
+		{name: "deny other", who: alice, ac: AccessControl{Deny: AccessRules{Tags: []string{"tag:ci-*"}}}, allowed: true},
+		{
+			name: "deny before allow",
+			who:  alice,
+			ac: AccessControl{
+				Allow: AccessRules{Users: []string{"*@example.com"}},
+				Deny:  AccessRules{Users: []string{"alice@example.com"}},
+			},
+		},
+		{name: "anonymous with deny rules", who: Whois{}, ac: AccessControl{Deny: AccessRules{Tags: []string{"tag:guest"}}}},
+		{name: "anonymous with allow rules", who: Whois{}, ac: AccessControl{Allow: AccessRules{Users: []string{"*"}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ac.IsAllowed(tt.who); got != tt.allowed {
+				t.Errorf("got %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestAccessControlIsEnabled(t *testing.T) {
+	if (&AccessControl{DeniedPage: "denied.html"}).IsEnabled() {
+		t.Error("enabled without rules")
+	}
+	if !(&AccessControl{Deny: AccessRules{Groups: []string{"group:guests"}}}).IsEnabled() {
+		t.Error("disabled with deny rules")
+	}
+}
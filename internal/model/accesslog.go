@@ -0,0 +1,45 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+// Access log formats
+const (
+	AccessLogFormatCommon   = "common"
+	AccessLogFormatCombined = "combined"
+	AccessLogFormatJSON     = "json"
+)
+
+// Access log outputs
+const (
+	AccessLogOutputLog    = "log"
+	AccessLogOutputFile   = "file"
+	AccessLogOutputSyslog = "syslog"
+)
+
+// AccessLog struct stores the access log of the HTTP requests of a proxy.
+// Requests are written to the TSDProxy log, to a file or to syslog, in the
+// Common Log Format, Combined Log Format or as JSON lines. Empty values use
+// the global defaults.
+type AccessLog struct {
+	Format  string `validate:"omitempty,oneof=common combined json" yaml:"format,omitempty"`
+	Output  string `validate:"omitempty,oneof=log file syslog" yaml:"output,omitempty"`
+	File    string `yaml:"file,omitempty"`
+	Enabled bool   `default:"true" validate:"boolean" yaml:"enabled"`
+}
+
+// WithDefaults method returns the access log with the values of defaults set
+// on the fields that are not configured.
+func (a AccessLog) WithDefaults(defaults AccessLog) AccessLog {
+	if a.Format == "" {
+		a.Format = defaults.Format
+	}
+	if a.Output == "" {
+		a.Output = defaults.Output
+	}
+	if a.File == "" {
+		a.File = defaults.File
+	}
+
+	return a
+}
@@ -0,0 +1,21 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+// cache defaults, in megabytes
+const (
+	DefaultCacheMaxSize      = 64
+	DefaultCacheMaxEntrySize = 8
+)
+
+// Cache struct stores the HTTP cache of the responses of a port. Responses are
+// cached following their Cache-Control, Expires and ETag headers, in memory or
+// in files of Dir if set. MaxSize and MaxEntrySize are in megabytes, and zero
+// values use the defaults.
+type Cache struct {
+	Dir          string `yaml:"dir,omitempty"`
+	MaxSize      int    `validate:"omitempty,min=0" yaml:"maxSize,omitempty"`
+	MaxEntrySize int    `validate:"omitempty,min=0" yaml:"maxEntrySize,omitempty"`
+	Enabled      bool   `validate:"boolean" yaml:"enabled,omitempty"`
+}
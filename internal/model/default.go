@@ -3,12 +3,15 @@
 
 package model
 
+import "time"
+
 const (
 	// Default values to proxyconfig
 	//
 	DefaultProxyAccessLog = true
 	DefaultProxyProvider  = ""
 	DefaultTLSValidate    = true
+	DefaultLoadBalance    = LoadBalanceRoundRobin
 
 	// tailscale defaults
 	DefaultTailscaleEphemeral    = false
@@ -17,6 +20,18 @@ const (
 	DefaultTailscaleFunnel       = false
 	DefaultTailscaleControlURL   = ""
 
+	// health check defaults
+	DefaultHealthCheckType               = HealthCheckHTTP
+	DefaultHealthCheckPath               = "/"
+	DefaultHealthCheckInterval           = 10 * time.Second
+	DefaultHealthCheckTimeout            = 2 * time.Second
+	DefaultHealthCheckHealthyThreshold   = 2
+	DefaultHealthCheckUnhealthyThreshold = 3
+
+	// stream defaults
+	DefaultTCPIdleTimeout = 5 * time.Minute
+	DefaultUDPIdleTimeout = 1 * time.Minute
+
 	// Dashboard defauts
 	DefaultDashboardVisible = true
 	DefaultDashboardIcon    = "tsdproxy"
@@ -0,0 +1,115 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+// funnel defaults
+const (
+	DefaultFunnelShareLinkTTL = 24 * time.Hour
+	DefaultFunnelSessionTTL   = 24 * time.Hour
+)
+
+var (
+	ErrInvalidFunnelCIDR      = errors.New("invalid funnel CIDR")
+	ErrInvalidFunnelBasicAuth = errors.New("invalid funnel basic auth, expected user:password")
+)
+
+// FunnelProtection struct stores the protections of the requests received from
+// the internet on Funnel ports. Requests from the tailnet aren't checked.
+//
+// Clients must pass one of the configured authentications: basic auth with
+// user:password (the password can be a bcrypt hash), a bearer token, or a
+// session opened with a one-time share link. Deny and Allow are CIDR lists of
+// the client addresses, and RateLimit and FailureLimit limit the requests and
+// the failed authentications of each client IP.
+type FunnelProtection struct {
+	BasicAuth    []string      `yaml:"basicAuth,omitempty"`
+	BearerTokens []string      `yaml:"bearerTokens,omitempty"`
+	Allow        []string      `yaml:"allow,omitempty"`
+	Deny         []string      `yaml:"deny,omitempty"`
+	RateLimit    RateLimit     `yaml:"rateLimit,omitempty"`
+	FailureLimit RateLimit     `yaml:"failureLimit,omitempty"`
+	ShareLinkTTL time.Duration `yaml:"shareLinkTTL,omitempty"`
+	SessionTTL   time.Duration `yaml:"sessionTTL,omitempty"`
+	ShareLinks   bool          `validate:"boolean" yaml:"shareLinks,omitempty"`
+}
+
+// IsEnabled method returns true if any protection is configured.
+func (f *FunnelProtection) IsEnabled() bool {
+	return f.HasAuth() || len(f.Allow) > 0 || len(f.Deny) > 0 ||
+		f.RateLimit.IsEnabled() || f.FailureLimit.IsEnabled()
+}
+
+// HasAuth method returns true if the clients must authenticate.
+func (f *FunnelProtection) HasAuth() bool {
+	return len(f.BasicAuth) > 0 || len(f.BearerTokens) > 0 || f.ShareLinks
+}
+
+// Validate method returns the errors of the CIDRs and basic auth entries.
+// Funnel ports with invalid CIDRs deny all the internet clients.
+func (f *FunnelProtection) Validate() error {
+	var errs error
+
+	for _, cidr := range f.Allow {
+		if _, err := ParsePrefix(cidr); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w: allow %s", ErrInvalidFunnelCIDR, cidr))
+		}
+	}
+	for _, cidr := range f.Deny {
+		if _, err := ParsePrefix(cidr); err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w: deny %s", ErrInvalidFunnelCIDR, cidr))
+		}
+	}
+
+	for _, entry := range f.BasicAuth {
+		if user, password, ok := strings.Cut(entry, ":"); !ok || user == "" || password == "" {
+			errs = errors.Join(errs, fmt.Errorf("%w: user %s", ErrInvalidFunnelBasicAuth, user))
+		}
+	}
+
+	return errs
+}
+
+// FunnelClientFromContext function returns the address of the internet client
+// of a Funnel connection, false if the connection is from the tailnet.
+func FunnelClientFromContext(ctx context.Context) (string, bool) {
+	addr, ok := ctx.Value(ContextKeyFunnelClient).(string)
+
+	return addr, ok
+}
+
+// FunnelClientNewContext function returns a context with the address of the
+// internet client of a Funnel connection.
+func FunnelClientNewContext(ctx context.Context, addr string) context.Context {
+	return context.WithValue(ctx, ContextKeyFunnelClient, addr)
+}
+
+// ParsePrefix function parses a CIDR, or a single IP address as a prefix of
+// its full length.
+func ParsePrefix(s string) (netip.Prefix, error) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+
+		return prefix.Masked(), nil
+	}
+
+	ip, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	return netip.PrefixFrom(ip.Unmap(), ip.Unmap().BitLen()), nil
+}
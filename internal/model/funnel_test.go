@@ -0,0 +1,35 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFunnelProtectionValidate(t *testing.T) {
+	valid := FunnelProtection{
+		Allow:     []string{"198.51.100.0/24", "2001:db8::1"},
+		Deny:      []string{"198.51.100.7"},
+		BasicAuth: []string{"alice:secret"},
+	}
+	if err := valid.Validate(); err != nil {
+		t.Errorf("valid protection: got %v", err)
+	}
+
+	tests := map[string]struct {
+		want       error
+		protection FunnelProtection
+	}{
+		"allow":      {protection: FunnelProtection{Allow: []string{"198.51.100.0/33"}}, want: ErrInvalidFunnelCIDR},
+		"deny":       {protection: FunnelProtection{Deny: []string{"example.com"}}, want: ErrInvalidFunnelCIDR},
+		"basic auth": {protection: FunnelProtection{BasicAuth: []string{"alice"}}, want: ErrInvalidFunnelBasicAuth},
+	}
+
+	for name, tt := range tests {
+		if err := tt.protection.Validate(); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", name, err, tt.want)
+		}
+	}
+}
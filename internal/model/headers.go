@@ -0,0 +1,27 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+type (
+	// Headers struct stores the header rules applied to the requests sent to
+	// the targets and to the responses sent to the clients.
+	Headers struct {
+		Request  HeaderRules `validate:"dive" yaml:"request,omitempty"`
+		Response HeaderRules `validate:"dive" yaml:"response,omitempty"`
+	}
+
+	// HeaderRules struct stores the headers to remove, set and add, in this order.
+	// Values of set and add are templates with the Whois fields of the user,
+	// like "{{ .Username }}".
+	HeaderRules struct {
+		Set    map[string]string `yaml:"set,omitempty"`
+		Add    map[string]string `yaml:"add,omitempty"`
+		Remove []string          `yaml:"remove,omitempty"`
+	}
+)
+
+// IsEmpty method returns true if no rules are defined.
+func (r *HeaderRules) IsEmpty() bool {
+	return len(r.Set) == 0 && len(r.Add) == 0 && len(r.Remove) == 0
+}
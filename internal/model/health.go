@@ -0,0 +1,74 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import "time"
+
+type (
+	// HealthCheck struct stores the active health check configuration of a port.
+	HealthCheck struct {
+		Type               string        `validate:"omitempty,oneof=http tcp" yaml:"type"`
+		Path               string        `yaml:"path"`
+		Interval           time.Duration `yaml:"interval"`
+		Timeout            time.Duration `yaml:"timeout"`
+		ExpectedStatus     int           `validate:"omitempty,min=100,max=599" yaml:"expectedStatus"`
+		HealthyThreshold   int           `validate:"omitempty,min=1" yaml:"healthyThreshold"`
+		UnhealthyThreshold int           `validate:"omitempty,min=1" yaml:"unhealthyThreshold"`
+		Enabled            bool          `validate:"boolean" yaml:"enabled"`
+	}
+
+	HealthStatus int
+
+	// TargetHealth struct stores the health of a port target.
+	TargetHealth struct {
+		URL    string
+		Status HealthStatus
+	}
+)
+
+const (
+	HealthCheckHTTP = "http"
+	HealthCheckTCP  = "tcp"
+)
+
+const (
+	HealthStatusUnknown HealthStatus = iota
+	HealthStatusHealthy
+	HealthStatusUnhealthy
+)
+
+var healthStatusStrings = []string{
+	"Unknown",
+	"Healthy",
+	"Unhealthy",
+}
+
+func (s *HealthStatus) String() string {
+	return healthStatusStrings[int(*s)]
+}
+
+// WithDefaults method returns the health check with the default values
+// set on the fields that are not configured.
+func (h HealthCheck) WithDefaults() HealthCheck {
+	if h.Type == "" {
+		h.Type = DefaultHealthCheckType
+	}
+	if h.Path == "" {
+		h.Path = DefaultHealthCheckPath
+	}
+	if h.Interval <= 0 {
+		h.Interval = DefaultHealthCheckInterval
+	}
+	if h.Timeout <= 0 {
+		h.Timeout = DefaultHealthCheckTimeout
+	}
+	if h.HealthyThreshold <= 0 {
+		h.HealthyThreshold = DefaultHealthCheckHealthyThreshold
+	}
+	if h.UnhealthyThreshold <= 0 {
+		h.UnhealthyThreshold = DefaultHealthCheckUnhealthyThreshold
+	}
+
+	return h
+}
@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"time"
 )
 
 type (
@@ -16,14 +17,24 @@ type (
 		name          string `validate:"string" yaml:"name"`
 		ProxyProtocol string `validate:"string" yaml:"proxyProtocol"`
 		targets       []*url.URL
+		LoadBalance   string        `validate:"omitempty,oneof=roundrobin leastconn random" yaml:"loadBalance"`
+		HealthCheck   HealthCheck   `validate:"dive" yaml:"healthCheck"`
+		AccessControl AccessControl `validate:"dive" yaml:"accessControl"`
+		Routes        []Route       `validate:"dive" yaml:"routes"`
+		Headers       Headers       `validate:"dive" yaml:"headers"`
+		Cache         Cache         `validate:"dive" yaml:"cache"`
+		Tailscale     TailscalePort `validate:"dive" yaml:"tailscale"`
+		Transport     Transport     `validate:"dive" yaml:"transport"`
+		RateLimit     RateLimit     `validate:"dive" yaml:"rateLimit"`
+		IdleTimeout   time.Duration `yaml:"idleTimeout"`
 		ProxyPort     int           `validate:"hostname_port" yaml:"proxyPort"`
 		TLSValidate   bool          `validate:"boolean" yaml:"tlsValidate"`
 		IsRedirect    bool          `validate:"boolean" yaml:"isRedirect"`
-		Tailscale     TailscalePort `validate:"dive" yaml:"tailscale"`
 	}
 
 	TailscalePort struct {
-		Funnel bool `validate:"boolean" yaml:"funnel"`
+		Protection FunnelProtection `validate:"dive" yaml:"funnelProtection,omitempty"`
+		Funnel     bool             `validate:"boolean" yaml:"funnel"`
 	}
 )
 
@@ -33,6 +44,27 @@ const (
 	protocolSeparator = "/"
 )
 
+// Proxy protocols that forward raw connections instead of HTTP requests.
+const (
+	ProtocolTCP = "tcp"
+	ProtocolUDP = "udp"
+)
+
+// Target protocols proxied with HTTP/2. grpc and h2c targets use HTTP/2 without
+// TLS (prior knowledge), https+h2 targets use HTTP/2 over TLS.
+const (
+	ProtocolGRPC    = "grpc"
+	ProtocolH2C     = "h2c"
+	ProtocolHTTPSH2 = "https+h2"
+)
+
+// Load balancing strategies used to distribute requests across the targets of a port.
+const (
+	LoadBalanceRoundRobin = "roundrobin"
+	LoadBalanceLeastConn  = "leastconn"
+	LoadBalanceRandom     = "random"
+)
+
 var (
 	ErrInvalidPortFormat   = errors.New("invalid format, missing '" + protocolSeparator + "' or '" + redirectSeparator + "'")
 	ErrInvalidProxyConfig  = errors.New("invalid proxy configuration")
@@ -114,6 +146,7 @@ func defaultPortConfig(name string) PortConfig {
 		ProxyProtocol: "https",
 		ProxyPort:     443, //nolint:mnd
 		IsRedirect:    false,
+		LoadBalance:   DefaultLoadBalance,
 	}
 }
 
@@ -183,6 +216,11 @@ func parseRedirectTarget(segment string, config *PortConfig) error {
 	return nil
 }
 
+// IsStream method returns true if the port forwards raw TCP or UDP connections.
+func (p *PortConfig) IsStream() bool {
+	return p.ProxyProtocol == ProtocolTCP || p.ProxyProtocol == ProtocolUDP
+}
+
 func (p *PortConfig) GetTargets() []*url.URL {
 	return p.targets
 }
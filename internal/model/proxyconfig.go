@@ -17,9 +17,11 @@ type (
 		TargetID       string
 		ProxyProvider  string
 		Hostname       string
-		Dashboard      Dashboard `validate:"dive"`
-		Tailscale      Tailscale `validate:"dive"`
-		ProxyAccessLog bool      `default:"true" validate:"boolean"`
+		Dashboard      Dashboard     `validate:"dive"`
+		Tailscale      Tailscale     `validate:"dive"`
+		AccessControl  AccessControl `validate:"dive"`
+		AccessLog      AccessLog     `validate:"dive"`
+		RateLimit      RateLimit     `validate:"dive"`
 	}
 
 	// Tailscale struct stores the configuration for tailscale ProxyProvider
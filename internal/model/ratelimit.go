@@ -0,0 +1,71 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultRateLimitPeriod is the period of rate limits without period.
+const DefaultRateLimitPeriod = time.Second
+
+// RateLimit struct stores the token bucket limit of the requests of each
+// Tailscale user, or client IP on Funnel requests. Requests are refilled every
+// Period, and Burst requests are allowed at once, defaulting to Requests.
+type RateLimit struct {
+	Requests int           `validate:"omitempty,min=0" yaml:"requests,omitempty"`
+	Period   time.Duration `yaml:"period,omitempty"`
+	Burst    int           `validate:"omitempty,min=0" yaml:"burst,omitempty"`
+}
+
+var ErrInvalidRateLimit = errors.New("invalid rate limit, expected <requests>/<period>")
+
+// ParseRateLimit function parses a rate limit in the format <requests>/<period>,
+// like 100/1m. The period defaults to one second.
+func ParseRateLimit(s string) (RateLimit, error) {
+	requests, period, hasPeriod := strings.Cut(strings.TrimSpace(s), "/")
+
+	n, err := strconv.Atoi(requests)
+	if err != nil || n < 0 {
+		return RateLimit{}, fmt.Errorf("%w: %s", ErrInvalidRateLimit, s)
+	}
+
+	limit := RateLimit{Requests: n}
+	if hasPeriod {
+		limit.Period, err = time.ParseDuration(period)
+		if err != nil || limit.Period <= 0 {
+			return RateLimit{}, fmt.Errorf("%w: %s", ErrInvalidRateLimit, s)
+		}
+	}
+
+	return limit, nil
+}
+
+// IsEnabled method returns true if the requests are limited.
+func (r RateLimit) IsEnabled() bool {
+	return r.Requests > 0
+}
+
+// PerSecond method returns the requests allowed per second.
+func (r RateLimit) PerSecond() float64 {
+	period := r.Period
+	if period <= 0 {
+		period = DefaultRateLimitPeriod
+	}
+
+	return float64(r.Requests) / period.Seconds()
+}
+
+// GetBurst method returns the requests allowed at once.
+func (r RateLimit) GetBurst() int {
+	if r.Burst > 0 {
+		return r.Burst
+	}
+
+	return r.Requests
+}
@@ -0,0 +1,93 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+type (
+	// Route struct stores a path route of a port. Requests under Path are
+	// proxied to the route targets instead of the port targets.
+	Route struct {
+		Path        string `validate:"required,startswith=/" yaml:"path"`
+		Rewrite     string `validate:"omitempty,startswith=/" yaml:"rewrite,omitempty"`
+		targets     []*url.URL
+		StripPrefix bool `validate:"boolean" yaml:"stripPrefix,omitempty"`
+	}
+)
+
+const targetsSeparator = ","
+
+var (
+	ErrInvalidRoutePath   = errors.New("invalid route path, must start with '/'")
+	ErrInvalidRouteTarget = errors.New("invalid route target")
+)
+
+// NewRouteLabel parses a route configuration string and returns a Route struct.
+//
+// The input string `s` must follow the format:
+// "<path> -> <target URL>[,<target URL>...]"
+//   - Example: "/api -> http://api:8080"
+//   - Example: "/api -> http://api1:8080,http://api2:8080"
+func NewRouteLabel(s string) (Route, error) {
+	var route Route
+
+	parts := strings.Split(s, redirectSeparator)
+	if len(parts) != 2 { //nolint:mnd
+		return route, ErrInvalidProxyConfig
+	}
+
+	route.Path = strings.TrimSpace(parts[0])
+	if !strings.HasPrefix(route.Path, "/") {
+		return route, ErrInvalidRoutePath
+	}
+
+	for _, target := range strings.Split(parts[1], targetsSeparator) {
+		targetURL, err := url.Parse(strings.TrimSpace(target))
+		if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
+			return route, fmt.Errorf("%w: %v", ErrInvalidRouteTarget, target)
+		}
+
+		route.AddTarget(targetURL)
+	}
+
+	return route, nil
+}
+
+func (r *Route) GetTargets() []*url.URL {
+	return r.targets
+}
+
+func (r *Route) AddTarget(target *url.URL) {
+	r.targets = append(r.targets, target)
+}
+
+// Match method returns true if the request path is under the route path.
+// "/api" matches "/api" and "/api/users", but not "/apis".
+func (r *Route) Match(path string) bool {
+	prefix := strings.TrimSuffix(r.Path, "/")
+
+	return prefix == "" || path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// RewritePath method returns the path sent to the targets. The route path is
+// removed with StripPrefix, or replaced by Rewrite.
+func (r *Route) RewritePath(path string) string {
+	if !r.StripPrefix && r.Rewrite == "" {
+		return path
+	}
+
+	rest := strings.TrimPrefix(path, strings.TrimSuffix(r.Path, "/"))
+	newPath := strings.TrimSuffix(r.Rewrite, "/") + rest
+
+	if !strings.HasPrefix(newPath, "/") {
+		newPath = "/" + newPath
+	}
+
+	return newPath
+}
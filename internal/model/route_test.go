@@ -0,0 +1,76 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNewRouteLabel(t *testing.T) {
+	route, err := NewRouteLabel("/api -> http://api1:8080, http://api2:8080")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if route.Path != "/api" {
+		t.Errorf("path: got %q", route.Path)
+	}
+	if targets := route.GetTargets(); len(targets) != 2 || targets[0].Host != "api1:8080" || targets[1].Host != "api2:8080" {
+		t.Errorf("targets: got %v", targets)
+	}
+
+	for label, want := range map[string]error{
+		"/api":                   ErrInvalidProxyConfig,
+		"api -> http://api:8080": ErrInvalidRoutePath,
+		"/api -> api:8080":       ErrInvalidRouteTarget,
+		"/api -> http://api,":    ErrInvalidRouteTarget,
+	} {
+		if _, err := NewRouteLabel(label); !errors.Is(err, want) {
+			t.Errorf("%q: got %v, want %v", label, err, want)
+		}
+	}
+}
+
+func TestRouteMatch(t *testing.T) {
+	tests := []struct {
+		route string
+		path  string
+		match bool
+	}{
+		{route: "/api", path: "/api", match: true},
+		{route: "/api", path: "/api/users", match: true},
+		{route: "/api/", path: "/api/users", match: true},
+		{route: "/api", path: "/apis"},
+		{route: "/api", path: "/"},
+		{route: "/", path: "/anything", match: true},
+	}
+
+	for _, tt := range tests {
+		r := Route{Path: tt.route}
+		if got := r.Match(tt.path); got != tt.match {
+			t.Errorf("%s matches %s: got %v", tt.route, tt.path, got)
+		}
+	}
+}
+
+func TestRouteRewritePath(t *testing.T) {
+	tests := []struct {
+		path  string
+		want  string
+		route Route
+	}{
+		{route: Route{Path: "/api"}, path: "/api/users", want: "/api/users"},
+		{route: Route{Path: "/api", StripPrefix: true}, path: "/api/users", want: "/users"},
+		{route: Route{Path: "/api", StripPrefix: true}, path: "/api", want: "/"},
+		{route: Route{Path: "/api/", StripPrefix: true}, path: "/api/users", want: "/users"},
+		{route: Route{Path: "/api", Rewrite: "/v2"}, path: "/api/users", want: "/v2/users"},
+		{route: Route{Path: "/api", Rewrite: "/v2/"}, path: "/api", want: "/v2"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.route.RewritePath(tt.path); got != tt.want {
+			t.Errorf("%+v %s: got %s, want %s", tt.route, tt.path, got, tt.want)
+		}
+	}
+}
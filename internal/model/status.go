@@ -9,7 +9,11 @@ type (
 		ID      string
 		Port    string
 		AuthURL string
+		Target  string
+		Error   string
+		Attempt int
 		Status  ProxyStatus
+		Health  HealthStatus
 	}
 )
 
@@ -21,6 +25,11 @@ const (
 	ProxyStatusStopping
 	ProxyStatusStopped
 	ProxyStatusError
+	ProxyStatusDegraded
+	ProxyStatusRestarting
+	// ProxyStatusMaintenance is only used in the events of the maintenance
+	// mode, the proxy keeps its status.
+	ProxyStatusMaintenance
 )
 
 var proxyStatusStrings = []string{
@@ -31,6 +40,9 @@ var proxyStatusStrings = []string{
 	"Stopping",
 	"Stopped",
 	"Error",
+	"Degraded",
+	"Restarting",
+	"Maintenance",
 }
 
 func (s *ProxyStatus) String() string {
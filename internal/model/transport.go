@@ -0,0 +1,56 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package model
+
+import "time"
+
+// Transport struct stores the timeouts and connection settings of a HTTP port.
+// Dial, keep-alive, response header and idle connection settings apply to the
+// connections to the targets, read and write timeouts to the client requests.
+// Zero values use the global defaults and negative durations disable the timeout.
+type Transport struct {
+	DialTimeout           time.Duration `yaml:"dialTimeout,omitempty"`
+	KeepAlive             time.Duration `yaml:"keepAlive,omitempty"`
+	ResponseHeaderTimeout time.Duration `yaml:"responseHeaderTimeout,omitempty"`
+	IdleConnTimeout       time.Duration `yaml:"idleConnTimeout,omitempty"`
+	ReadHeaderTimeout     time.Duration `yaml:"readHeaderTimeout,omitempty"`
+	ReadTimeout           time.Duration `yaml:"readTimeout,omitempty"`
+	WriteTimeout          time.Duration `yaml:"writeTimeout,omitempty"`
+	MaxIdleConns          int           `validate:"omitempty,min=0" yaml:"maxIdleConns,omitempty"`
+	MaxIdleConnsPerHost   int           `validate:"omitempty,min=0" yaml:"maxIdleConnsPerHost,omitempty"`
+}
+
+// WithDefaults method returns the transport with the values of defaults set on
+// the fields that are not configured.
+func (t Transport) WithDefaults(defaults Transport) Transport {
+	if t.DialTimeout == 0 {
+		t.DialTimeout = defaults.DialTimeout
+	}
+	if t.KeepAlive == 0 {
+		t.KeepAlive = defaults.KeepAlive
+	}
+	if t.ResponseHeaderTimeout == 0 {
+		t.ResponseHeaderTimeout = defaults.ResponseHeaderTimeout
+	}
+	if t.IdleConnTimeout == 0 {
+		t.IdleConnTimeout = defaults.IdleConnTimeout
+	}
+	if t.ReadHeaderTimeout == 0 {
+		t.ReadHeaderTimeout = defaults.ReadHeaderTimeout
+	}
+	if t.ReadTimeout == 0 {
+		t.ReadTimeout = defaults.ReadTimeout
+	}
+	if t.WriteTimeout == 0 {
+		t.WriteTimeout = defaults.WriteTimeout
+	}
+	if t.MaxIdleConns <= 0 {
+		t.MaxIdleConns = defaults.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost <= 0 {
+		t.MaxIdleConnsPerHost = defaults.MaxIdleConnsPerHost
+	}
+
+	return t
+}
@@ -11,6 +11,8 @@ type (
 		DisplayName   string
 		Username      string
 		ProfilePicURL string
+		Tags          []string
+		Groups        []string
 	}
 )
 
@@ -30,6 +32,20 @@ func (w *Whois) GetProfilePicURL() string {
 	return w.ProfilePicURL
 }
 
+func (w *Whois) GetTags() []string {
+	return w.Tags
+}
+
+func (w *Whois) GetGroups() []string {
+	return w.Groups
+}
+
+// IsAnonymous method returns true if the request has no Tailscale identity,
+// like the requests of Funnel clients.
+func (w *Whois) IsAnonymous() bool {
+	return w.ID == "" && w.Username == "" && len(w.Tags) == 0
+}
+
 func WhoisFromContext(ctx context.Context) (Whois, bool) {
 	who, ok := ctx.Value(ContextKeyWhois).(Whois)
 
@@ -0,0 +1,117 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"net/http"
+	"os"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/ui/pages"
+
+	"github.com/rs/zerolog"
+)
+
+// accessControl struct denies the requests of identities not allowed by
+// the proxy and port access rules. Funnel clients have no identity, they are
+// only allowed if funnelAuth is set, when the Funnel gate authenticates them.
+type accessControl struct {
+	log        zerolog.Logger
+	proxyName  string
+	deniedPage []byte
+	rules      []model.AccessControl
+	funnelAuth bool
+}
+
+// newAccessControl function returns the access control of a port, or nil if
+// neither the proxy nor the port have access rules.
+func newAccessControl(log zerolog.Logger, proxyName string, funnelAuth bool, rules ...model.AccessControl) *accessControl {
+	ac := &accessControl{
+		log:        log,
+		proxyName:  proxyName,
+		funnelAuth: funnelAuth,
+	}
+
+	// the port denied page overrides the proxy denied page
+	var deniedPage string
+	for _, r := range rules {
+		if !r.IsEnabled() {
+			continue
+		}
+		ac.rules = append(ac.rules, r)
+		if r.DeniedPage != "" {
+			deniedPage = r.DeniedPage
+		}
+	}
+
+	if len(ac.rules) == 0 {
+		return nil
+	}
+
+	if deniedPage != "" {
+		page, err := os.ReadFile(deniedPage)
+		if err != nil {
+			log.Error().Err(err).Str("file", deniedPage).Msg("error reading denied page, using default")
+		}
+		ac.deniedPage = page
+	}
+
+	return ac
+}
+
+// middleware method checks the identity set by ProviderUserMiddleware against all rules.
+func (ac *accessControl) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		who, _ := model.WhoisFromContext(r.Context())
+
+		if _, funnel := model.FunnelClientFromContext(r.Context()); funnel && ac.funnelAuth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !ac.allowed(who) {
+			ac.deny(w, r, who)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowed method returns true if the identity is allowed by all rules.
+func (ac *accessControl) allowed(who model.Whois) bool {
+	for _, rule := range ac.rules {
+		if !rule.IsAllowed(who) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// deny method writes the denied page.
+func (ac *accessControl) deny(w http.ResponseWriter, r *http.Request, who model.Whois) {
+	ac.log.Warn().
+		Str("user", who.Username).
+		Strs("tags", who.Tags).
+		Str("client", r.RemoteAddr).
+		Str("url", r.URL.String()).
+		Msg("access denied")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(http.StatusForbidden)
+
+	if len(ac.deniedPage) > 0 {
+		_, _ = w.Write(ac.deniedPage)
+		return
+	}
+
+	err := pages.AccessDenied(pages.AccessDeniedData{
+		Proxy:    ac.proxyName,
+		Username: who.Username,
+	}).Render(r.Context(), w)
+	if err != nil {
+		ac.log.Error().Err(err).Msg("error rendering denied page")
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// allowAlice is a proxy that only allows alice.
+var allowAlice = &model.Config{
+	Hostname:      "admin",
+	AccessControl: model.AccessControl{Allow: model.AccessRules{Users: []string{alice.Username}}},
+}
+
+func TestAccessControlMiddleware(t *testing.T) {
+	denyGuests := model.AccessControl{Deny: model.AccessRules{Tags: []string{"tag:guest"}}}
+
+	tests := []struct {
+		name       string
+		who        model.Whois
+		status     int
+		funnel     bool
+		funnelAuth bool
+	}{
+		{name: "allowed user", who: alice, status: http.StatusOK},
+		{name: "denied tag", who: model.Whois{ID: "4", Tags: []string{"tag:guest"}}, status: http.StatusForbidden},
+		{name: "anonymous", status: http.StatusForbidden},
+		{name: "funnel client", funnel: true, status: http.StatusForbidden},
+		{name: "authenticated funnel client", funnel: true, funnelAuth: true, status: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ac := newAccessControl(zerolog.Nop(), "test", tt.funnelAuth, denyGuests)
+			handler := ac.middleware(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			ctx := model.WhoisNewContext(r.Context(), tt.who)
+			if tt.funnel {
+				ctx = model.FunnelClientNewContext(ctx, "198.51.100.7:40000")
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r.WithContext(ctx))
+
+			if w.Code != tt.status {
+				t.Errorf("status: got %d, want %d", w.Code, tt.status)
+			}
+		})
+	}
+}
+
+func TestAccessControlDisabled(t *testing.T) {
+	if newAccessControl(zerolog.Nop(), "test", false, model.AccessControl{}, model.AccessControl{}) != nil {
+		t.Error("access control without rules")
+	}
+}
+
+func TestRedirectAccessControl(t *testing.T) {
+	pconfig, err := model.NewPortLongLabel("443/https->https://example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for who, status := range map[*model.Whois]int{&alice: http.StatusMovedPermanently, &bob: http.StatusForbidden} {
+		p := newPortRedirect(context.Background(), pconfig, zerolog.Nop(), allowAlice, testWhois(*who))
+		w := httptest.NewRecorder()
+		p.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != status {
+			t.Errorf("%s: got %d, want %d", who.Username, w.Code, status)
+		}
+	}
+}
+
+func TestStreamAccessControl(t *testing.T) {
+	loadTestConfig(t, "")
+
+	pconfig := newTestPort(t, "5432/tcp:5432/tcp", startEchoTarget(t))
+
+	for who, allowed := range map[*model.Whois]bool{&alice: true, &bob: false} {
+		whois := func(context.Context, net.Conn) model.Whois { return *who }
+		p := newPortStream(context.Background(), pconfig, zerolog.Nop(), allowAlice, whois, func(*backend) {})
+
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatal(err)
+		}
+		go func() { _ = p.startWithListener(l) }()
+
+		if got := streamEcho(t, l.Addr().String()); got != allowed {
+			t.Errorf("%s: got allowed %v, want %v", who.Username, got, allowed)
+		}
+		l.Close()
+	}
+}
+
+// streamEcho function returns true if the echo target answers through the
+// stream port.
+func streamEcho(t *testing.T, addr string) bool {
+	t.Helper()
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := conn.Write([]byte("ping")); err != nil {
+		return false
+	}
+
+	buf := make([]byte, 4)
+	_, err = io.ReadFull(conn, buf)
+
+	return err == nil && string(buf) == "ping"
+}
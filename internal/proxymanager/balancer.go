@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+
+	"github.com/xybydy/tsdproxy/internal/accesslog"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+const contextKeyBackend model.ContextKey = "contextkey.backend"
+
+type (
+	// backend is a single target of a port, its health and the number of requests it is serving.
+	backend struct {
+		url    *url.URL
+		active atomic.Int64
+		health atomic.Int32
+	}
+
+	// balancer distributes the requests of a port across its backends.
+	balancer struct {
+		strategy string
+		backends []*backend
+		next     atomic.Uint64
+	}
+)
+
+// newBalancer function creates a balancer for the targets using the strategy.
+// Unknown or empty strategies fallback to round robin.
+func newBalancer(strategy string, targets []*url.URL) *balancer {
+	b := &balancer{
+		strategy: strategy,
+		backends: make([]*backend, 0, len(targets)),
+	}
+
+	for _, target := range targets {
+		b.backends = append(b.backends, &backend{url: target})
+	}
+
+	return b
+}
+
+// pick method returns the backend that should handle the next request.
+// Unhealthy backends are skipped, it returns nil if there are no backends available.
+func (b *balancer) pick() *backend {
+	backends := b.available()
+	if len(backends) == 0 {
+		return nil
+	}
+
+	switch b.strategy {
+	case model.LoadBalanceRandom:
+		return backends[rand.IntN(len(backends))] //nolint:gosec
+
+	case model.LoadBalanceLeastConn:
+		// start on a rotating offset so ties are spread across backends
+		offset := int(b.next.Add(1) % uint64(len(backends))) //nolint:gosec
+		selected := backends[offset]
+		for i := 1; i < len(backends); i++ {
+			candidate := backends[(offset+i)%len(backends)]
+			if candidate.active.Load() < selected.active.Load() {
+				selected = candidate
+			}
+		}
+		return selected
+
+	default:
+		return backends[(b.next.Add(1)-1)%uint64(len(backends))]
+	}
+}
+
+// available method returns the backends that are not unhealthy.
+func (b *balancer) available() []*backend {
+	backends := make([]*backend, 0, len(b.backends))
+	for _, target := range b.backends {
+		if target.getHealth() != model.HealthStatusUnhealthy {
+			backends = append(backends, target)
+		}
+	}
+
+	return backends
+}
+
+// isDegraded method returns true if any backend is unhealthy.
+func (b *balancer) isDegraded() bool {
+	return len(b.available()) != len(b.backends)
+}
+
+// health method returns the health of all backends.
+func (b *balancer) health() []model.TargetHealth {
+	health := make([]model.TargetHealth, 0, len(b.backends))
+	for _, target := range b.backends {
+		health = append(health, model.TargetHealth{
+			URL:    target.url.String(),
+			Status: target.getHealth(),
+		})
+	}
+
+	return health
+}
+
+// middleware method picks a backend for each request and keeps the count
+// of active requests while the request is being served.
+func (b *balancer) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		target := b.pick()
+		if target == nil {
+			writeErrorPage(w, r, http.StatusServiceUnavailable)
+			return
+		}
+
+		target.active.Add(1)
+		defer target.active.Add(-1)
+
+		accesslog.SetTarget(r.Context(), target.url.String())
+
+		ctx := context.WithValue(r.Context(), contextKeyBackend, target)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// getHealth method returns the last health status of the backend.
+func (b *backend) getHealth() model.HealthStatus {
+	return model.HealthStatus(b.health.Load())
+}
+
+// setHealth method sets the backend health and returns true if it changed.
+func (b *backend) setHealth(status model.HealthStatus) bool {
+	return model.HealthStatus(b.health.Swap(int32(status))) != status //nolint:gosec
+}
+
+// backendFromContext function returns the backend picked for the request.
+func backendFromContext(ctx context.Context) (*backend, bool) {
+	b, ok := ctx.Value(contextKeyBackend).(*backend)
+
+	return b, ok
+}
@@ -0,0 +1,104 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// testTargets function returns n target URLs.
+func testTargets(n int) []*url.URL {
+	targets := make([]*url.URL, 0, n)
+	for i := range n {
+		targets = append(targets, &url.URL{Scheme: "http", Host: "10.0.0." + strconv.Itoa(i+1) + ":80"})
+	}
+
+	return targets
+}
+
+func TestBalancerRoundRobin(t *testing.T) {
+	b := newBalancer(model.LoadBalanceRoundRobin, testTargets(3))
+
+	for i := range 6 {
+		if got := b.pick(); got != b.backends[i%3] {
+			t.Errorf("pick %d: got %s, want %s", i, got.url, b.backends[i%3].url)
+		}
+	}
+}
+
+func TestBalancerSkipsUnhealthy(t *testing.T) {
+	b := newBalancer(model.LoadBalanceRoundRobin, testTargets(3))
+	b.backends[1].setHealth(model.HealthStatusUnhealthy)
+
+	if !b.isDegraded() {
+		t.Error("balancer with an unhealthy backend not degraded")
+	}
+	for range 4 {
+		if got := b.pick(); got == b.backends[1] {
+			t.Fatal("unhealthy backend picked")
+		}
+	}
+
+	b.backends[0].setHealth(model.HealthStatusUnhealthy)
+	b.backends[2].setHealth(model.HealthStatusUnhealthy)
+	if got := b.pick(); got != nil {
+		t.Errorf("pick without healthy backends: got %s", got.url)
+	}
+}
+
+func TestBalancerLeastConn(t *testing.T) {
+	b := newBalancer(model.LoadBalanceLeastConn, testTargets(3))
+	b.backends[0].active.Store(2)
+	b.backends[1].active.Store(1)
+	b.backends[2].active.Store(3)
+
+	for range 3 {
+		if got := b.pick(); got != b.backends[1] {
+			t.Errorf("pick: got %s, want %s", got.url, b.backends[1].url)
+		}
+	}
+}
+
+func TestBalancerMiddleware(t *testing.T) {
+	b := newBalancer(model.LoadBalanceRoundRobin, testTargets(2))
+
+	var picked []*backend
+	handler := b.middleware(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		target, ok := backendFromContext(r.Context())
+		if !ok {
+			t.Fatal("no backend in the context")
+		}
+		if target.active.Load() != 1 {
+			t.Errorf("active requests: got %d, want 1", target.active.Load())
+		}
+		picked = append(picked, target)
+	}))
+
+	for range 2 {
+		handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if len(picked) != 2 || picked[0] == picked[1] {
+		t.Errorf("requests not distributed: %v", picked)
+	}
+	for _, target := range b.backends {
+		if target.active.Load() != 0 {
+			t.Errorf("%s: active requests not released", target.url)
+		}
+	}
+
+	for _, target := range b.backends {
+		target.setHealth(model.HealthStatusUnhealthy)
+	}
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status without backends: got %d", w.Code)
+	}
+}
@@ -0,0 +1,763 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"bytes"
+	"container/list"
+	"context"
+	"crypto/rand"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/consts"
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/metrics"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// contextKeyCache is the context key of the target response of a request that
+// can be stored in the cache.
+const contextKeyCache model.ContextKey = "contextkey.cache"
+
+// Cache status of the requests, returned in the consts.HeaderCacheStatus header.
+const (
+	cacheHit         = "HIT"
+	cacheMiss        = "MISS"
+	cacheRevalidated = "REVALIDATED"
+	cacheBypass      = "BYPASS"
+)
+
+const (
+	// bytesPerMegabyte is the unit of the cache sizes.
+	bytesPerMegabyte = 1024 * 1024
+	// cacheFileSuffix is the suffix of the files of disk caches.
+	cacheFileSuffix = ".cache"
+	// cacheDirMode is the permission of the disk cache directories.
+	cacheDirMode = 0o750
+)
+
+// cacheableStatus are the response status codes stored in the cache.
+var cacheableStatus = []int{
+	http.StatusOK,
+	http.StatusNonAuthoritativeInfo,
+	http.StatusNoContent,
+	http.StatusMovedPermanently,
+	http.StatusPermanentRedirect,
+	http.StatusNotFound,
+	http.StatusGone,
+}
+
+// hopHeaders are the headers of a response that aren't stored in the cache.
+var hopHeaders = []string{
+	"Connection",
+	"Keep-Alive",
+	"Proxy-Connection",
+	"Trailer",
+	"Transfer-Encoding",
+	"Upgrade",
+	"Age",
+	consts.HeaderCacheStatus,
+}
+
+type (
+	// responseCache struct is the HTTP cache of the responses of a port. Entries
+	// are evicted in least recently used order when the cache is bigger than
+	// maxSize. Bodies are kept in memory, or in files of dir if set. Responses
+	// are stored with the headers of the target, the response rules are applied
+	// when they are served.
+	responseCache struct {
+		log           zerolog.Logger
+		entries       map[string]*cacheEntry
+		lru           *list.List
+		requestRules  *headerRules
+		responseRules *headerRules
+		proxyName     string
+		portName      string
+		dir           string
+		maxSize       int64
+		maxEntrySize  int64
+		size          int64
+		mtx           sync.Mutex
+	}
+
+	// targetResponse struct stores the headers of the target response of a
+	// request, before the response rules.
+	targetResponse struct {
+		header http.Header
+	}
+
+	// cacheEntry struct is a response stored in the cache.
+	cacheEntry struct {
+		stored  time.Time
+		expires time.Time
+		header  http.Header
+		vary    map[string]string
+		elem    *list.Element
+		key     string
+		path    string
+		file    string
+		body    []byte
+		size    int64
+		status  int
+	}
+
+	// cacheRecorder struct wraps a http.ResponseWriter with the shared
+	// recorder and keeps a copy of the body, up to maxSize. Not modified
+	// responses of revalidations aren't written to the client, and the headers
+	// are restored to initialHeader.
+	cacheRecorder struct {
+		*core.ResponseRecorder
+		initialHeader http.Header
+		body          bytes.Buffer
+		maxSize       int64
+		revalidating  bool
+		notModified   bool
+		wroteHeader   bool
+		overflow      bool
+	}
+)
+
+// newResponseCache function returns the cache of a port, or nil if the cache
+// isn't enabled. The files of a disk cache left by a previous run are removed.
+// The header rules of the port are used to match the Vary headers with the
+// requests sent to the targets, and to serve the cached responses.
+func newResponseCache(log zerolog.Logger, proxyName string, pconfig model.PortConfig,
+	requestRules, responseRules *headerRules,
+) *responseCache {
+	cfg := pconfig.Cache
+	if !cfg.Enabled {
+		return nil
+	}
+
+	c := &responseCache{
+		log:           log,
+		entries:       make(map[string]*cacheEntry),
+		lru:           list.New(),
+		requestRules:  requestRules,
+		responseRules: responseRules,
+		proxyName:     proxyName,
+		portName:      pconfig.String(),
+		maxSize:       int64(intOr(cfg.MaxSize, model.DefaultCacheMaxSize)) * bytesPerMegabyte,
+		maxEntrySize:  int64(intOr(cfg.MaxEntrySize, model.DefaultCacheMaxEntrySize)) * bytesPerMegabyte,
+	}
+	c.maxEntrySize = min(c.maxEntrySize, c.maxSize)
+
+	if cfg.Dir != "" {
+		dir := filepath.Join(cfg.Dir, proxyName, strings.ReplaceAll(c.portName, "/", "_"))
+		if err := os.MkdirAll(dir, cacheDirMode); err != nil {
+			log.Error().Err(err).Str("dir", dir).Msg("error creating cache directory, using memory cache")
+		} else {
+			c.dir = dir
+			c.removeFiles()
+		}
+	}
+
+	return c
+}
+
+// intOr function returns the value i, or def if i isn't positive.
+func intOr(i, def int) int {
+	if i > 0 {
+		return i
+	}
+
+	return def
+}
+
+// middleware method serves the requests from the cache, and stores the
+// cacheable responses of the targets.
+func (c *responseCache) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCC := parseCacheControl(r.Header)
+		if !cacheableRequest(r, reqCC) {
+			c.observe(w, cacheBypass)
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header := c.upstreamHeader(r)
+		entry := c.get(r, header)
+
+		if entry != nil && entry.isFresh(reqCC) {
+			if c.serve(w, r, entry, cacheHit) {
+				return
+			}
+			entry = nil
+		}
+
+		c.forward(w, r, next, header, entry)
+	})
+}
+
+// forward method sends a request to the targets, revalidating the stale entry
+// if it has validators, and stores the response if it's cacheable.
+func (c *responseCache) forward(w http.ResponseWriter, r *http.Request, next http.Handler, header http.Header, entry *cacheEntry) {
+	target := &targetResponse{}
+	out := r.WithContext(context.WithValue(r.Context(), contextKeyCache, target))
+
+	// client conditional requests are forwarded, the cache can't answer them
+	revalidating := entry != nil && r.Method == http.MethodGet && !isConditional(r) && entry.hasValidators()
+	if revalidating {
+		out = entry.conditionalRequest(out)
+	}
+
+	rec := &cacheRecorder{
+		ResponseRecorder: core.NewResponseRecorder(w),
+		initialHeader:    w.Header().Clone(),
+		maxSize:          c.maxEntrySize,
+		revalidating:     revalidating,
+	}
+	w.Header().Set(consts.HeaderCacheStatus, cacheMiss)
+	next.ServeHTTP(rec, out)
+
+	if rec.notModified {
+		if !c.serve(w, r, c.refresh(entry, target.header), cacheRevalidated) {
+			http.Error(w, http.StatusText(http.StatusBadGateway), http.StatusBadGateway)
+		}
+		return
+	}
+
+	metrics.ObserveCache(c.proxyName, c.portName, strings.ToLower(cacheMiss))
+	// responses not written by the targets, like errors, aren't stored
+	if r.Method == http.MethodGet && target.header != nil && rec.cacheable() {
+		c.store(r, header, target.header, rec)
+	}
+}
+
+// saveTargetHeader function keeps the headers of a target response if the
+// request can be stored in the cache. It runs before the response rules.
+func saveTargetHeader(resp *http.Response) {
+	if target, ok := resp.Request.Context().Value(contextKeyCache).(*targetResponse); ok {
+		target.header = resp.Header.Clone()
+	}
+}
+
+// upstreamHeader method returns the headers of a request as they are sent to
+// the targets, with the identity of the user and the request rules, to match
+// the Vary headers of the responses.
+func (c *responseCache) upstreamHeader(r *http.Request) http.Header {
+	header := r.Header.Clone()
+
+	who, ok := model.WhoisFromContext(r.Context())
+	if ok {
+		setWhoisHeaders(header, who)
+	}
+	if c.requestRules != nil {
+		c.requestRules.apply(header, who)
+	}
+
+	return header
+}
+
+// cacheKey function returns the key of a request in the cache. Responses are
+// stored for the Tailscale user of the request, or for all users if shared.
+// Requests without a Tailscale identity, like the requests of Funnel clients,
+// only have the shared key, so they can't get the responses of other clients.
+func cacheKey(r *http.Request, shared bool) (string, bool) {
+	key := r.Host + r.URL.RequestURI()
+	if shared {
+		return key, true
+	}
+
+	who, ok := model.WhoisFromContext(r.Context())
+	if !ok || who.ID == "" {
+		return "", false
+	}
+
+	return who.ID + "@" + key, true
+}
+
+// observe method records the cache status of a request in the metrics and in
+// the response headers.
+func (c *responseCache) observe(w http.ResponseWriter, status string) {
+	w.Header().Set(consts.HeaderCacheStatus, status)
+	metrics.ObserveCache(c.proxyName, c.portName, strings.ToLower(status))
+}
+
+// cacheableRequest function returns true if the request can be served from the
+// cache. Range and upgrade requests are always forwarded.
+func cacheableRequest(r *http.Request, cc map[string]string) bool {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+	if r.Header.Get("Range") != "" || r.Header.Get("Upgrade") != "" {
+		return false
+	}
+	_, noStore := cc["no-store"]
+
+	return !noStore
+}
+
+// isConditional function returns true if the client sent a conditional request.
+func isConditional(r *http.Request) bool {
+	return r.Header.Get("If-None-Match") != "" || r.Header.Get("If-Modified-Since") != ""
+}
+
+// get method returns the entry of the request for its user, or the shared
+// entry, if it matches the Vary headers sent to the targets, nil otherwise.
+// Entries aren't modified after they are added, so they can be read without
+// lock.
+func (c *responseCache) get(r *http.Request, header http.Header) *cacheEntry {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	for _, shared := range []bool{false, true} {
+		key, ok := cacheKey(r, shared)
+		if !ok {
+			continue
+		}
+		if e, ok := c.entries[key]; ok && e.matches(header) {
+			c.lru.MoveToFront(e.elem)
+			return e
+		}
+	}
+
+	return nil
+}
+
+// serve method writes a cached response. Returns false if the body of the
+// entry can't be read, without writing to the client.
+func (c *responseCache) serve(w http.ResponseWriter, r *http.Request, e *cacheEntry, status string) bool {
+	var body io.ReadCloser
+	if r.Method != http.MethodHead {
+		var err error
+		if body, err = e.open(); err != nil {
+			c.log.Debug().Err(err).Str("url", e.key).Msg("error reading cache entry")
+			c.remove(e)
+			return false
+		}
+		defer body.Close()
+	}
+
+	// headers of the outer middlewares are kept
+	h := w.Header()
+	for name, values := range e.header {
+		h[name] = slices.Clone(values)
+	}
+	if c.responseRules != nil {
+		who, _ := model.WhoisFromContext(r.Context())
+		c.responseRules.apply(h, who)
+	}
+	h.Set("Age", strconv.Itoa(int(time.Since(e.stored).Seconds())))
+	c.observe(w, status)
+
+	if notModified(r, e.header) {
+		w.WriteHeader(http.StatusNotModified)
+		return true
+	}
+
+	w.WriteHeader(e.status)
+	if body != nil {
+		if _, err := io.Copy(w, body); err != nil {
+			c.log.Debug().Err(err).Str("url", e.key).Msg("error writing cached response")
+		}
+	}
+
+	return true
+}
+
+// notModified function returns true if the client has the cached response,
+// checking If-None-Match, or If-Modified-Since without If-None-Match.
+func notModified(r *http.Request, header http.Header) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		etag := strings.TrimPrefix(header.Get("ETag"), "W/")
+		if etag == "" {
+			return false
+		}
+		for candidate := range strings.SplitSeq(match, ",") {
+			candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+			if candidate == "*" || candidate == etag {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(header.Get("Last-Modified"))
+
+	return err == nil && !modified.After(since)
+}
+
+// store method adds a response to the cache, if its headers allow it. The
+// response is stored for the user of the request, unless it is public or has
+// s-maxage. Responses that aren't shared aren't stored without a user.
+func (c *responseCache) store(r *http.Request, upstreamHeader, targetHeader http.Header, rec *cacheRecorder) {
+	header := targetHeader.Clone()
+	for _, name := range hopHeaders {
+		header.Del(name)
+	}
+
+	now := time.Now()
+	cc := parseCacheControl(header)
+	lifetime, ok := freshness(r, header, cc, now)
+	if !ok {
+		return
+	}
+
+	vary, ok := varyValues(header, upstreamHeader)
+	if !ok {
+		return
+	}
+
+	_, public := cc["public"]
+	_, hasSMaxAge := cc["s-maxage"]
+	key, ok := cacheKey(r, public || hasSMaxAge)
+	if !ok {
+		return
+	}
+
+	body := slices.Clone(rec.body.Bytes())
+	e := &cacheEntry{
+		stored:  now,
+		expires: now.Add(lifetime),
+		header:  header,
+		vary:    vary,
+		key:     key,
+		path:    r.URL.Path,
+		size:    int64(len(body)),
+		status:  rec.Status,
+	}
+
+	if c.dir == "" {
+		e.body = body
+	} else {
+		e.file = filepath.Join(c.dir, rand.Text()+cacheFileSuffix)
+		if err := os.WriteFile(e.file, body, consts.PermOwnerRead|consts.PermOwnerWrite); err != nil {
+			c.log.Error().Err(err).Str("url", key).Msg("error writing cache file")
+			return
+		}
+	}
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if old, ok := c.entries[key]; ok {
+		c.removeLocked(old)
+	}
+	e.elem = c.lru.PushFront(e)
+	c.entries[key] = e
+	c.size += e.size
+
+	for c.size > c.maxSize && c.lru.Len() > 1 {
+		c.removeLocked(c.lru.Back().Value.(*cacheEntry))
+	}
+}
+
+// varyValues function returns the values of the headers sent to the targets
+// listed in the Vary headers of a response, and false if the response varies
+// on everything.
+func varyValues(header, upstreamHeader http.Header) (map[string]string, bool) {
+	vary := make(map[string]string)
+
+	for _, v := range header.Values("Vary") {
+		for name := range strings.SplitSeq(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name == "*" {
+				return nil, false
+			}
+			if name != "" {
+				vary[name] = upstreamHeader.Get(name)
+			}
+		}
+	}
+
+	return vary, true
+}
+
+// freshness function returns the time a response can be served from the cache,
+// and false if it can't be stored. Responses without freshness are only stored
+// if they can be revalidated with ETag or Last-Modified.
+func freshness(r *http.Request, header http.Header, cc map[string]string, now time.Time) (time.Duration, bool) {
+	if _, ok := cc["no-store"]; ok {
+		return 0, false
+	}
+	if _, ok := cc["private"]; ok {
+		return 0, false
+	}
+	if header.Get("Set-Cookie") != "" {
+		return 0, false
+	}
+
+	_, public := cc["public"]
+	_, hasSMaxAge := cc["s-maxage"]
+	// responses of authenticated requests are private unless marked otherwise
+	if r.Header.Get("Authorization") != "" && !public && !hasSMaxAge {
+		return 0, false
+	}
+
+	lifetime := lifetimeOf(cc, header, now)
+	if _, ok := cc["no-cache"]; ok {
+		lifetime = 0
+	}
+	if age, err := strconv.Atoi(header.Get("Age")); err == nil {
+		lifetime -= time.Duration(age) * time.Second
+	}
+
+	hasValidators := header.Get("ETag") != "" || header.Get("Last-Modified") != ""
+
+	return max(lifetime, 0), lifetime > 0 || hasValidators
+}
+
+// lifetimeOf function returns the freshness lifetime of a response, from the
+// s-maxage or max-age directives, or the Expires header.
+func lifetimeOf(cc map[string]string, header http.Header, now time.Time) time.Duration {
+	if sMaxAge, ok := cc["s-maxage"]; ok {
+		return parseSeconds(sMaxAge)
+	}
+	if maxAge, ok := cc["max-age"]; ok {
+		return parseSeconds(maxAge)
+	}
+
+	expires, err := http.ParseTime(header.Get("Expires"))
+	if err != nil {
+		return 0
+	}
+	date, err := http.ParseTime(header.Get("Date"))
+	if err != nil {
+		date = now
+	}
+
+	return expires.Sub(date)
+}
+
+// parseSeconds function parses the seconds of a Cache-Control directive.
+func parseSeconds(s string) time.Duration {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0
+	}
+
+	return time.Duration(n) * time.Second
+}
+
+// parseCacheControl function returns the directives of the Cache-Control
+// headers, with their lowercase names.
+func parseCacheControl(header http.Header) map[string]string {
+	cc := make(map[string]string)
+
+	for _, v := range header.Values("Cache-Control") {
+		for directive := range strings.SplitSeq(v, ",") {
+			name, value, _ := strings.Cut(strings.TrimSpace(directive), "=")
+			if name != "" {
+				cc[strings.ToLower(name)] = strings.Trim(value, `"`)
+			}
+		}
+	}
+	if len(cc) == 0 && header.Get("Pragma") == "no-cache" {
+		cc["no-cache"] = ""
+	}
+
+	return cc
+}
+
+// refresh method replaces an entry with a copy updated with the headers of a
+// not modified response, and returns the copy.
+func (c *responseCache) refresh(e *cacheEntry, header http.Header) *cacheEntry {
+	updated := *e
+	updated.header = e.header.Clone()
+	for _, name := range []string{"Cache-Control", "Date", "ETag", "Expires", "Last-Modified", "Vary"} {
+		if values := header.Values(name); len(values) > 0 {
+			updated.header[name] = values
+		}
+	}
+
+	now := time.Now()
+	lifetime, _ := freshness(&http.Request{Header: http.Header{}}, updated.header, parseCacheControl(updated.header), now)
+	updated.stored = now
+	updated.expires = now.Add(lifetime)
+
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.entries[e.key] == e {
+		c.entries[e.key] = &updated
+		updated.elem.Value = &updated
+	}
+
+	return &updated
+}
+
+// purge method removes the entries with a path starting with prefix, or all
+// entries if prefix is empty. Returns the number of entries removed.
+func (c *responseCache) purge(prefix string) int {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	n := 0
+	for _, e := range c.entries {
+		if strings.HasPrefix(e.path, prefix) {
+			c.removeLocked(e)
+			n++
+		}
+	}
+
+	return n
+}
+
+// remove method removes an entry from the cache.
+func (c *responseCache) remove(e *cacheEntry) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if c.entries[e.key] == e {
+		c.removeLocked(e)
+	}
+}
+
+// removeLocked method removes an entry and its file. The cache must be locked.
+func (c *responseCache) removeLocked(e *cacheEntry) {
+	delete(c.entries, e.key)
+	c.lru.Remove(e.elem)
+	c.size -= e.size
+
+	if e.file != "" {
+		if err := os.Remove(e.file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			c.log.Error().Err(err).Str("file", e.file).Msg("error removing cache file")
+		}
+	}
+}
+
+// removeFiles method removes the files of the disk cache directory.
+func (c *responseCache) removeFiles() {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*"+cacheFileSuffix))
+	if err != nil {
+		return
+	}
+
+	for _, f := range files {
+		if err := os.Remove(f); err != nil {
+			c.log.Error().Err(err).Str("file", f).Msg("error removing cache file")
+		}
+	}
+}
+
+// close method removes all entries of the cache.
+func (c *responseCache) close() {
+	c.purge("")
+}
+
+// conditionalRequest method returns a copy of the request to revalidate the
+// entry with the target.
+func (e *cacheEntry) conditionalRequest(r *http.Request) *http.Request {
+	out := r.Clone(r.Context())
+	if etag := e.header.Get("ETag"); etag != "" {
+		out.Header.Set("If-None-Match", etag)
+	}
+	if modified := e.header.Get("Last-Modified"); modified != "" {
+		out.Header.Set("If-Modified-Since", modified)
+	}
+
+	return out
+}
+
+// isFresh method returns true if the entry can be served without revalidation.
+func (e *cacheEntry) isFresh(reqCC map[string]string) bool {
+	_, noCache := reqCC["no-cache"]
+
+	return !noCache && time.Now().Before(e.expires)
+}
+
+// matches method returns true if the Vary headers of the entry have the values
+// of the headers sent to the targets.
+func (e *cacheEntry) matches(header http.Header) bool {
+	for name, value := range e.vary {
+		if header.Get(name) != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// hasValidators method returns true if the entry can be revalidated.
+func (e *cacheEntry) hasValidators() bool {
+	return e.header.Get("ETag") != "" || e.header.Get("Last-Modified") != ""
+}
+
+// open method returns a reader of the body of the entry.
+func (e *cacheEntry) open() (io.ReadCloser, error) {
+	if e.file == "" {
+		return io.NopCloser(bytes.NewReader(e.body)), nil
+	}
+
+	return os.Open(e.file)
+}
+
+// cacheable method returns true if the whole response was recorded with a
+// status that can be cached.
+func (rec *cacheRecorder) cacheable() bool {
+	return !rec.overflow && !rec.Hijacked && slices.Contains(cacheableStatus, rec.Status)
+}
+
+// WriteHeader overrides ResponseWriter.WriteHeader to keep track of the
+// response code. Not modified responses of revalidations aren't written.
+func (rec *cacheRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.wroteHeader = true
+
+	if rec.revalidating && status == http.StatusNotModified {
+		// the headers of the target are restored, the cached response is served
+		rec.Status = status
+		rec.notModified = true
+		h := rec.Header()
+		clear(h)
+		maps.Copy(h, rec.initialHeader)
+		return
+	}
+
+	rec.ResponseRecorder.WriteHeader(status)
+}
+
+func (rec *cacheRecorder) Write(data []byte) (int, error) {
+	if !rec.wroteHeader {
+		rec.WriteHeader(http.StatusOK)
+	}
+	if rec.notModified {
+		return len(data), nil
+	}
+
+	if !rec.overflow {
+		if int64(rec.body.Len()+len(data)) > rec.maxSize {
+			rec.overflow = true
+			rec.body = bytes.Buffer{}
+		} else {
+			rec.body.Write(data)
+		}
+	}
+
+	return rec.ResponseRecorder.Write(data)
+}
+
+// Flush method implements http.Flusher, not modified responses aren't flushed.
+func (rec *cacheRecorder) Flush() {
+	_ = rec.FlushError()
+}
+
+// FlushError method flushes the response, used by http.ResponseController.
+func (rec *cacheRecorder) FlushError() error {
+	if rec.notModified {
+		return nil
+	}
+
+	return rec.ResponseRecorder.FlushError()
+}
@@ -0,0 +1,369 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/consts"
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+var (
+	alice = model.Whois{ID: "1", Username: "alice@example.com"}
+	bob   = model.Whois{ID: "2", Username: "bob@example.com"}
+)
+
+// cacheTest struct is a cache in front of a reverse proxy to a test target,
+// like the handlers of a port.
+type cacheTest struct {
+	cache   *responseCache
+	handler http.Handler
+	hits    atomic.Int32
+}
+
+// newCacheTest function starts the target and returns the cache in front of it.
+func newCacheTest(t *testing.T, rules model.HeaderRules, target http.HandlerFunc) *cacheTest {
+	t.Helper()
+
+	ct := &cacheTest{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ct.hits.Add(1)
+		target(w, r)
+	}))
+	t.Cleanup(srv.Close)
+
+	targetURL, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestRules, err := newHeaderRules(model.HeaderRules{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	responseRules, err := newHeaderRules(rules)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxy := &httputil.ReverseProxy{
+		Rewrite: func(r *httputil.ProxyRequest) {
+			r.SetURL(targetURL)
+			if who, ok := model.WhoisFromContext(r.In.Context()); ok {
+				setWhoisHeaders(r.Out.Header, who)
+			}
+		},
+		ModifyResponse: func(resp *http.Response) error {
+			saveTargetHeader(resp)
+			who, _ := model.WhoisFromContext(resp.Request.Context())
+			responseRules.apply(resp.Header, who)
+			return nil
+		},
+	}
+
+	pconfig := model.PortConfig{Cache: model.Cache{Enabled: true, MaxSize: 1, MaxEntrySize: 1}}
+	ct.cache = newResponseCache(zerolog.Nop(), "test", pconfig, requestRules, responseRules)
+	ct.handler = ct.cache.middleware(proxy)
+
+	return ct
+}
+
+// do method sends a request of the user to the cache.
+func (ct *cacheTest) do(method, path string, who *model.Whois, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "http://app.example.com"+path, nil)
+	for name, values := range header {
+		r.Header[name] = values
+	}
+	if who != nil {
+		r = r.WithContext(model.WhoisNewContext(r.Context(), *who))
+	}
+
+	w := httptest.NewRecorder()
+	ct.handler.ServeHTTP(w, r)
+
+	return w
+}
+
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, status string) {
+	t.Helper()
+
+	if got := w.Header().Get(consts.HeaderCacheStatus); got != status {
+		t.Fatalf("cache status: got %q, want %q", got, status)
+	}
+}
+
+func TestCacheHit(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		fmt.Fprint(w, "hello")
+	})
+
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheMiss)
+	w := ct.do(http.MethodGet, "/", &alice, nil)
+	expectStatus(t, w, cacheHit)
+
+	if w.Body.String() != "hello" {
+		t.Errorf("body: got %q", w.Body.String())
+	}
+	if w.Header().Get("Age") == "" {
+		t.Error("missing Age header")
+	}
+	if ct.hits.Load() != 1 {
+		t.Errorf("target requests: got %d, want 1", ct.hits.Load())
+	}
+
+	expectStatus(t, ct.do(http.MethodHead, "/", &alice, nil), cacheHit)
+}
+
+func TestCacheUsers(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		fmt.Fprint(w, r.Header.Get(consts.HeaderUsername))
+	})
+
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheMiss)
+	w := ct.do(http.MethodGet, "/", &bob, nil)
+	expectStatus(t, w, cacheMiss)
+	if w.Body.String() != bob.Username {
+		t.Errorf("body of bob: got %q", w.Body.String())
+	}
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheHit)
+
+	expectStatus(t, ct.do(http.MethodGet, "/public", &alice, nil), cacheMiss)
+	expectStatus(t, ct.do(http.MethodGet, "/public", &bob, nil), cacheHit)
+	expectStatus(t, ct.do(http.MethodGet, "/public", nil, nil), cacheHit)
+}
+
+func TestCacheFunnelClients(t *testing.T) {
+	var requests atomic.Int32
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/public" {
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		} else {
+			w.Header().Set("Cache-Control", "max-age=60")
+		}
+		fmt.Fprintf(w, "response %d", requests.Add(1))
+	})
+
+	// the Funnel gate removes the credentials before the cache
+	pconfig := model.PortConfig{Tailscale: model.TailscalePort{
+		Funnel:     true,
+		Protection: model.FunnelProtection{BearerTokens: []string{"token-a", "token-b"}},
+	}}
+	ct.handler = newFunnelGate(zerolog.Nop(), "test", "443/https", pconfig).middleware(ct.handler)
+
+	funnelDo := func(path, token string) *httptest.ResponseRecorder {
+		return funnelRequest(ct.handler, funnelClient, path, http.Header{"Authorization": {"Bearer " + token}})
+	}
+
+	expectStatus(t, funnelDo("/", "token-a"), cacheMiss)
+	w := funnelDo("/", "token-b")
+	expectStatus(t, w, cacheMiss)
+	if w.Body.String() != "response 2" {
+		t.Errorf("body of the second client: got %q", w.Body.String())
+	}
+	expectStatus(t, funnelDo("/", "token-a"), cacheMiss)
+
+	// shared responses are served to all clients
+	expectStatus(t, funnelDo("/public", "token-a"), cacheMiss)
+	expectStatus(t, funnelDo("/public", "token-b"), cacheHit)
+}
+
+func TestCacheVaryIdentity(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+		w.Header().Set("Vary", consts.HeaderUsername)
+		fmt.Fprint(w, r.Header.Get(consts.HeaderUsername))
+	})
+
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheMiss)
+	// the client can't match the Vary header with its own identity header
+	spoofed := http.Header{consts.HeaderUsername: {alice.Username}}
+	w := ct.do(http.MethodGet, "/", &bob, spoofed)
+	expectStatus(t, w, cacheMiss)
+	if w.Body.String() != bob.Username {
+		t.Errorf("body of bob: got %q", w.Body.String())
+	}
+}
+
+func TestCacheNotStored(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		name   string
+	}{
+		{name: "no-store", header: http.Header{"Cache-Control": {"no-store"}}},
+		{name: "private", header: http.Header{"Cache-Control": {"private, max-age=60"}}},
+		{name: "set-cookie", header: http.Header{"Cache-Control": {"max-age=60"}, "Set-Cookie": {"a=b"}}},
+		{name: "vary-all", header: http.Header{"Cache-Control": {"max-age=60"}, "Vary": {"*"}}},
+		{name: "no-freshness", header: http.Header{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, _ *http.Request) {
+				for name, values := range tt.header {
+					w.Header()[name] = values
+				}
+			})
+
+			ct.do(http.MethodGet, "/", &alice, nil)
+			expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheMiss)
+		})
+	}
+}
+
+func TestCacheBypass(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+	})
+
+	expectStatus(t, ct.do(http.MethodPost, "/", &alice, nil), cacheBypass)
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, http.Header{"Range": {"bytes=0-1"}}), cacheBypass)
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, http.Header{"Cache-Control": {"no-store"}}), cacheBypass)
+}
+
+func TestCacheRevalidate(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		w.Header().Set("Cache-Control", "no-cache")
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		fmt.Fprint(w, "hello")
+	})
+
+	expectStatus(t, ct.do(http.MethodGet, "/", &alice, nil), cacheMiss)
+	w := ct.do(http.MethodGet, "/", &alice, nil)
+	expectStatus(t, w, cacheRevalidated)
+	if w.Code != http.StatusOK || w.Body.String() != "hello" {
+		t.Errorf("revalidated response: got %d %q", w.Code, w.Body.String())
+	}
+
+	// conditional requests of the client are answered from the cache
+	w = ct.do(http.MethodGet, "/", &alice, http.Header{"If-None-Match": {`"v1"`}})
+	if w.Code != http.StatusNotModified {
+		t.Errorf("conditional request: got %d", w.Code)
+	}
+}
+
+func TestCacheServeHeaders(t *testing.T) {
+	rules := model.HeaderRules{Set: map[string]string{"X-User": "{{ .Username }}"}}
+	ct := newCacheTest(t, rules, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "public, max-age=60")
+	})
+
+	ct.do(http.MethodGet, "/", &alice, nil)
+
+	// headers of outer middlewares are kept, and rules use the current user
+	r := httptest.NewRequest(http.MethodGet, "http://app.example.com/", nil)
+	r = r.WithContext(model.WhoisNewContext(r.Context(), bob))
+	w := httptest.NewRecorder()
+	w.Header().Set("X-Outer", "1")
+	ct.handler.ServeHTTP(w, r)
+
+	expectStatus(t, w, cacheHit)
+	if w.Header().Get("X-Outer") != "1" {
+		t.Error("header of outer middleware removed")
+	}
+	if got := w.Header().Get("X-User"); got != bob.Username {
+		t.Errorf("response rule: got %q, want %q", got, bob.Username)
+	}
+}
+
+func TestCachePurge(t *testing.T) {
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+	})
+
+	for _, path := range []string{"/assets/a.js", "/assets/b.js", "/index.html"} {
+		ct.do(http.MethodGet, path, &alice, nil)
+	}
+
+	if n := ct.cache.purge("/assets/"); n != 2 {
+		t.Errorf("purged: got %d, want 2", n)
+	}
+	expectStatus(t, ct.do(http.MethodGet, "/index.html", &alice, nil), cacheHit)
+	expectStatus(t, ct.do(http.MethodGet, "/assets/a.js", &alice, nil), cacheMiss)
+}
+
+func TestCacheEviction(t *testing.T) {
+	body := make([]byte, bytesPerMegabyte/2)
+	ct := newCacheTest(t, model.HeaderRules{}, func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Cache-Control", "max-age=60")
+		_, _ = w.Write(body)
+	})
+
+	ct.do(http.MethodGet, "/a", &alice, nil)
+	ct.do(http.MethodGet, "/b", &alice, nil)
+	ct.do(http.MethodGet, "/a", &alice, nil)
+	ct.do(http.MethodGet, "/c", &alice, nil)
+
+	// /b is the least recently used entry
+	expectStatus(t, ct.do(http.MethodGet, "/a", &alice, nil), cacheHit)
+	expectStatus(t, ct.do(http.MethodGet, "/b", &alice, nil), cacheMiss)
+}
+
+func TestCacheRecorderFlush(t *testing.T) {
+	for _, notModified := range []bool{false, true} {
+		w := httptest.NewRecorder()
+		rec := &cacheRecorder{ResponseRecorder: core.NewResponseRecorder(w), notModified: notModified}
+
+		// streamed responses are flushed through the recorders of the port
+		if err := http.NewResponseController(core.NewResponseRecorder(rec)).Flush(); err != nil {
+			t.Fatal(err)
+		}
+		if w.Flushed == notModified {
+			t.Errorf("not modified %v: flushed %v", notModified, w.Flushed)
+		}
+	}
+}
+
+func TestFreshness(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		header   http.Header
+		name     string
+		lifetime time.Duration
+		ok       bool
+	}{
+		{name: "max-age", header: http.Header{"Cache-Control": {"max-age=60"}}, lifetime: time.Minute, ok: true},
+		{name: "s-maxage", header: http.Header{"Cache-Control": {"max-age=60, s-maxage=120"}}, lifetime: 2 * time.Minute, ok: true},
+		{name: "age", header: http.Header{"Cache-Control": {"max-age=60"}, "Age": {"30"}}, lifetime: 30 * time.Second, ok: true},
+		{
+			name:     "expires",
+			header:   http.Header{"Expires": {now.Add(time.Hour).UTC().Format(http.TimeFormat)}, "Date": {now.UTC().Format(http.TimeFormat)}},
+			lifetime: time.Hour,
+			ok:       true,
+		},
+		{name: "validator", header: http.Header{"Last-Modified": {now.UTC().Format(http.TimeFormat)}}, ok: true},
+		{name: "no-cache", header: http.Header{"Cache-Control": {"no-cache, max-age=60"}}},
+		{name: "pragma", header: http.Header{"Pragma": {"no-cache"}}},
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lifetime, ok := freshness(r, tt.header, parseCacheControl(tt.header), now)
+			if ok != tt.ok || lifetime.Round(time.Second) != tt.lifetime {
+				t.Errorf("got %v %v, want %v %v", lifetime, ok, tt.lifetime, tt.ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/ui/pages"
+	"github.com/xybydy/tsdproxy/web"
+
+	"github.com/rs/zerolog"
+)
+
+const contextKeyErrorPages model.ContextKey = "contextkey.errorpages"
+
+// errorPages struct renders the pages of the proxy errors and of the
+// maintenance mode, branded with the dashboard label and icon of the proxy.
+type errorPages struct {
+	log         zerolog.Logger
+	maintenance func() bool
+	label       string
+	icon        string
+}
+
+// newErrorPages function returns the error pages of a proxy. maintenance
+// returns true while the proxy is in maintenance mode.
+func newErrorPages(log zerolog.Logger, pcfg *model.Config, maintenance func() bool) *errorPages {
+	label := pcfg.Dashboard.Label
+	if label == "" {
+		label = pcfg.Hostname
+	}
+
+	iconName := pcfg.Dashboard.Icon
+	if iconName == "" {
+		iconName = model.DefaultDashboardIcon
+	}
+
+	// the icon is embedded, the dashboard isn't reachable from the proxy hostname
+	var icon string
+	if svg, err := web.Icon(iconName); err == nil {
+		icon = "data:image/svg+xml;base64," + base64.StdEncoding.EncodeToString(svg)
+	} else {
+		log.Debug().Err(err).Str("icon", iconName).Msg("error page without icon")
+	}
+
+	return &errorPages{
+		log:         log,
+		maintenance: maintenance,
+		label:       label,
+		icon:        icon,
+	}
+}
+
+// middleware method serves the maintenance page while the proxy is in
+// maintenance mode, and adds the error pages to the request context.
+func (e *errorPages) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if e.maintenance() {
+			e.write(w, r, http.StatusServiceUnavailable, pages.ProxyErrorData{
+				Title:   "Under maintenance",
+				Message: e.label + " is under maintenance and will be back soon.",
+			})
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), contextKeyErrorPages, e)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// proxyErrorHandler method is the ErrorHandler of the reverse proxy, returning
+// 504 on target timeouts and 502 on other target errors.
+func (e *errorPages) proxyErrorHandler(w http.ResponseWriter, r *http.Request, err error) {
+	code := http.StatusBadGateway
+
+	var netErr net.Error
+	switch {
+	case errors.Is(err, context.Canceled):
+		// the client is gone
+		w.WriteHeader(code)
+		return
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		code = http.StatusGatewayTimeout
+	}
+
+	e.log.Error().Err(err).Str("url", r.URL.String()).Msg("error proxying request")
+
+	e.writeError(w, r, code)
+}
+
+// writeError method writes the page of an error status code.
+func (e *errorPages) writeError(w http.ResponseWriter, r *http.Request, code int) {
+	data := pages.ProxyErrorData{Code: code, Title: http.StatusText(code)}
+
+	switch code {
+	case http.StatusBadGateway:
+		data.Message = e.label + " is not responding correctly. Please try again later."
+	case http.StatusServiceUnavailable:
+		data.Message = e.label + " is unavailable right now. Please try again later."
+	case http.StatusGatewayTimeout:
+		data.Message = e.label + " took too long to respond. Please try again later."
+	}
+
+	e.write(w, r, code, data)
+}
+
+// write method writes a page, or a plain text error to clients that don't
+// accept HTML, like API and gRPC clients.
+func (e *errorPages) write(w http.ResponseWriter, r *http.Request, code int, data pages.ProxyErrorData) {
+	w.Header().Set("Cache-Control", "no-store")
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		http.Error(w, data.Title, code)
+		return
+	}
+
+	data.Label = e.label
+	data.Icon = e.icon
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(code)
+
+	if err := pages.ProxyError(data).Render(r.Context(), w); err != nil {
+		e.log.Error().Err(err).Msg("error rendering error page")
+	}
+}
+
+// writeErrorPage function writes the error page of the port of the request,
+// or a plain text error if the port has no error pages.
+func writeErrorPage(w http.ResponseWriter, r *http.Request, code int) {
+	if e, ok := r.Context().Value(contextKeyErrorPages).(*errorPages); ok {
+		e.writeError(w, r, code)
+		return
+	}
+
+	http.Error(w, http.StatusText(code), code)
+}
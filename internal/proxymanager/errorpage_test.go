@@ -0,0 +1,132 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func TestErrorPages(t *testing.T) {
+	loadTestConfig(t, "")
+
+	// the target is down
+	srv := httptest.NewServer(nil)
+	srv.Close()
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pconfig := newTestPort(t, "443/https:80/http", target)
+
+	var maintenance atomic.Bool
+	proxyConfig := &model.Config{Hostname: "web", Dashboard: model.Dashboard{Label: "My Web"}}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, maintenance.Load)
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		accept      string
+		body        string
+		contentType string
+		status      int
+		maintenance bool
+	}{
+		{
+			name: "browser", accept: "text/html,*/*", status: http.StatusBadGateway,
+			body: "My Web is not responding correctly", contentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "API client", accept: "application/json", status: http.StatusBadGateway,
+			body: "Bad Gateway\n", contentType: "text/plain; charset=utf-8",
+		},
+		{
+			name: "maintenance", accept: "text/html", status: http.StatusServiceUnavailable, maintenance: true,
+			body: "My Web is under maintenance", contentType: "text/html; charset=utf-8",
+		},
+		{
+			name: "maintenance API client", status: http.StatusServiceUnavailable, maintenance: true,
+			body: "Under maintenance\n", contentType: "text/plain; charset=utf-8",
+		},
+	}
+
+	for _, tt := range tests {
+		maintenance.Store(tt.maintenance)
+
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		p.httpServer.Handler.ServeHTTP(w, r)
+
+		if w.Code != tt.status {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.status)
+		}
+		if !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: body %q doesn't contain %q", tt.name, w.Body.String(), tt.body)
+		}
+		if got := w.Header().Get("Content-Type"); got != tt.contentType {
+			t.Errorf("%s: content type %q", tt.name, got)
+		}
+		if got := w.Header().Get("Cache-Control"); got != "no-store" {
+			t.Errorf("%s: cache control %q", tt.name, got)
+		}
+	}
+}
+
+func TestErrorPagesLabel(t *testing.T) {
+	// icons are built with the dashboard, unknown icons are omitted
+	proxyConfig := &model.Config{Hostname: "web", Dashboard: model.Dashboard{Icon: "missing-icon"}}
+	if e := newErrorPages(zerolog.Nop(), proxyConfig, nil); e.label != "web" || e.icon != "" {
+		t.Errorf("label and icon: got %q, %q", e.label, e.icon)
+	}
+}
+
+func TestProxyManagerMaintenance(t *testing.T) {
+	loadTestConfig(t, "")
+
+	provider := newFakeProvider()
+	proxy := startTestProxy(t, provider, &model.Config{
+		Hostname: "web",
+		Ports:    model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))},
+	})
+
+	pm := NewProxyManager(zerolog.Nop(), nil)
+	pm.Proxies["web"] = proxy
+
+	if err := pm.SetMaintenance("missing", true); !errors.Is(err, ErrProxyNotFound) {
+		t.Errorf("unknown proxy: got %v", err)
+	}
+
+	addr := provider.proxy("web").addr("web")
+	for _, enabled := range []bool{true, false} {
+		if err := pm.SetMaintenance("web", enabled); err != nil {
+			t.Fatal(err)
+		}
+
+		want := "web"
+		if enabled {
+			want = "Under maintenance\n"
+		}
+		if body, err := get(addr); err != nil || body != want {
+			t.Errorf("maintenance %v: got %q, %v", enabled, body, err)
+		}
+		if proxy.IsMaintenance() != enabled || pm.maintenance["web"] != enabled {
+			t.Errorf("maintenance %v: proxy %v, kept %v", enabled, proxy.IsMaintenance(), pm.maintenance["web"])
+		}
+	}
+}
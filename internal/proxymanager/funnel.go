@@ -0,0 +1,389 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xybydy/tsdproxy/internal/metrics"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+const (
+	// funnelShareParam is the query parameter of the one-time share links.
+	funnelShareParam = "tsdproxy_share"
+	// funnelSessionCookie is the cookie of the sessions opened with share links.
+	funnelSessionCookie = "tsdproxy_funnel"
+	// defaultHTTPSPort is the port omitted from the share links.
+	defaultHTTPSPort = 443
+)
+
+// funnelGate struct protects a Funnel port from the internet clients. Links and
+// sessions are kept in memory, so they are invalidated when the proxy restarts.
+type funnelGate struct {
+	log          zerolog.Logger
+	basicAuth    map[string]string
+	limiter      *rateLimiter
+	failures     *rateLimiter
+	shareLinks   map[string]time.Time
+	sessions     map[string]time.Time
+	proxyName    string
+	portName     string
+	tokens       []string
+	allow        []netip.Prefix
+	deny         []netip.Prefix
+	shareLinkTTL time.Duration
+	sessionTTL   time.Duration
+	hasAuth      bool
+	allowLinks   bool
+	denyAll      bool
+	mtx          sync.Mutex
+}
+
+// newFunnelGate function returns the Funnel gate of a port, or nil if the port
+// isn't a Funnel port or has no protections.
+func newFunnelGate(log zerolog.Logger, proxyName, portName string, pconfig model.PortConfig) *funnelGate {
+	protection := pconfig.Tailscale.Protection
+	if !pconfig.Tailscale.Funnel || !protection.IsEnabled() {
+		return nil
+	}
+
+	g := &funnelGate{
+		log:          log,
+		proxyName:    proxyName,
+		portName:     portName,
+		basicAuth:    make(map[string]string),
+		tokens:       protection.BearerTokens,
+		limiter:      newRateLimiter(protection.RateLimit),
+		failures:     newRateLimiter(protection.FailureLimit),
+		shareLinks:   make(map[string]time.Time),
+		sessions:     make(map[string]time.Time),
+		shareLinkTTL: durationOr(protection.ShareLinkTTL, model.DefaultFunnelShareLinkTTL),
+		sessionTTL:   durationOr(protection.SessionTTL, model.DefaultFunnelSessionTTL),
+		hasAuth:      protection.HasAuth(),
+		allowLinks:   protection.ShareLinks,
+	}
+
+	// invalid CIDRs deny all the internet clients, instead of allowing the
+	// clients of a mistyped allow entry or of a missing deny entry
+	var errAllow, errDeny error
+	g.allow, errAllow = parsePrefixes(protection.Allow)
+	g.deny, errDeny = parsePrefixes(protection.Deny)
+	if err := errors.Join(errAllow, errDeny); err != nil {
+		log.Error().Err(err).Msg("invalid funnel CIDR, all internet clients are denied")
+		g.denyAll = true
+	}
+
+	for _, entry := range protection.BasicAuth {
+		user, password, ok := strings.Cut(entry, ":")
+		if !ok || user == "" || password == "" {
+			log.Error().Str("user", user).Msg("invalid funnel basic auth, expected user:password")
+			continue
+		}
+		g.basicAuth[user] = password
+	}
+
+	return g
+}
+
+// authenticates method returns true if the internet clients must authenticate
+// with basic auth, a bearer token or a share link.
+func (g *funnelGate) authenticates() bool {
+	return g.hasAuth || g.allowLinks
+}
+
+// durationOr function returns the duration d, or def if d isn't positive.
+func durationOr(d, def time.Duration) time.Duration {
+	if d > 0 {
+		return d
+	}
+
+	return def
+}
+
+// parsePrefixes function parses a list of CIDRs or single IP addresses.
+func parsePrefixes(list []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(list))
+
+	var errs error
+	for _, s := range list {
+		prefix, err := model.ParsePrefix(s)
+		if err != nil {
+			errs = errors.Join(errs, fmt.Errorf("%w: %s", model.ErrInvalidFunnelCIDR, s))
+			continue
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return prefixes, errs
+}
+
+// middleware method checks the requests of the internet clients. Requests from
+// the tailnet aren't checked.
+func (g *funnelGate) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		addr, ok := model.FunnelClientFromContext(r.Context())
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ip := clientIP(addr)
+		if !g.allowed(ip) {
+			g.log.Warn().Str("client", addr).Str("url", r.URL.String()).Msg("funnel client denied")
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+
+			return
+		}
+
+		key := ip.String()
+		if g.limiter != nil {
+			if ok, delay := g.limiter.allow(key); !ok {
+				g.tooManyRequests(w, key, delay)
+				return
+			}
+		}
+
+		if !g.hasAuth {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if g.failures != nil {
+			if delay := g.failures.wait(key); delay > 0 {
+				g.tooManyRequests(w, key, delay)
+				return
+			}
+		}
+
+		if token := r.URL.Query().Get(funnelShareParam); token != "" && g.allowLinks && g.redeemShareLink(w, r, token) {
+			return
+		}
+
+		if g.authenticate(r) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		g.fail(w, r, key)
+	})
+}
+
+// clientIP function returns the IP address of a client address.
+func clientIP(addr string) netip.Addr {
+	if ap, err := netip.ParseAddrPort(addr); err == nil {
+		return ap.Addr().Unmap()
+	}
+
+	ip, _ := netip.ParseAddr(addr)
+
+	return ip.Unmap()
+}
+
+// allowed method returns true if the client IP isn't denied and, with allow
+// CIDRs, is in one of them. No client is allowed with invalid CIDRs.
+func (g *funnelGate) allowed(ip netip.Addr) bool {
+	if g.denyAll {
+		return false
+	}
+
+	for _, p := range g.deny {
+		if p.Contains(ip) {
+			return false
+		}
+	}
+
+	if len(g.allow) == 0 {
+		return true
+	}
+
+	for _, p := range g.allow {
+		if p.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// authenticate method returns true if the request has a valid session, basic
+// auth or bearer token. The credentials used are removed from the request, so
+// they aren't forwarded to the targets.
+func (g *funnelGate) authenticate(r *http.Request) bool {
+	if cookie, err := r.Cookie(funnelSessionCookie); err == nil && g.validSession(cookie.Value) {
+		removeCookie(r, funnelSessionCookie)
+		return true
+	}
+
+	auth := r.Header.Get("Authorization")
+	if auth == "" {
+		return false
+	}
+
+	ok := false
+	if user, password, isBasic := r.BasicAuth(); isBasic {
+		ok = g.checkBasicAuth(user, password)
+	} else if token, isBearer := strings.CutPrefix(auth, "Bearer "); isBearer {
+		ok = g.checkToken(token)
+	}
+
+	if ok {
+		r.Header.Del("Authorization")
+	}
+
+	return ok
+}
+
+// checkBasicAuth method checks a user password, stored in plain text or as a
+// bcrypt hash.
+func (g *funnelGate) checkBasicAuth(user, password string) bool {
+	stored, ok := g.basicAuth[user]
+	if !ok {
+		return false
+	}
+
+	if strings.HasPrefix(stored, "$2") {
+		return bcrypt.CompareHashAndPassword([]byte(stored), []byte(password)) == nil
+	}
+
+	return subtle.ConstantTimeCompare([]byte(stored), []byte(password)) == 1
+}
+
+// checkToken method checks a bearer token.
+func (g *funnelGate) checkToken(token string) bool {
+	valid := false
+	for _, t := range g.tokens {
+		if subtle.ConstantTimeCompare([]byte(t), []byte(token)) == 1 {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+// fail method rejects a request without valid credentials, counting the
+// failure in the failure limit of the client.
+func (g *funnelGate) fail(w http.ResponseWriter, r *http.Request, key string) {
+	g.log.Warn().Str("client", r.RemoteAddr).Str("url", r.URL.String()).Msg("funnel authentication failed")
+
+	if g.failures != nil {
+		if ok, delay := g.failures.allow(key); !ok {
+			g.tooManyRequests(w, key, delay)
+			return
+		}
+	}
+
+	if len(g.basicAuth) > 0 {
+		w.Header().Set("WWW-Authenticate", `Basic realm="`+g.proxyName+`", charset="UTF-8"`)
+	}
+	http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+}
+
+// tooManyRequests method rejects a request of a client over a limit.
+func (g *funnelGate) tooManyRequests(w http.ResponseWriter, key string, delay time.Duration) {
+	g.log.Debug().Str("client", key).Dur("retry", delay).Msg("funnel client rate limited")
+	metrics.ObserveRateLimited(g.proxyName, g.portName)
+
+	writeTooManyRequests(w, delay)
+}
+
+// createShareLink method returns a new one-time share link token and its
+// expiration time.
+func (g *funnelGate) createShareLink() (string, time.Time) {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	now := time.Now()
+	removeExpired(g.shareLinks, now)
+
+	token := rand.Text()
+	expires := now.Add(g.shareLinkTTL)
+	g.shareLinks[token] = expires
+
+	return token, expires
+}
+
+// redeemShareLink method opens a session with a share link, removing the link,
+// and redirects the client to the URL without the link token. Returns false if
+// the link isn't valid.
+func (g *funnelGate) redeemShareLink(w http.ResponseWriter, r *http.Request, token string) bool {
+	g.mtx.Lock()
+	now := time.Now()
+	expires, ok := g.shareLinks[token]
+	delete(g.shareLinks, token)
+
+	var session string
+	if ok && now.Before(expires) {
+		removeExpired(g.sessions, now)
+		session = rand.Text()
+		g.sessions[session] = now.Add(g.sessionTTL)
+	}
+	g.mtx.Unlock()
+
+	if session == "" {
+		return false
+	}
+
+	g.log.Info().Str("client", r.RemoteAddr).Msg("funnel share link used")
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     funnelSessionCookie,
+		Value:    session,
+		Path:     "/",
+		MaxAge:   int(g.sessionTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	u := *r.URL
+	q := u.Query()
+	q.Del(funnelShareParam)
+	u.RawQuery = q.Encode()
+	http.Redirect(w, r, u.RequestURI(), http.StatusSeeOther)
+
+	return true
+}
+
+// validSession method returns true if the session exists and isn't expired.
+func (g *funnelGate) validSession(session string) bool {
+	g.mtx.Lock()
+	defer g.mtx.Unlock()
+
+	expires, ok := g.sessions[session]
+
+	return ok && time.Now().Before(expires)
+}
+
+// removeExpired function removes the expired tokens of a map.
+func removeExpired(tokens map[string]time.Time, now time.Time) {
+	for token, expires := range tokens {
+		if !now.Before(expires) {
+			delete(tokens, token)
+		}
+	}
+}
+
+// removeCookie function removes a cookie from the request headers.
+func removeCookie(r *http.Request, name string) {
+	cookies := r.Cookies()
+	r.Header.Del("Cookie")
+
+	for _, c := range cookies {
+		if c.Name != name {
+			r.AddCookie(c)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// funnelClient is the address of the internet client of the tests.
+const funnelClient = "198.51.100.8:40000"
+
+// newTestFunnelGate function returns the Funnel gate of the protection, in
+// front of a target that answers the Authorization and Cookie headers it received.
+func newTestFunnelGate(t *testing.T, protection model.FunnelProtection) (*funnelGate, http.Handler) {
+	t.Helper()
+
+	pconfig := model.PortConfig{Tailscale: model.TailscalePort{Funnel: true, Protection: protection}}
+	g := newFunnelGate(zerolog.Nop(), "web", "443/https", pconfig)
+	if g == nil {
+		t.Fatal("no funnel gate")
+	}
+
+	return g, g.middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.Header.Get("Authorization")+r.Header.Get("Cookie"))
+	}))
+}
+
+// funnelRequest function sends a request of the client address to the handler,
+// or a tailnet request if addr is empty.
+func funnelRequest(h http.Handler, addr, target string, header http.Header) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(http.MethodGet, target, nil)
+	if addr != "" {
+		r = r.WithContext(model.FunnelClientNewContext(r.Context(), addr))
+	}
+	for name, values := range header {
+		r.Header[name] = values
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	return w
+}
+
+func TestFunnelGateDisabled(t *testing.T) {
+	pconfig := model.PortConfig{Tailscale: model.TailscalePort{Protection: model.FunnelProtection{BearerTokens: []string{"token"}}}}
+	if newFunnelGate(zerolog.Nop(), "web", "443/https", pconfig) != nil {
+		t.Error("funnel gate of a port without funnel")
+	}
+
+	pconfig.Tailscale = model.TailscalePort{Funnel: true}
+	if newFunnelGate(zerolog.Nop(), "web", "443/https", pconfig) != nil {
+		t.Error("funnel gate without protections")
+	}
+}
+
+func TestFunnelGateCIDR(t *testing.T) {
+	_, h := newTestFunnelGate(t, model.FunnelProtection{
+		Allow: []string{"198.51.100.0/24"},
+		Deny:  []string{"198.51.100.7"},
+	})
+
+	tests := map[string]int{
+		"":                           http.StatusOK,
+		funnelClient:                 http.StatusOK,
+		"198.51.100.7:4000":          http.StatusForbidden,
+		"203.0.113.1:4000":           http.StatusForbidden,
+		"[::ffff:198.51.100.9]:4000": http.StatusOK,
+	}
+
+	for addr, status := range tests {
+		if got := funnelRequest(h, addr, "/", nil).Code; got != status {
+			t.Errorf("%q: got %d, want %d", addr, got, status)
+		}
+	}
+}
+
+func TestFunnelGateInvalidCIDR(t *testing.T) {
+	for name, protection := range map[string]model.FunnelProtection{
+		"invalid allow": {Allow: []string{"198.51.100.0/33"}},
+		"invalid deny":  {Allow: []string{"198.51.100.0/24"}, Deny: []string{"198.51.100.7/"}},
+	} {
+		_, h := newTestFunnelGate(t, protection)
+
+		// internet clients are denied, tailnet clients aren't checked
+		if got := funnelRequest(h, funnelClient, "/", nil).Code; got != http.StatusForbidden {
+			t.Errorf("%s: internet client: got %d", name, got)
+		}
+		if got := funnelRequest(h, "", "/", nil).Code; got != http.StatusOK {
+			t.Errorf("%s: tailnet client: got %d", name, got)
+		}
+	}
+}
+
+func TestFunnelGateAuth(t *testing.T) {
+	hash, err := bcrypt.GenerateFromPassword([]byte("secret"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, h := newTestFunnelGate(t, model.FunnelProtection{
+		BasicAuth:    []string{"alice:plain", "bob:" + string(hash)},
+		BearerTokens: []string{"token"},
+	})
+
+	basic := func(user, password string) http.Header {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.SetBasicAuth(user, password)
+
+		return r.Header
+	}
+
+	tests := []struct {
+		header http.Header
+		name   string
+		status int
+	}{
+		{name: "plain password", header: basic("alice", "plain"), status: http.StatusOK},
+		{name: "bcrypt password", header: basic("bob", "secret"), status: http.StatusOK},
+		{name: "bearer token", header: http.Header{"Authorization": {"Bearer token"}}, status: http.StatusOK},
+		{name: "wrong password", header: basic("alice", "secret"), status: http.StatusUnauthorized},
+		{name: "wrong token", header: http.Header{"Authorization": {"Bearer other"}}, status: http.StatusUnauthorized},
+		{name: "no credentials", status: http.StatusUnauthorized},
+	}
+
+	for _, tt := range tests {
+		w := funnelRequest(h, funnelClient, "/", tt.header)
+		if w.Code != tt.status {
+			t.Errorf("%s: got %d, want %d", tt.name, w.Code, tt.status)
+		}
+
+		// the credentials aren't forwarded to the target
+		if w.Code == http.StatusOK && w.Body.String() != "" {
+			t.Errorf("%s: credentials forwarded: %q", tt.name, w.Body.String())
+		}
+		if w.Code == http.StatusUnauthorized && !strings.HasPrefix(w.Header().Get("WWW-Authenticate"), "Basic") {
+			t.Errorf("%s: no basic auth challenge", tt.name)
+		}
+	}
+
+	// tailnet clients don't authenticate
+	if got := funnelRequest(h, "", "/", nil).Code; got != http.StatusOK {
+		t.Errorf("tailnet client: got %d", got)
+	}
+}
+
+func TestFunnelGateFailureLimit(t *testing.T) {
+	_, h := newTestFunnelGate(t, model.FunnelProtection{
+		BearerTokens: []string{"token"},
+		FailureLimit: model.RateLimit{Requests: 2, Period: time.Minute},
+	})
+
+	for i := range 2 {
+		if got := funnelRequest(h, funnelClient, "/", nil).Code; got != http.StatusUnauthorized {
+			t.Errorf("failure %d: got %d", i, got)
+		}
+	}
+
+	// the client is blocked, even with valid credentials
+	w := funnelRequest(h, funnelClient, "/", http.Header{"Authorization": {"Bearer token"}})
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("blocked client: got %d", w.Code)
+	}
+
+	// other clients aren't blocked
+	if got := funnelRequest(h, "198.51.100.9:4000", "/", http.Header{"Authorization": {"Bearer token"}}).Code; got != http.StatusOK {
+		t.Errorf("other client: got %d", got)
+	}
+}
+
+func TestFunnelShareLink(t *testing.T) {
+	g, h := newTestFunnelGate(t, model.FunnelProtection{ShareLinks: true})
+
+	token, expires := g.createShareLink()
+	if time.Until(expires) <= 23*time.Hour {
+		t.Errorf("expires: got %s", expires)
+	}
+
+	// the link opens a session and redirects to the URL without the token
+	target := "/page?" + url.Values{funnelShareParam: {token}, "id": {"1"}}.Encode()
+	w := funnelRequest(h, funnelClient, target, nil)
+	if w.Code != http.StatusSeeOther || w.Header().Get("Location") != "/page?id=1" {
+		t.Fatalf("share link: got %d to %q", w.Code, w.Header().Get("Location"))
+	}
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 || cookies[0].Name != funnelSessionCookie || !cookies[0].HttpOnly {
+		t.Fatalf("session cookie: got %v", cookies)
+	}
+
+	// links are used once
+	if got := funnelRequest(h, funnelClient, target, nil).Code; got != http.StatusUnauthorized {
+		t.Errorf("share link used twice: got %d", got)
+	}
+
+	// the session cookie isn't forwarded to the target, other cookies are
+	header := http.Header{"Cookie": {"theme=dark; " + cookies[0].Name + "=" + cookies[0].Value}}
+	w = funnelRequest(h, funnelClient, "/page", header)
+	if w.Code != http.StatusOK || w.Body.String() != "theme=dark" {
+		t.Errorf("session: got %d, %q", w.Code, w.Body.String())
+	}
+
+	header = http.Header{"Cookie": {funnelSessionCookie + "=invalid"}}
+	if got := funnelRequest(h, funnelClient, "/page", header).Code; got != http.StatusUnauthorized {
+		t.Errorf("invalid session: got %d", got)
+	}
+}
+
+func TestProxyCreateShareLink(t *testing.T) {
+	loadTestConfig(t, "")
+
+	funnelPort := func(label string) model.PortConfig {
+		pconfig := newTestPort(t, label, newTestTarget(t, "web"))
+		pconfig.Tailscale = model.TailscalePort{Funnel: true, Protection: model.FunnelProtection{ShareLinks: true}}
+
+		return pconfig
+	}
+
+	proxy := startTestProxy(t, newFakeProvider(), &model.Config{
+		Hostname: "web",
+		Ports: model.PortConfigList{
+			"web":  newTestPort(t, "443/https:80/http", newTestTarget(t, "web")),
+			"blog": funnelPort("8443/https:80/http"),
+		},
+	})
+
+	link, err := proxy.CreateShareLink("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.Port != "blog" || !strings.HasPrefix(link.URL, "https://web.example.ts.net:8443/?"+funnelShareParam+"=") {
+		t.Errorf("share link: got %+v", link)
+	}
+
+	if _, err := proxy.CreateShareLink("web"); !errors.Is(err, ErrShareLinksDisabled) {
+		t.Errorf("port without share links: got %v", err)
+	}
+	if _, err := proxy.CreateShareLink("missing"); !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("unknown port: got %v", err)
+	}
+
+	if err := proxy.StartPort("docs", funnelPort("9443/https:80/http")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := proxy.CreateShareLink(""); !errors.Is(err, ErrShareLinkPortRequired) {
+		t.Errorf("several ports with share links: got %v", err)
+	}
+}
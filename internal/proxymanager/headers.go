@@ -0,0 +1,112 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/xybydy/tsdproxy/internal/consts"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+type (
+	// headerRules struct applies the header rules of a port to a request or
+	// response headers.
+	headerRules struct {
+		set    []headerValue
+		add    []headerValue
+		remove []string
+	}
+
+	// headerValue struct is a header with its value template.
+	headerValue struct {
+		tmpl *template.Template
+		name string
+	}
+)
+
+// headerFuncs are the functions available in the header templates.
+var headerFuncs = template.FuncMap{
+	"join":  strings.Join,
+	"lower": strings.ToLower,
+	"upper": strings.ToUpper,
+}
+
+// newHeaderRules function compiles the header rules. Rules with invalid
+// templates are skipped and returned in the error.
+func newHeaderRules(rules model.HeaderRules) (*headerRules, error) {
+	var errs error
+
+	h := &headerRules{}
+	for _, name := range rules.Remove {
+		h.remove = append(h.remove, http.CanonicalHeaderKey(strings.TrimSpace(name)))
+	}
+
+	compile := func(values map[string]string) []headerValue {
+		list := make([]headerValue, 0, len(values))
+		// sorted, so rules are always applied in the same order
+		for _, name := range slices.Sorted(maps.Keys(values)) {
+			tmpl, err := template.New(name).Funcs(headerFuncs).Parse(values[name])
+			if err != nil {
+				errs = errors.Join(errs, fmt.Errorf("invalid value of header %s: %w", name, err))
+				continue
+			}
+			list = append(list, headerValue{name: http.CanonicalHeaderKey(name), tmpl: tmpl})
+		}
+		return list
+	}
+
+	h.set = compile(rules.Set)
+	h.add = compile(rules.Add)
+
+	return h, errs
+}
+
+// apply method removes, sets and adds the headers, using the identity of the user
+// in the values. Set headers with an empty value are removed, so clients can't
+// send them when there is no identity, and add headers with an empty value are
+// skipped.
+func (h *headerRules) apply(header http.Header, who model.Whois) {
+	for _, name := range h.remove {
+		header.Del(name)
+	}
+
+	for _, v := range h.set {
+		if value := v.value(who); value != "" {
+			header.Set(v.name, value)
+		} else {
+			header.Del(v.name)
+		}
+	}
+
+	for _, v := range h.add {
+		if value := v.value(who); value != "" {
+			header.Add(v.name, value)
+		}
+	}
+}
+
+// setWhoisHeaders function sets the headers with the identity of the
+// Tailscale user, sent to the targets.
+func setWhoisHeaders(header http.Header, who model.Whois) {
+	header.Set(consts.HeaderUsername, who.Username)
+	header.Set(consts.HeaderDisplayName, who.DisplayName)
+	header.Set(consts.HeaderProfilePicURL, who.ProfilePicURL)
+}
+
+// value method returns the header value for the identity.
+func (v *headerValue) value(who model.Whois) string {
+	var b strings.Builder
+	if err := v.tmpl.Execute(&b, who); err != nil {
+		return ""
+	}
+
+	return b.String()
+}
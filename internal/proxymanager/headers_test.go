@@ -0,0 +1,175 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/consts"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func TestHeaderRules(t *testing.T) {
+	rules, err := newHeaderRules(model.HeaderRules{
+		Remove: []string{"x-powered-by"},
+		Set: map[string]string{
+			"X-User":  "{{ .Username }}",
+			"X-Tags":  `{{ join .Tags "," }}`,
+			"X-Upper": "{{ upper .DisplayName }}",
+		},
+		Add: map[string]string{
+			"X-Group": "{{ range .Groups }}{{ . }}{{ end }}",
+			"Via":     "tsdproxy",
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	header := http.Header{
+		"X-Powered-By": {"php"},
+		"X-Tags":       {"spoofed"},
+		"Via":          {"1.1 cdn"},
+	}
+	rules.apply(header, model.Whois{ID: "1", Username: "alice@example.com", DisplayName: "Alice"})
+
+	want := http.Header{
+		"X-User":  {"alice@example.com"},
+		"X-Upper": {"ALICE"},
+		"Via":     {"1.1 cdn", "tsdproxy"},
+	}
+	if len(header) != len(want) {
+		t.Errorf("headers: got %v, want %v", header, want)
+	}
+	for name, values := range want {
+		if got := header.Values(name); len(got) != len(values) || got[len(got)-1] != values[len(values)-1] {
+			t.Errorf("%s: got %v, want %v", name, got, values)
+		}
+	}
+}
+
+func TestHeaderRulesInvalidTemplate(t *testing.T) {
+	rules, err := newHeaderRules(model.HeaderRules{
+		Set: map[string]string{"X-Invalid": "{{ .Username", "X-Valid": "ok"},
+	})
+	if err == nil {
+		t.Error("invalid template compiled")
+	}
+
+	header := http.Header{}
+	rules.apply(header, model.Whois{})
+	if header.Get("X-Valid") != "ok" || header.Get("X-Invalid") != "" {
+		t.Errorf("headers: got %v", header)
+	}
+}
+
+func TestPortHeaders(t *testing.T) {
+	loadTestConfig(t, "")
+
+	// the target answers the headers it received
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Server", "nginx")
+		_ = json.NewEncoder(w).Encode(r.Header)
+	}))
+	t.Cleanup(srv.Close)
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pconfig := newTestPort(t, "443/https:80/http", target)
+	pconfig.Headers = model.Headers{
+		Request:  model.HeaderRules{Set: map[string]string{"X-Email": "{{ .Username }}"}, Remove: []string{"Cookie"}},
+		Response: model.HeaderRules{Set: map[string]string{"X-User": "{{ .Username }}"}, Remove: []string{"Server"}},
+	}
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Cookie", "session=1")
+	r.Header.Set("X-Email", "mallory@example.com")
+	r.Header.Set(consts.HeaderUsername, "mallory@example.com")
+	w := httptest.NewRecorder()
+	p.httpServer.Handler.ServeHTTP(w, r)
+
+	var received http.Header
+	if err := json.NewDecoder(w.Body).Decode(&received); err != nil {
+		t.Fatal(err)
+	}
+	if got := received.Get("X-Email"); got != alice.Username {
+		t.Errorf("request header rule: got %q", got)
+	}
+	if got := received.Get(consts.HeaderUsername); got != alice.Username {
+		t.Errorf("identity header: got %q", got)
+	}
+	if got := received.Get("Cookie"); got != "" {
+		t.Errorf("removed request header sent: %q", got)
+	}
+
+	if got := w.Header().Get("X-User"); got != alice.Username {
+		t.Errorf("response header rule: got %q", got)
+	}
+	if got := w.Header().Get("Server"); got != "" {
+		t.Errorf("removed response header returned: %q", got)
+	}
+}
+
+func TestPortInvalidHeaderRules(t *testing.T) {
+	loadTestConfig(t, "")
+
+	invalid := newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))
+	invalid.Headers = model.Headers{
+		Response: model.HeaderRules{Set: map[string]string{"X-User": "{{ .Username "}},
+	}
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	if _, err := newPortProxy(context.Background(), invalid, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {}); err == nil {
+		t.Fatal("port created with an invalid header rule")
+	}
+
+	// invalid ports of the configuration aren't started with the proxy
+	provider := newFakeProvider()
+	proxy := startTestProxy(t, provider, &model.Config{
+		Hostname: "web",
+		Ports: model.PortConfigList{
+			"web":   newTestPort(t, "443/https:80/http", newTestTarget(t, "web")),
+			"admin": invalid,
+		},
+	})
+	if _, ok := proxy.Config.Ports["admin"]; ok {
+		t.Error("invalid port kept in the proxy configuration")
+	}
+	if body, err := get(provider.proxy("web").addr("web")); err != nil || body != "web" {
+		t.Errorf("web port: got %q, %v", body, err)
+	}
+
+	// and aren't added to the running proxy
+	if err := proxy.StartPort("admin", invalid); err == nil {
+		t.Fatal("invalid port started")
+	}
+	proxy.mtx.RLock()
+	_, ok := proxy.ports["admin"]
+	proxy.mtx.RUnlock()
+	if ok {
+		t.Error("invalid port added to the proxy")
+	}
+	if _, ok := proxy.Config.Ports["admin"]; ok {
+		t.Error("invalid port added to the proxy configuration")
+	}
+}
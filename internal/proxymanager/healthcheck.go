@@ -0,0 +1,167 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+// healthChecker struct actively probes the backends of a port and takes the
+// unhealthy ones out of the balancer rotation.
+type healthChecker struct {
+	log      zerolog.Logger
+	onChange func(target *backend)
+	client   *http.Client
+	path     *url.URL
+	backends []*backend
+	config   model.HealthCheck
+}
+
+var ErrUnexpectedHealthStatus = errors.New("unexpected health check status")
+
+// newHealthChecker function returns a health checker for the backends.
+func newHealthChecker(log zerolog.Logger, cfg model.HealthCheck, tlsValidate bool,
+	backends []*backend, onChange func(target *backend),
+) (*healthChecker, error) {
+	//
+	cfg = cfg.WithDefaults()
+
+	path, err := url.Parse(cfg.Path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid health check path: %w", err)
+	}
+
+	return &healthChecker{
+		log:      log.With().Str("module", "healthcheck").Logger(),
+		config:   cfg,
+		path:     path,
+		backends: backends,
+		onChange: onChange,
+		client: &http.Client{
+			Transport: newTransport(tlsValidate, true, model.Transport{}),
+			// a redirect is a valid answer from the target
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+	}, nil
+}
+
+// start method starts probing all backends until the context is canceled.
+func (hc *healthChecker) start(ctx context.Context) {
+	for _, target := range hc.backends {
+		go hc.watch(ctx, target)
+	}
+}
+
+// watch method probes a backend on every interval and updates its health
+// after the configured number of consecutive successes or failures.
+func (hc *healthChecker) watch(ctx context.Context, target *backend) {
+	log := hc.log.With().Str("target", target.url.String()).Logger()
+
+	ticker := time.NewTicker(hc.config.Interval)
+	defer ticker.Stop()
+
+	var successes, failures int
+
+	for {
+		err := hc.probe(ctx, target)
+		if err == nil {
+			successes++
+			failures = 0
+		} else {
+			failures++
+			successes = 0
+			log.Debug().Err(err).Int("failures", failures).Msg("health check failed")
+		}
+
+		switch {
+		case successes >= hc.config.HealthyThreshold:
+			if target.setHealth(model.HealthStatusHealthy) {
+				log.Info().Msg("target is healthy")
+				hc.onChange(target)
+			}
+		case failures >= hc.config.UnhealthyThreshold:
+			if target.setHealth(model.HealthStatusUnhealthy) {
+				log.Warn().Err(err).Msg("target is unhealthy")
+				hc.onChange(target)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// probe method runs a single health check against the backend.
+func (hc *healthChecker) probe(ctx context.Context, target *backend) error {
+	ctx, cancel := context.WithTimeout(ctx, hc.config.Timeout)
+	defer cancel()
+
+	if hc.config.Type == model.HealthCheckTCP {
+		var dialer net.Dialer
+
+		conn, err := dialer.DialContext(ctx, "tcp", targetAddress(target.url))
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, target.url.ResolveReference(hc.path).String(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := hc.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if hc.config.ExpectedStatus != 0 {
+		if resp.StatusCode != hc.config.ExpectedStatus {
+			return fmt.Errorf("%w: %d", ErrUnexpectedHealthStatus, resp.StatusCode)
+		}
+		return nil
+	}
+
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusBadRequest {
+		return fmt.Errorf("%w: %d", ErrUnexpectedHealthStatus, resp.StatusCode)
+	}
+
+	return nil
+}
+
+// targetAddress function returns the host:port of a target URL,
+// using the scheme default port if none is defined.
+func targetAddress(target *url.URL) string {
+	if target.Port() != "" {
+		return target.Host
+	}
+
+	port := "80"
+	if target.Scheme == "https" || target.Scheme == model.ProtocolHTTPSH2 {
+		port = "443"
+	}
+
+	return net.JoinHostPort(target.Hostname(), port)
+}
@@ -0,0 +1,156 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// testHealthCheck is a fast health check of the tests.
+var testHealthCheck = model.HealthCheck{
+	Enabled:            true,
+	Path:               "/healthz",
+	Interval:           10 * time.Millisecond,
+	Timeout:            time.Second,
+	HealthyThreshold:   1,
+	UnhealthyThreshold: 2,
+}
+
+// startHealthChecker function starts a health checker of the target and
+// returns the channel of its health changes.
+func startHealthChecker(t *testing.T, cfg model.HealthCheck, target *url.URL) (*backend, chan model.HealthStatus) {
+	t.Helper()
+
+	changes := make(chan model.HealthStatus, 10) //nolint:mnd
+	b := newBalancer(model.LoadBalanceRoundRobin, []*url.URL{target})
+	hc, err := newHealthChecker(zerolog.Nop(), cfg, false, b.backends, func(target *backend) {
+		changes <- target.getHealth()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	hc.start(ctx)
+
+	return b.backends[0], changes
+}
+
+// expectHealth function waits for the next health change and checks it.
+func expectHealth(t *testing.T, changes chan model.HealthStatus, want model.HealthStatus) {
+	t.Helper()
+
+	select {
+	case got := <-changes:
+		if got != want {
+			t.Fatalf("health: got %s, want %s", got.String(), want.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no health change, want %s", want.String())
+	}
+}
+
+func TestHealthCheckHTTP(t *testing.T) {
+	var healthy atomic.Bool
+	healthy.Store(true)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != testHealthCheck.Path || !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, changes := startHealthChecker(t, testHealthCheck, target)
+
+	expectHealth(t, changes, model.HealthStatusHealthy)
+
+	healthy.Store(false)
+	expectHealth(t, changes, model.HealthStatusUnhealthy)
+	if b.getHealth() != model.HealthStatusUnhealthy {
+		t.Error("backend not ejected")
+	}
+
+	healthy.Store(true)
+	expectHealth(t, changes, model.HealthStatusHealthy)
+}
+
+func TestHealthCheckExpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := testHealthCheck
+	cfg.ExpectedStatus = http.StatusNoContent
+	_, changes := startHealthChecker(t, cfg, target)
+
+	expectHealth(t, changes, model.HealthStatusUnhealthy)
+}
+
+func TestHealthCheckTCP(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Close()
+		}
+	}()
+
+	cfg := testHealthCheck
+	cfg.Type = model.HealthCheckTCP
+	_, changes := startHealthChecker(t, cfg, &url.URL{Scheme: "tcp", Host: l.Addr().String()})
+
+	expectHealth(t, changes, model.HealthStatusHealthy)
+
+	l.Close()
+	expectHealth(t, changes, model.HealthStatusUnhealthy)
+}
+
+func TestTargetAddress(t *testing.T) {
+	tests := map[string]string{
+		"http://web":         "web:80",
+		"https://web":        "web:443",
+		"https+h2://web":     "web:443",
+		"http://web:8080":    "web:8080",
+		"tcp://10.0.0.1:543": "10.0.0.1:543",
+	}
+
+	for raw, want := range tests {
+		target, err := url.Parse(raw)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := targetAddress(target); got != want {
+			t.Errorf("%s: got %s, want %s", raw, got, want)
+		}
+	}
+}
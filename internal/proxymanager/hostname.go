@@ -0,0 +1,158 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+)
+
+// maxHostnameSuffix is the last suffix tried by the suffix policy.
+const maxHostnameSuffix = 99
+
+// Conflict struct stores a target that wasn't started because its hostname
+// is used by the proxy of another target.
+type Conflict struct {
+	Config *model.Config
+	// Owner is the target provider and target ID that uses the hostname
+	Owner string
+}
+
+var ErrHostnameConflict = errors.New("hostname already in use")
+
+// GetConflicts method returns the targets not started because of a hostname
+// conflict, sorted by hostname.
+func (pm *ProxyManager) GetConflicts() []Conflict {
+	pm.mtx.RLock()
+	defer pm.mtx.RUnlock()
+
+	conflicts := slices.Collect(maps.Values(pm.conflicts))
+	slices.SortFunc(conflicts, func(a, b Conflict) int {
+		if a.Config.Hostname != b.Config.Hostname {
+			return strings.Compare(a.Config.Hostname, b.Config.Hostname)
+		}
+		return strings.Compare(a.Config.TargetID, b.Config.TargetID)
+	})
+
+	return conflicts
+}
+
+// Error method returns the message of the conflict.
+func (c Conflict) Error() string {
+	return fmt.Sprintf("%s: %s is used by %s", ErrHostnameConflict, c.Config.Hostname, c.Owner)
+}
+
+// Unwrap method returns ErrHostnameConflict.
+func (c Conflict) Unwrap() error {
+	return ErrHostnameConflict
+}
+
+// reserveHostname method reserves the hostname of a new proxy until it's added
+// with addProxy. If the hostname is used by the proxy of another target, the
+// conflict is resolved with the configured policy: the hostname is changed to
+// the first free hostname with a numeric suffix, or the conflict is returned.
+func (pm *ProxyManager) reserveHostname(pcfg *model.Config) error {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+
+	key := targetKey(pcfg)
+	delete(pm.conflicts, key)
+
+	owner, used := pm.hostnameOwner(pcfg.Hostname)
+	if !used || owner == key {
+		pm.reserved[pcfg.Hostname] = key
+		return nil
+	}
+
+	if config.Get().HostnameConflict == config.HostnameConflictSuffix {
+		for i := 2; i <= maxHostnameSuffix; i++ {
+			hostname := fmt.Sprintf("%s-%d", pcfg.Hostname, i)
+			if o, ok := pm.hostnameOwner(hostname); ok && o != key {
+				continue
+			}
+
+			pm.log.Warn().Str("proxy", pcfg.Hostname).Str("hostname", hostname).Str("owner", owner).
+				Msg("Hostname already in use, using suffix")
+
+			pcfg.Hostname = hostname
+			pm.reserved[hostname] = key
+
+			return nil
+		}
+	}
+
+	conflict := Conflict{Config: pcfg, Owner: owner}
+	pm.conflicts[key] = conflict
+
+	return conflict
+}
+
+// releaseHostname method removes the reservation of a hostname.
+func (pm *ProxyManager) releaseHostname(hostname string) {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+
+	delete(pm.reserved, hostname)
+}
+
+// removeConflict method removes the conflict of the target of an event,
+// returning false if the target had no conflict.
+func (pm *ProxyManager) removeConflict(event targetproviders.TargetEvent) bool {
+	pm.mtx.Lock()
+	defer pm.mtx.Unlock()
+
+	for key, c := range pm.conflicts {
+		if c.Config.TargetID == event.ID && pm.TargetProviders[c.Config.TargetProvider] == event.TargetProvider {
+			delete(pm.conflicts, key)
+			return true
+		}
+	}
+
+	return false
+}
+
+// startWaitingTarget method starts the first target, in target order, that
+// wasn't started because its hostname was used by a removed proxy. The other
+// waiting targets keep their conflict with the new owner.
+func (pm *ProxyManager) startWaitingTarget(hostname string) {
+	pm.mtx.RLock()
+	var waiting *model.Config
+	for _, c := range pm.conflicts {
+		if c.Config.Hostname == hostname && (waiting == nil || targetKey(c.Config) < targetKey(waiting)) {
+			waiting = c.Config
+		}
+	}
+	pm.mtx.RUnlock()
+
+	if waiting == nil {
+		return
+	}
+
+	pm.log.Info().Str("proxy", hostname).Str("target", targetKey(waiting)).Msg("Hostname released, starting waiting target")
+	pm.newAndStartProxy(hostname, waiting)
+}
+
+// hostnameOwner method returns the target that uses or reserved a hostname.
+// The caller must hold the lock.
+func (pm *ProxyManager) hostnameOwner(hostname string) (string, bool) {
+	if p, ok := pm.Proxies[hostname]; ok {
+		return targetKey(p.Config), true
+	}
+
+	owner, ok := pm.reserved[hostname]
+
+	return owner, ok
+}
+
+// targetKey function returns the target provider and target ID of a proxy.
+func targetKey(pcfg *model.Config) string {
+	return pcfg.TargetProvider + "/" + pcfg.TargetID
+}
@@ -0,0 +1,168 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+)
+
+// fakeTargetProvider struct is a target provider without events, its targets
+// are added by the tests.
+type fakeTargetProvider struct{}
+
+var _ targetproviders.TargetProvider = fakeTargetProvider{}
+
+func (fakeTargetProvider) WatchEvents(context.Context, chan targetproviders.TargetEvent, chan error) {
+}
+
+func (fakeTargetProvider) GetDefaultProxyProviderName() string {
+	return "fake"
+}
+
+func (fakeTargetProvider) Close() {}
+
+func (fakeTargetProvider) AddTarget(string) (*model.Config, error) {
+	return nil, errors.ErrUnsupported
+}
+
+func (fakeTargetProvider) RemoveTarget(string) {}
+
+func (fakeTargetProvider) DeleteProxy(string) error {
+	return nil
+}
+
+// newTestManager function returns a proxy manager with the fake proxy provider
+// and the docker and lists fake target providers.
+func newTestManager(t *testing.T) *ProxyManager {
+	t.Helper()
+
+	pm := NewProxyManager(zerolog.Nop(), nil)
+	pm.ProxyProviders["fake"] = newFakeProvider()
+	pm.TargetProviders["docker"] = fakeTargetProvider{}
+	pm.TargetProviders["lists"] = fakeTargetProvider{}
+	t.Cleanup(pm.StopAllProxies)
+
+	return pm
+}
+
+// startTarget function starts the proxy of a target with a port.
+func startTarget(t *testing.T, pm *ProxyManager, provider, id, hostname string) {
+	t.Helper()
+
+	pcfg := targetConfig(provider, id, hostname)
+	pcfg.ProxyProvider = "fake"
+	pcfg.Ports = model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, id))}
+	pm.newAndStartProxy(hostname, pcfg)
+}
+
+// targetConfig function returns the proxy configuration of a target.
+func targetConfig(provider, id, hostname string) *model.Config {
+	return &model.Config{TargetProvider: provider, TargetID: id, Hostname: hostname}
+}
+
+func TestReserveHostnameError(t *testing.T) {
+	loadTestConfig(t, "hostnameConflict: error\n")
+
+	pm := NewProxyManager(zerolog.Nop(), nil)
+
+	if err := pm.reserveHostname(targetConfig("docker", "a", "web")); err != nil {
+		t.Fatal(err)
+	}
+	// the same target can reserve its hostname again
+	if err := pm.reserveHostname(targetConfig("docker", "a", "web")); err != nil {
+		t.Errorf("same target: %v", err)
+	}
+
+	err := pm.reserveHostname(targetConfig("lists", "b", "web"))
+	var conflict Conflict
+	if !errors.As(err, &conflict) || !errors.Is(err, ErrHostnameConflict) {
+		t.Fatalf("conflict: got %v", err)
+	}
+	if conflict.Owner != "docker/a" {
+		t.Errorf("owner: got %s", conflict.Owner)
+	}
+	if conflicts := pm.GetConflicts(); len(conflicts) != 1 || conflicts[0].Config.TargetID != "b" {
+		t.Errorf("conflicts: got %+v", conflicts)
+	}
+
+	// the hostname is free once released
+	pm.releaseHostname("web")
+	if err := pm.reserveHostname(targetConfig("lists", "b", "web")); err != nil {
+		t.Errorf("released hostname: %v", err)
+	}
+	if conflicts := pm.GetConflicts(); len(conflicts) != 0 {
+		t.Errorf("conflicts after reserving: got %+v", conflicts)
+	}
+}
+
+func TestReserveHostnameSuffix(t *testing.T) {
+	loadTestConfig(t, "hostnameConflict: suffix\n")
+
+	pm := NewProxyManager(zerolog.Nop(), nil)
+	pm.Proxies["web"] = &Proxy{Config: targetConfig("docker", "a", "web")}
+
+	for _, id := range []string{"b", "c"} {
+		pcfg := targetConfig("lists", id, "web")
+		if err := pm.reserveHostname(pcfg); err != nil {
+			t.Fatal(err)
+		}
+		if want := map[string]string{"b": "web-2", "c": "web-3"}[id]; pcfg.Hostname != want {
+			t.Errorf("hostname of %s: got %s, want %s", id, pcfg.Hostname, want)
+		}
+	}
+
+	// the proxy of the owner keeps its hostname
+	if err := pm.reserveHostname(targetConfig("docker", "a", "web")); err != nil {
+		t.Errorf("owner: %v", err)
+	}
+}
+
+func TestConflictStartedWhenReleased(t *testing.T) {
+	loadTestConfig(t, "hostnameConflict: error\n")
+
+	pm := newTestManager(t)
+	startTarget(t, pm, "docker", "a", "web")
+	startTarget(t, pm, "lists", "b", "web")
+	if conflicts := pm.GetConflicts(); len(conflicts) != 1 {
+		t.Fatalf("conflicts: got %+v", conflicts)
+	}
+
+	// the waiting target gets the hostname when the owner stops
+	pm.eventStop(targetproviders.TargetEvent{ID: "a", TargetProvider: pm.TargetProviders["docker"]})
+
+	p, ok := pm.GetProxy("web")
+	if !ok || targetKey(p.Config) != "lists/b" {
+		t.Fatalf("proxy of the hostname: got %v", ok)
+	}
+	if conflicts := pm.GetConflicts(); len(conflicts) != 0 {
+		t.Errorf("conflicts after the owner stopped: got %+v", conflicts)
+	}
+}
+
+func TestSuffixMaintenance(t *testing.T) {
+	loadTestConfig(t, "hostnameConflict: suffix\n")
+
+	pm := newTestManager(t)
+	startTarget(t, pm, "docker", "a", "web")
+	if err := pm.SetMaintenance("web", true); err != nil {
+		t.Fatal(err)
+	}
+	startTarget(t, pm, "lists", "b", "web")
+
+	// the suffixed proxy doesn't get the maintenance mode of the owner
+	p, ok := pm.GetProxy("web-2")
+	if !ok {
+		t.Fatal("suffixed proxy not started")
+	}
+	if p.IsMaintenance() {
+		t.Error("suffixed proxy in maintenance mode of the owner")
+	}
+}
@@ -5,7 +5,6 @@ package proxymanager
 
 import (
 	"context"
-	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
@@ -13,87 +12,235 @@ import (
 	"net/http/httputil"
 	"sync"
 
-	"github.com/xybydy/tsdproxy/internal/consts"
+	"github.com/xybydy/tsdproxy/internal/accesslog"
 	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/metrics"
 	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/tracing"
 
 	"github.com/rs/zerolog"
 )
 
 type port struct {
-	log        zerolog.Logger
-	ctx        context.Context
-	listener   net.Listener
-	cancel     context.CancelFunc
-	httpServer *http.Server
-	mtx        sync.Mutex
+	log           zerolog.Logger
+	ctx           context.Context
+	listener      net.Listener
+	cancel        context.CancelFunc
+	httpServer    *http.Server
+	stream        *streamServer
+	balancer      *balancer
+	healthChecker *healthChecker
+	funnel        *funnelGate
+	cache         *responseCache
+	routes        []*route
+	mtx           sync.Mutex
 }
 
 func newPortProxy(
 	ctx context.Context,
 	pconfig model.PortConfig,
 	log zerolog.Logger,
-	accessLog bool,
+	proxyConfig *model.Config,
 	whoisFunc func(next http.Handler) http.Handler,
-) *port {
+	proxyLimiter *rateLimiter,
+	errPages *errorPages,
+	accessLog *accesslog.Logger,
+	onHealthChange func(target *backend),
+) (*port, error) {
 	//
 	log = log.With().Str("port", pconfig.String()).Logger()
 
+	requestHeaders, err := newHeaderRules(pconfig.Headers.Request)
+	if err != nil {
+		return nil, fmt.Errorf("error in request header rules: %w", err)
+	}
+	responseHeaders, err := newHeaderRules(pconfig.Headers.Response)
+	if err != nil {
+		return nil, fmt.Errorf("error in response header rules: %w", err)
+	}
+
 	ctxPort, cancel := context.WithCancel(ctx)
 
+	lb := newBalancer(pconfig.LoadBalance, pconfig.GetTargets())
+	hc := newPortHealthChecker(log, pconfig, lb, onHealthChange)
+
+	settings := portTransport(pconfig)
+
 	// Create the reverse proxy
 	//
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: !pconfig.TLSValidate}, //nolint
-	}
 	reverseProxy := &httputil.ReverseProxy{
-		Transport: tr,
+		Transport: newTransport(pconfig.TLSValidate, false, settings),
+		// flush streamed responses, like gRPC streams, immediately
+		FlushInterval: -1,
+		ErrorHandler:  errPages.proxyErrorHandler,
 		Rewrite: func(r *httputil.ProxyRequest) {
-			r.SetURL(pconfig.GetFirstTarget())
+			if target, ok := backendFromContext(r.In.Context()); ok {
+				r.SetURL(target.url)
+				tracing.InjectRequest(r.In, r.Out, target.url.String())
+			}
 			r.Out.Host = r.In.Host
 			r.Out.Header["X-Forwarded-For"] = r.In.Header["X-Forwarded-For"]
 
-			if user, ok := model.WhoisFromContext(r.In.Context()); ok {
-				r.Out.Header.Set(consts.HeaderUsername, user.Username)
-				r.Out.Header.Set(consts.HeaderDisplayName, user.DisplayName)
-				r.Out.Header.Set(consts.HeaderProfilePicURL, user.ProfilePicURL)
+			user, ok := model.WhoisFromContext(r.In.Context())
+			if ok {
+				setWhoisHeaders(r.Out.Header, user)
 			}
 
 			r.SetXForwarded()
+
+			// header rules run last, so they can override the default headers
+			requestHeaders.apply(r.Out.Header, user)
 		},
 	}
+	if pconfig.Headers.Response.IsEmpty() {
+		responseHeaders = nil
+	}
+	reverseProxy.ModifyResponse = func(resp *http.Response) error {
+		// the cache stores the headers of the target, without the response rules
+		saveTargetHeader(resp)
+		if responseHeaders != nil {
+			user, _ := model.WhoisFromContext(resp.Request.Context())
+			responseHeaders.apply(resp.Header, user)
+		}
+		return nil
+	}
 
-	handler := whoisFunc(reverseProxy)
-	// add logger to proxy
-	if accessLog {
-		handler = core.LoggerMiddleware(log, handler)
+	handler := lb.middleware(reverseProxy)
+	// ports with only routes don't have a default target
+	if len(pconfig.GetTargets()) == 0 {
+		handler = http.NotFoundHandler()
+	}
+	// add path routes to proxy
+	routes := newRoutes(log, pconfig, reverseProxy, onHealthChange)
+	handler = routerMiddleware(routes, handler)
+	// add response cache to proxy, after access control to serve only allowed requests
+	cache := newResponseCache(log, proxyConfig.Hostname, pconfig, requestHeaders, responseHeaders)
+	if cache != nil {
+		handler = cache.middleware(handler)
+	}
+	// add access control to proxy, Funnel clients authenticated by the Funnel gate are allowed
+	funnel := newFunnelGate(log, proxyConfig.Hostname, pconfig.String(), pconfig)
+	funnelAuth := funnel != nil && funnel.authenticates()
+	if ac := newAccessControl(log, proxyConfig.Hostname, funnelAuth, proxyConfig.AccessControl, pconfig.AccessControl); ac != nil {
+		handler = ac.middleware(handler)
+	}
+	// add rate limits of the process, the proxy and the port
+	limiters := make([]*rateLimiter, 0, 2) //nolint:mnd
+	for _, rl := range []*rateLimiter{proxyLimiter, newRateLimiter(pconfig.RateLimit)} {
+		if rl != nil {
+			limiters = append(limiters, rl)
+		}
+	}
+	handler = rateLimitMiddleware(log, proxyConfig.Hostname, pconfig.String(), limiters, handler)
+	// add Funnel protections to proxy
+	if funnel != nil {
+		handler = funnel.middleware(handler)
 	}
+	// add error and maintenance pages to proxy
+	handler = errPages.middleware(handler)
+	// add access log to proxy, after whois to log the user
+	if accessLog != nil {
+		handler = accessLog.Middleware(pconfig.String(), handler)
+	}
+	handler = whoisFunc(handler)
+	// add metrics to proxy
+	handler = metrics.Middleware(proxyConfig.Hostname, pconfig.String(), handler)
+	// add tracing to proxy
+	handler = tracing.Middleware(proxyConfig.Hostname, pconfig.String(), handler)
 
 	// main http Server
 	httpServer := &http.Server{
 		Handler:           handler,
-		ReadHeaderTimeout: core.ReadHeaderTimeout,
+		ReadHeaderTimeout: max(settings.ReadHeaderTimeout, 0),
+		ReadTimeout:       max(settings.ReadTimeout, 0),
+		WriteTimeout:      max(settings.WriteTimeout, 0),
 		BaseContext:       func(net.Listener) context.Context { return ctxPort },
 	}
 
 	return &port{
-		log:        log,
-		ctx:        ctxPort,
-		cancel:     cancel,
-		httpServer: httpServer,
+		log:           log,
+		ctx:           ctxPort,
+		cancel:        cancel,
+		httpServer:    httpServer,
+		balancer:      lb,
+		healthChecker: hc,
+		funnel:        funnel,
+		cache:         cache,
+		routes:        routes,
+	}, nil
+}
+
+func newPortStream(
+	ctx context.Context,
+	pconfig model.PortConfig,
+	log zerolog.Logger,
+	proxyConfig *model.Config,
+	whois func(ctx context.Context, conn net.Conn) model.Whois,
+	onHealthChange func(target *backend),
+) *port {
+	//
+	log = log.With().Str("port", pconfig.String()).Logger()
+
+	ctxPort, cancel := context.WithCancel(ctx)
+
+	lb := newBalancer(pconfig.LoadBalance, pconfig.GetTargets())
+	hc := newPortHealthChecker(log, pconfig, lb, onHealthChange)
+
+	stream := newStreamServer(log, pconfig, proxyConfig.Hostname, lb, accesslog.IsEnabled(proxyConfig.AccessLog))
+	// connections are checked with the identity of the client
+	if ac := newAccessControl(log, proxyConfig.Hostname, false, proxyConfig.AccessControl, pconfig.AccessControl); ac != nil {
+		stream.access = ac
+		stream.whois = whois
+	}
+
+	return &port{
+		log:           log,
+		ctx:           ctxPort,
+		cancel:        cancel,
+		stream:        stream,
+		balancer:      lb,
+		healthChecker: hc,
+	}
+}
+
+// newPortHealthChecker function returns the health checker of the port balancer,
+// or nil if health checks are disabled.
+func newPortHealthChecker(log zerolog.Logger, pconfig model.PortConfig, lb *balancer, onHealthChange func(target *backend)) *healthChecker {
+	if !pconfig.HealthCheck.Enabled {
+		return nil
 	}
+
+	hc, err := newHealthChecker(log, pconfig.HealthCheck, pconfig.TLSValidate, lb.backends, onHealthChange)
+	if err != nil {
+		log.Error().Err(err).Msg("health checks disabled")
+		return nil
+	}
+
+	return hc
 }
 
-func newPortRedirect(ctx context.Context, pconfig model.PortConfig, log zerolog.Logger) *port {
+func newPortRedirect(
+	ctx context.Context,
+	pconfig model.PortConfig,
+	log zerolog.Logger,
+	proxyConfig *model.Config,
+	whoisFunc func(next http.Handler) http.Handler,
+) *port {
+	//
 	log = log.With().Str("port", pconfig.String()).Logger()
 
 	ctxPort, cancel := context.WithCancel(ctx)
 
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, pconfig.GetFirstTarget().String(), http.StatusMovedPermanently)
+	})
+	if ac := newAccessControl(log, proxyConfig.Hostname, false, proxyConfig.AccessControl, pconfig.AccessControl); ac != nil {
+		handler = whoisFunc(ac.middleware(handler))
+	}
+
 	redirectHTTPServer := &http.Server{
 		ReadHeaderTimeout: core.ReadHeaderTimeout,
-		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			http.Redirect(w, r, pconfig.GetFirstTarget().String(), http.StatusMovedPermanently)
-		}),
+		Handler:           handler,
 	}
 
 	return &port{
@@ -109,7 +256,21 @@ func (p *port) startWithListener(l net.Listener) error {
 	p.listener = l
 	p.mtx.Unlock()
 
-	err := p.httpServer.Serve(l)
+	if p.healthChecker != nil {
+		p.healthChecker.start(p.ctx)
+	}
+	for _, rt := range p.routes {
+		if rt.healthChecker != nil {
+			rt.healthChecker.start(p.ctx)
+		}
+	}
+
+	var err error
+	if p.stream != nil {
+		err = p.stream.serve(p.ctx, l)
+	} else {
+		err = p.httpServer.Serve(l)
+	}
 	defer p.log.Info().Msg("Terminating server")
 
 	if err != nil && !errors.Is(err, net.ErrClosed) && !errors.Is(err, http.ErrServerClosed) {
@@ -118,6 +279,43 @@ func (p *port) startWithListener(l net.Listener) error {
 	return nil
 }
 
+// isDegraded method returns true if any target of the port or its routes is unhealthy.
+func (p *port) isDegraded() bool {
+	if p.balancer != nil && p.balancer.isDegraded() {
+		return true
+	}
+
+	for _, rt := range p.routes {
+		if rt.balancer.isDegraded() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// connections method returns the number of active connections of stream ports.
+func (p *port) connections() int64 {
+	if p.stream == nil {
+		return 0
+	}
+	return p.stream.connections()
+}
+
+// targetsHealth method returns the health of the port and routes targets.
+func (p *port) targetsHealth() []model.TargetHealth {
+	if p.balancer == nil {
+		return nil
+	}
+
+	health := p.balancer.health()
+	for _, rt := range p.routes {
+		health = append(health, rt.balancer.health()...)
+	}
+
+	return health
+}
+
 func (p *port) close() error {
 	var errs error
 
@@ -125,8 +323,25 @@ func (p *port) close() error {
 		errs = errors.Join(errs, p.httpServer.Shutdown(p.ctx))
 	}
 
-	if p.listener != nil {
-		errs = errors.Join(errs, p.listener.Close())
+	// the listener is set by startWithListener, which can run while a
+	// stopped port is closed
+	p.mtx.Lock()
+	listener := p.listener
+	p.mtx.Unlock()
+
+	// the listener is already closed if the HTTP server was serving it
+	if listener != nil {
+		if err := listener.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+	}
+
+	if p.stream != nil {
+		errs = errors.Join(errs, p.stream.close())
+	}
+
+	if p.cache != nil {
+		p.cache.close()
 	}
 
 	p.cancel()
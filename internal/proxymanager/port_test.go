@@ -0,0 +1,173 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/coder/websocket"
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/accesslog"
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// testConfig is the minimal server configuration of the tests, with the
+// Tailscale data directory.
+const testConfig = `
+tailscale:
+  dataDir: %s
+  providers:
+    default: {}
+`
+
+// syncBuffer struct is a buffer written and read by several goroutines.
+type syncBuffer struct {
+	buf bytes.Buffer
+	mtx sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mtx.Lock()
+	defer b.mtx.Unlock()
+
+	return b.buf.String()
+}
+
+// loadTestConfig function loads the configuration of the tests, with the yaml
+// added to the minimal configuration.
+func loadTestConfig(t *testing.T, yaml string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "tsdproxy.yaml")
+	if err := os.WriteFile(file, []byte(fmt.Sprintf(testConfig, dir)+yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := config.LoadFile(file); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// newTestPort function returns a port of the label, with targets replaced by
+// target.
+func newTestPort(t *testing.T, label string, target *url.URL) model.PortConfig {
+	t.Helper()
+
+	pconfig, err := model.NewPortLongLabel(label)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pconfig.ReplaceTarget(pconfig.GetFirstTarget(), target)
+
+	return pconfig
+}
+
+// testWhois function returns a middleware that adds the identity to the
+// requests, like ProviderUserMiddleware.
+func testWhois(who model.Whois) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			next.ServeHTTP(w, r.WithContext(model.WhoisNewContext(r.Context(), who)))
+		})
+	}
+}
+
+func TestPortWebSocket(t *testing.T) {
+	loadTestConfig(t, "")
+
+	echo := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := websocket.Accept(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.CloseNow()
+
+		for {
+			typ, data, err := conn.Read(r.Context())
+			if err != nil {
+				return
+			}
+			if err := conn.Write(r.Context(), typ, data); err != nil {
+				return
+			}
+		}
+	}))
+	t.Cleanup(echo.Close)
+
+	target, err := url.Parse(echo.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pconfig := newTestPort(t, "443/https:80/http", target)
+	pconfig.Cache = model.Cache{Enabled: true}
+	pconfig.RateLimit = model.RateLimit{Requests: 10, Period: time.Second}
+
+	proxyConfig := &model.Config{Hostname: "echo"}
+	var logs syncBuffer
+	accessLog := accesslog.NewLog(zerolog.New(&logs), proxyConfig.Hostname)
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		accessLog, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(p.httpServer.Handler)
+	t.Cleanup(srv.Close)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols {
+		t.Errorf("status: got %d", resp.StatusCode)
+	}
+
+	if err := conn.Write(ctx, websocket.MessageText, []byte("ping")); err != nil {
+		t.Fatal(err)
+	}
+	_, data, err := conn.Read(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "ping" {
+		t.Errorf("echo: got %q", data)
+	}
+	conn.Close(websocket.StatusNormalClosure, "")
+
+	// the request is logged when the upgraded connection is closed
+	deadline := time.Now().Add(5 * time.Second)
+	for !strings.Contains(logs.String(), `"status":101`) {
+		if time.Now().After(deadline) {
+			t.Fatalf("upgraded connection not logged with status 101: %s", logs.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if !strings.Contains(logs.String(), alice.Username) {
+		t.Errorf("user not logged: %s", logs.String())
+	}
+}
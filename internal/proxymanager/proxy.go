@@ -7,11 +7,17 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"maps"
 	"net"
 	"net/http"
 	"net/url"
+	"slices"
+	"strconv"
 	"sync"
+	"time"
 
+	"github.com/xybydy/tsdproxy/internal/accesslog"
+	"github.com/xybydy/tsdproxy/internal/metrics"
 	"github.com/xybydy/tsdproxy/internal/model"
 	"github.com/xybydy/tsdproxy/internal/proxyproviders"
 
@@ -19,6 +25,13 @@ import (
 )
 
 type (
+	// ShareLink struct is a one-time link that opens a session on a Funnel port.
+	ShareLink struct {
+		Expires time.Time
+		URL     string
+		Port    string
+	}
+
 	// Proxy struct is a struct that contains all the information needed to run a proxy.
 	Proxy struct {
 		onUpdate func(event model.ProxyEvent)
@@ -27,14 +40,33 @@ type (
 		ctx           context.Context
 		providerProxy proxyproviders.ProxyInterface
 		Config        *model.Config
+		proxyProvider string
 		URL           *url.URL
 		cancel        context.CancelFunc
 		ports         map[string]*port
+		rateLimiter   *rateLimiter
+		errorPages    *errorPages
+		accessLog     *accesslog.Logger
+		lastError     string
+		restarts      int
 		mtx           sync.RWMutex
 		status        model.ProxyStatus
+		maintenance   bool
 	}
 )
 
+var (
+	ErrProxyNotRunning   = errors.New("proxy is not running")
+	ErrPortAlreadyExists = errors.New("port already exists")
+	ErrPortNotFound      = errors.New("port not found")
+	ErrNoPorts           = errors.New("no ports configured")
+
+	ErrShareLinksDisabled    = errors.New("share links not enabled on the funnel port")
+	ErrShareLinkPortRequired = errors.New("more than one port with share links, port is required")
+
+	ErrCacheDisabled = errors.New("cache not enabled on the port")
+)
+
 // NewProxy function is a function that creates a new proxy.
 func NewProxy(log zerolog.Logger,
 	pcfg *model.Config,
@@ -69,13 +101,33 @@ func NewProxy(log zerolog.Logger,
 		cancel:        cancel,
 		providerProxy: pProvider,
 		ports:         make(map[string]*port),
+		rateLimiter:   newRateLimiter(pcfg.RateLimit),
 	}
+	p.errorPages = newErrorPages(log, pcfg, p.IsMaintenance)
+	p.accessLog = newProxyAccessLog(log, pcfg)
 
 	p.initPorts()
 
 	return p, nil
 }
 
+// newProxyAccessLog function returns the access logger of the proxy, or nil if
+// the access log is disabled. Requests are written to the TSDProxy log if the
+// configured output can't be opened.
+func newProxyAccessLog(log zerolog.Logger, pcfg *model.Config) *accesslog.Logger {
+	if !accesslog.IsEnabled(pcfg.AccessLog) {
+		return nil
+	}
+
+	al, err := accesslog.New(log, pcfg.Hostname, pcfg.AccessLog)
+	if err != nil {
+		log.Error().Err(err).Msg("access log written to the TSDProxy log")
+		return accesslog.NewLog(log, pcfg.Hostname)
+	}
+
+	return al
+}
+
 func (proxy *Proxy) Start() {
 	go func() {
 		go proxy.start()
@@ -86,6 +138,10 @@ func (proxy *Proxy) Start() {
 					// Channel closed, exit goroutine
 					return
 				}
+				if event.Status == model.ProxyStatusError {
+					proxy.setError(errors.New(event.Error)) //nolint:err113
+					continue
+				}
 				proxy.setStatus(event.Status)
 			case <-proxy.ctx.Done():
 				// Context canceled, exit goroutine
@@ -115,6 +171,32 @@ func (proxy *Proxy) GetStatus() model.ProxyStatus {
 	return proxy.status
 }
 
+// IsMaintenance method returns true if the proxy is in maintenance mode.
+func (proxy *Proxy) IsMaintenance() bool {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	return proxy.maintenance
+}
+
+// SetMaintenance method enables or disables the maintenance mode. In
+// maintenance mode the HTTP ports serve the maintenance page instead of the
+// targets, while the proxy keeps running.
+func (proxy *Proxy) SetMaintenance(enabled bool) {
+	proxy.mtx.Lock()
+	defer proxy.mtx.Unlock()
+
+	proxy.maintenance = enabled
+}
+
+// GetError method returns the reason of the last error of the proxy.
+func (proxy *Proxy) GetError() string {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	return proxy.lastError
+}
+
 func (proxy *Proxy) GetURL() string {
 	return proxy.providerProxy.GetURL()
 }
@@ -128,22 +210,119 @@ func (proxy *Proxy) ProviderUserMiddleware(next http.Handler) http.Handler {
 		who := proxy.providerProxy.Whois(r)
 
 		ctx := model.WhoisNewContext(r.Context(), who)
+		r = r.WithContext(ctx)
 
-		next.ServeHTTP(w, r.WithContext(ctx))
+		// use the address of the internet client, instead of the Funnel relay
+		if addr, ok := model.FunnelClientFromContext(ctx); ok {
+			r.RemoteAddr = addr
+		}
+
+		next.ServeHTTP(w, r)
 	})
 }
 
+// connWhois method returns the identity of the client of a stream connection.
+func (proxy *Proxy) connWhois(ctx context.Context, conn net.Conn) model.Whois {
+	r := &http.Request{RemoteAddr: conn.RemoteAddr().String()}
+
+	return proxy.providerProxy.Whois(r.WithContext(proxy.providerProxy.ConnContext(ctx, conn)))
+}
+
+// CreateShareLink method creates a one-time share link of a Funnel port. The
+// port can be empty if only one port has share links enabled.
+func (proxy *Proxy) CreateShareLink(portName string) (ShareLink, error) {
+	if portName == "" {
+		for name, pconfig := range proxy.Config.Ports {
+			if !pconfig.Tailscale.Funnel || !pconfig.Tailscale.Protection.ShareLinks {
+				continue
+			}
+			if portName != "" {
+				return ShareLink{}, ErrShareLinkPortRequired
+			}
+			portName = name
+		}
+		if portName == "" {
+			return ShareLink{}, ErrShareLinksDisabled
+		}
+	}
+
+	pconfig, ok := proxy.Config.Ports[portName]
+	if !ok {
+		return ShareLink{}, ErrPortNotFound
+	}
+
+	proxy.mtx.RLock()
+	p, ok := proxy.ports[portName]
+	proxy.mtx.RUnlock()
+	if !ok {
+		return ShareLink{}, ErrPortNotFound
+	}
+	if p.funnel == nil || !p.funnel.allowLinks {
+		return ShareLink{}, ErrShareLinksDisabled
+	}
+
+	token, expires := p.funnel.createShareLink()
+
+	u := proxy.GetURL()
+	if pconfig.ProxyPort != defaultHTTPSPort {
+		u += ":" + strconv.Itoa(pconfig.ProxyPort)
+	}
+
+	return ShareLink{
+		URL:     u + "/?" + url.Values{funnelShareParam: {token}}.Encode(),
+		Port:    portName,
+		Expires: expires,
+	}, nil
+}
+
+// PurgeCache method removes the cached responses with a path starting with
+// path, of a port or of all ports if portName is empty. Returns the number of
+// responses removed.
+func (proxy *Proxy) PurgeCache(portName, path string) (int, error) {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	if portName != "" {
+		p, ok := proxy.ports[portName]
+		if !ok {
+			return 0, ErrPortNotFound
+		}
+		if p.cache == nil {
+			return 0, ErrCacheDisabled
+		}
+		return p.cache.purge(path), nil
+	}
+
+	purged := 0
+	enabled := false
+	for _, p := range proxy.ports {
+		if p.cache != nil {
+			enabled = true
+			purged += p.cache.purge(path)
+		}
+	}
+	if !enabled {
+		return 0, ErrCacheDisabled
+	}
+
+	return purged, nil
+}
+
 func (proxy *Proxy) initPorts() {
-	var newPort *port
 	for k, v := range proxy.Config.Ports {
-		log := proxy.log.With().Str("port", k).Logger()
-		if v.IsRedirect {
-			newPort = newPortRedirect(proxy.ctx, v, log)
-		} else {
-			newPort = newPortProxy(proxy.ctx, v, log, proxy.Config.ProxyAccessLog, proxy.ProviderUserMiddleware)
-		}
+		newPort, err := proxy.newPort(k, v)
+		if err != nil {
+			// invalid ports aren't started by the proxy provider
+			proxy.log.Error().Err(err).Str("port", k).Msg("error creating port")
 
-		proxy.log.Debug().Any("port", newPort).Msg("newport")
+			proxy.mtx.Lock()
+			ports := maps.Clone(proxy.Config.Ports)
+			delete(ports, k)
+			proxy.Config.Ports = ports
+			proxy.mtx.Unlock()
+
+			continue
+		}
 
 		proxy.mtx.Lock()
 		proxy.ports[k] = newPort
@@ -151,6 +330,127 @@ func (proxy *Proxy) initPorts() {
 	}
 }
 
+// newPort method creates a port of the proxy.
+func (proxy *Proxy) newPort(name string, pconfig model.PortConfig) (*port, error) {
+	var newPort *port
+	var err error
+
+	log := proxy.log.With().Str("port", name).Logger()
+	switch {
+	case pconfig.IsRedirect:
+		newPort = newPortRedirect(proxy.ctx, pconfig, log, proxy.Config, proxy.ProviderUserMiddleware)
+	case pconfig.IsStream():
+		newPort = newPortStream(proxy.ctx, pconfig, log, proxy.Config, proxy.connWhois, proxy.onTargetHealthChange(name))
+	default:
+		newPort, err = newPortProxy(proxy.ctx, pconfig, log, proxy.Config, proxy.ProviderUserMiddleware,
+			proxy.rateLimiter, proxy.errorPages, proxy.accessLog, proxy.onTargetHealthChange(name))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if newPort.httpServer != nil {
+		newPort.httpServer.ConnContext = proxy.providerProxy.ConnContext
+	}
+
+	proxy.log.Debug().Any("port", newPort).Msg("newport")
+
+	return newPort, nil
+}
+
+// StartPort method adds a port to the running proxy and starts listening on
+// the proxy provider, without restarting the other ports.
+func (proxy *Proxy) StartPort(name string, pconfig model.PortConfig) error {
+	if status := proxy.GetStatus(); status != model.ProxyStatusRunning && status != model.ProxyStatusDegraded {
+		return ErrProxyNotRunning
+	}
+
+	proxy.mtx.RLock()
+	_, exists := proxy.ports[name]
+	proxy.mtx.RUnlock()
+	if exists {
+		return ErrPortAlreadyExists
+	}
+
+	// invalid ports aren't added to the proxy
+	newPort, err := proxy.newPort(name, pconfig)
+	if err != nil {
+		return err
+	}
+
+	proxy.mtx.Lock()
+	if _, ok := proxy.ports[name]; ok {
+		proxy.mtx.Unlock()
+		return errors.Join(ErrPortAlreadyExists, newPort.close())
+	}
+	// the proxy provider gets the port configuration from the proxy config
+	ports := maps.Clone(proxy.Config.Ports)
+	ports[name] = pconfig
+	proxy.Config.Ports = ports
+	proxy.ports[name] = newPort
+	proxy.mtx.Unlock()
+
+	proxy.log.Info().Str("port", name).Msg("starting port")
+
+	l, err := proxy.providerProxy.GetListener(name)
+	if err != nil {
+		// the port isn't kept, so it can be started again
+		proxy.removePort(name)
+		return errors.Join(fmt.Errorf("error adding listener: %w", err), newPort.close())
+	}
+
+	proxy.startPort(name, l)
+	proxy.notifyPortChange(name)
+
+	return nil
+}
+
+// StopPort method closes a port of the running proxy.
+func (proxy *Proxy) StopPort(name string) error {
+	p, ok := proxy.removePort(name)
+	if !ok {
+		return ErrPortNotFound
+	}
+
+	proxy.log.Info().Str("port", name).Msg("stopping port")
+
+	err := p.close()
+	proxy.notifyPortChange(name)
+
+	return err
+}
+
+// removePort method removes a port from the ports and the configuration of
+// the proxy, returning false if the port doesn't exist. The port isn't closed.
+func (proxy *Proxy) removePort(name string) (*port, bool) {
+	proxy.mtx.Lock()
+	defer proxy.mtx.Unlock()
+
+	p, ok := proxy.ports[name]
+	if !ok {
+		return nil, false
+	}
+
+	delete(proxy.ports, name)
+
+	ports := maps.Clone(proxy.Config.Ports)
+	delete(ports, name)
+	proxy.Config.Ports = ports
+
+	return p, true
+}
+
+// notifyPortChange method sends a proxy event to update the ports of the proxy.
+func (proxy *Proxy) notifyPortChange(name string) {
+	if proxy.onUpdate != nil {
+		proxy.onUpdate(model.ProxyEvent{
+			ID:     proxy.Config.Hostname,
+			Port:   name,
+			Status: proxy.GetStatus(),
+		})
+	}
+}
+
 // Start method is a method that starts the proxy.
 func (proxy *Proxy) start() {
 	proxy.log.Info().Msg("starting proxy")
@@ -162,14 +462,19 @@ func (proxy *Proxy) start() {
 
 	if portsCount == 0 {
 		proxy.log.Warn().Msg("No ports configured")
-		proxy.setStatus(model.ProxyStatusError)
+		proxy.setError(ErrNoPorts)
 
 		return
 	}
 
 	if err := proxy.providerProxy.Start(proxy.ctx); err != nil {
 		proxy.log.Error().Err(err).Msg("Error starting with proxy provider")
-		proxy.Close()
+
+		// release the proxy resources, keeping the error status to be restarted
+		proxy.cancel()
+		proxy.close()
+		proxy.setError(err)
+
 		return
 	}
 
@@ -198,7 +503,7 @@ func (proxy *Proxy) startPort(name string, l net.Listener) {
 		go func() {
 			if err := p.startWithListener(l); err != nil {
 				proxy.log.Error().Err(err).Msg("error starting port")
-				proxy.setStatus(model.ProxyStatusError)
+				proxy.setError(fmt.Errorf("port %s: %w", name, err))
 			}
 		}()
 	}
@@ -209,12 +514,18 @@ func (proxy *Proxy) close() {
 	var errs error
 	proxy.log.Info().Str("name", proxy.Config.Hostname).Msg("stopping proxy")
 
-	for _, p := range proxy.ports {
+	// ports are closed without the lock, they can be changed by StartPort and StopPort
+	proxy.mtx.RLock()
+	ports := slices.Collect(maps.Values(proxy.ports))
+	proxy.mtx.RUnlock()
+
+	for _, p := range ports {
 		errs = errors.Join(errs, p.close())
 	}
 	if proxy.providerProxy != nil {
-		errs = errors.Join(proxy.providerProxy.Close())
+		errs = errors.Join(errs, proxy.providerProxy.Close())
 	}
+	errs = errors.Join(errs, proxy.accessLog.Close())
 
 	if errs != nil {
 		proxy.log.Error().Err(errs).Msg("Error stopping proxy")
@@ -223,7 +534,94 @@ func (proxy *Proxy) close() {
 	proxy.log.Info().Str("name", proxy.Config.Hostname).Msg("proxy stopped")
 }
 
+// GetTargetsHealth method returns the health of the targets of each port.
+func (proxy *Proxy) GetTargetsHealth() map[string][]model.TargetHealth {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	health := make(map[string][]model.TargetHealth, len(proxy.ports))
+	for name, p := range proxy.ports {
+		health[name] = p.targetsHealth()
+	}
+
+	return health
+}
+
+// GetActiveConnections method returns the number of active connections of each stream port.
+func (proxy *Proxy) GetActiveConnections() map[string]int64 {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	conns := make(map[string]int64, len(proxy.ports))
+	for name, p := range proxy.ports {
+		if p.stream != nil {
+			conns[name] = p.connections()
+		}
+	}
+
+	return conns
+}
+
+// onTargetHealthChange method returns the function called by the port
+// health checker when a target changes its health.
+func (proxy *Proxy) onTargetHealthChange(portName string) func(target *backend) {
+	return func(target *backend) {
+		// update Running and Degraded status
+		if status := proxy.GetStatus(); status == model.ProxyStatusRunning || status == model.ProxyStatusDegraded {
+			proxy.setStatus(model.ProxyStatusRunning)
+		}
+
+		if proxy.onUpdate != nil {
+			proxy.onUpdate(model.ProxyEvent{
+				ID:     proxy.Config.Hostname,
+				Port:   portName,
+				Target: target.url.String(),
+				Status: proxy.GetStatus(),
+				Health: target.getHealth(),
+			})
+		}
+	}
+}
+
+// isDegraded method returns true if any port has unhealthy targets.
+func (proxy *Proxy) isDegraded() bool {
+	proxy.mtx.RLock()
+	defer proxy.mtx.RUnlock()
+
+	for _, p := range proxy.ports {
+		if p.isDegraded() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setError method sets the proxy in error status, keeping the reason of the error.
+func (proxy *Proxy) setError(err error) {
+	proxy.mtx.Lock()
+	proxy.lastError = err.Error()
+	proxy.mtx.Unlock()
+
+	proxy.setStatus(model.ProxyStatusError)
+}
+
+// setRestarting method sets the proxy in restarting status while it waits for
+// the restart attempt.
+func (proxy *Proxy) setRestarting(attempt int) {
+	proxy.mtx.Lock()
+	proxy.restarts = attempt
+	proxy.mtx.Unlock()
+
+	proxy.setStatus(model.ProxyStatusRestarting)
+}
+
 func (proxy *Proxy) setStatus(status model.ProxyStatus) {
+	// a running proxy with unhealthy targets is degraded
+	if status == model.ProxyStatusRunning && proxy.isDegraded() {
+		status = model.ProxyStatusDegraded
+	}
+
 	proxy.mtx.Lock()
 
 	if proxy.status == status {
@@ -232,12 +630,23 @@ func (proxy *Proxy) setStatus(status model.ProxyStatus) {
 	}
 
 	proxy.status = status
+
+	event := model.ProxyEvent{
+		ID:     proxy.Config.Hostname,
+		Status: status,
+	}
+	switch status {
+	case model.ProxyStatusError:
+		event.Error = proxy.lastError
+	case model.ProxyStatusRestarting:
+		event.Error = proxy.lastError
+		event.Attempt = proxy.restarts
+	}
 	proxy.mtx.Unlock()
 
+	metrics.SetProxyStatus(proxy.Config.Hostname, status)
+
 	if proxy.onUpdate != nil {
-		proxy.onUpdate(model.ProxyEvent{
-			ID:     proxy.Config.Hostname,
-			Status: status,
-		})
+		proxy.onUpdate(event)
 	}
 }
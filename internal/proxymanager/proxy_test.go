@@ -0,0 +1,370 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/proxyproviders"
+)
+
+type (
+	// fakeProvider struct is a proxy provider that listens on local addresses.
+	fakeProvider struct {
+		startErr error
+		proxies  map[string]*fakeProxy
+		mtx      sync.Mutex
+	}
+
+	// fakeProxy struct is a proxy of the fakeProvider, its ports listen on
+	// 127.0.0.1 and all clients are identified as who.
+	fakeProxy struct {
+		startErr  error
+		listenErr error
+		events    chan model.ProxyEvent
+		listeners map[string]net.Listener
+		hostname  string
+		who       model.Whois
+		mtx       sync.Mutex
+	}
+)
+
+var (
+	_ proxyproviders.Provider       = (*fakeProvider)(nil)
+	_ proxyproviders.ProxyInterface = (*fakeProxy)(nil)
+)
+
+func newFakeProvider() *fakeProvider {
+	return &fakeProvider{proxies: make(map[string]*fakeProxy)}
+}
+
+func (p *fakeProvider) NewProxy(cfg *model.Config) (proxyproviders.ProxyInterface, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	proxy := &fakeProxy{
+		startErr:  p.startErr,
+		hostname:  cfg.Hostname,
+		who:       alice,
+		events:    make(chan model.ProxyEvent, 10), //nolint:mnd
+		listeners: make(map[string]net.Listener),
+	}
+	p.proxies[cfg.Hostname] = proxy
+
+	return proxy, nil
+}
+
+// proxy method returns the last proxy created for the hostname.
+func (p *fakeProvider) proxy(hostname string) *fakeProxy {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	return p.proxies[hostname]
+}
+
+func (p *fakeProxy) Start(context.Context) error {
+	if p.startErr != nil {
+		return p.startErr
+	}
+	p.events <- model.ProxyEvent{ID: p.hostname, Status: model.ProxyStatusRunning}
+
+	return nil
+}
+
+func (p *fakeProxy) Close() error {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	for _, l := range p.listeners {
+		l.Close()
+	}
+
+	return nil
+}
+
+func (p *fakeProxy) GetListener(port string) (net.Listener, error) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.listenErr != nil {
+		return nil, p.listenErr
+	}
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	p.listeners[port] = l
+
+	return l, nil
+}
+
+// addr method returns the local address of a port.
+func (p *fakeProxy) addr(port string) string {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if l, ok := p.listeners[port]; ok {
+		return l.Addr().String()
+	}
+
+	return ""
+}
+
+func (p *fakeProxy) GetURL() string {
+	return "https://" + p.hostname + ".example.ts.net"
+}
+
+func (p *fakeProxy) GetAuthURL() string {
+	return ""
+}
+
+func (p *fakeProxy) WatchEvents() chan model.ProxyEvent {
+	return p.events
+}
+
+func (p *fakeProxy) Whois(*http.Request) model.Whois {
+	return p.who
+}
+
+func (p *fakeProxy) ConnContext(ctx context.Context, _ net.Conn) context.Context {
+	return ctx
+}
+
+// newTestTarget function starts a HTTP target that answers its name.
+func newTestTarget(t *testing.T, name string) *url.URL {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, name)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return target
+}
+
+// waitStatus function waits until the proxy has the status.
+func waitStatus(t *testing.T, proxy *Proxy, status model.ProxyStatus) {
+	t.Helper()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for proxy.GetStatus() != status {
+		if time.Now().After(deadline) {
+			got := proxy.GetStatus()
+			t.Fatalf("status: got %s, want %s", got.String(), status.String())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// get function returns the body of a GET request to the address, or an error.
+func get(addr string) (string, error) {
+	client := http.Client{Timeout: 5 * time.Second}
+
+	resp, err := client.Get("http://" + addr + "/")
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+
+	return string(body), err
+}
+
+// startTestProxy function starts a proxy of the fake provider and waits
+// until it is running.
+func startTestProxy(t *testing.T, provider *fakeProvider, pcfg *model.Config) *Proxy {
+	t.Helper()
+
+	proxy, err := NewProxy(zerolog.Nop(), pcfg, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(proxy.Close)
+
+	proxy.Start()
+	waitStatus(t, proxy, model.ProxyStatusRunning)
+
+	return proxy
+}
+
+func TestProxyPorts(t *testing.T) {
+	loadTestConfig(t, "")
+
+	provider := newFakeProvider()
+	pcfg := &model.Config{
+		Hostname: "web",
+		Ports:    model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))},
+	}
+
+	var (
+		updates []model.ProxyEvent
+		mtx     sync.Mutex
+	)
+	proxy, err := NewProxy(zerolog.Nop(), pcfg, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxy.onUpdate = func(event model.ProxyEvent) {
+		mtx.Lock()
+		defer mtx.Unlock()
+		updates = append(updates, event)
+	}
+	t.Cleanup(proxy.Close)
+	proxy.Start()
+	waitStatus(t, proxy, model.ProxyStatusRunning)
+
+	fake := provider.proxy("web")
+	if body, err := get(fake.addr("web")); err != nil || body != "web" {
+		t.Fatalf("web port: got %q, %v", body, err)
+	}
+
+	// ports are added and removed without restarting the proxy
+	if err := proxy.StartPort("admin", newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := proxy.Config.Ports["admin"]; !ok {
+		t.Error("port not added to the proxy configuration")
+	}
+	if body, err := get(fake.addr("admin")); err != nil || body != "admin" {
+		t.Fatalf("admin port: got %q, %v", body, err)
+	}
+	if err := proxy.StartPort("admin", newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))); !errors.Is(err, ErrPortAlreadyExists) {
+		t.Errorf("port started twice: %v", err)
+	}
+
+	if err := proxy.StopPort("admin"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := get(fake.addr("admin")); err == nil {
+		t.Error("stopped port still listening")
+	}
+	if _, ok := proxy.Config.Ports["admin"]; ok {
+		t.Error("port not removed from the proxy configuration")
+	}
+	if err := proxy.StopPort("admin"); !errors.Is(err, ErrPortNotFound) {
+		t.Errorf("unknown port stopped: %v", err)
+	}
+
+	if body, err := get(fake.addr("web")); err != nil || body != "web" {
+		t.Errorf("web port after port changes: got %q, %v", body, err)
+	}
+	if proxy.GetStatus() != model.ProxyStatusRunning {
+		t.Error("proxy not running after port changes")
+	}
+
+	mtx.Lock()
+	defer mtx.Unlock()
+	ports := 0
+	for _, e := range updates {
+		if e.Port == "admin" {
+			ports++
+		}
+	}
+	if ports != 2 { //nolint:mnd
+		t.Errorf("port change events: got %d, want 2", ports)
+	}
+}
+
+func TestProxyStartPortNotRunning(t *testing.T) {
+	loadTestConfig(t, "")
+
+	proxy, err := NewProxy(zerolog.Nop(), &model.Config{Hostname: "web"}, newFakeProvider())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(proxy.Close)
+
+	if err := proxy.StartPort("admin", newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))); !errors.Is(err, ErrProxyNotRunning) {
+		t.Errorf("port started on a stopped proxy: %v", err)
+	}
+}
+
+func TestProxyStartPortListenerError(t *testing.T) {
+	loadTestConfig(t, "")
+
+	provider := newFakeProvider()
+	proxy := startTestProxy(t, provider, &model.Config{
+		Hostname: "web",
+		Ports:    model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))},
+	})
+	fake := provider.proxy("web")
+
+	errListen := errors.New("listen failed")
+	fake.mtx.Lock()
+	fake.listenErr = errListen
+	fake.mtx.Unlock()
+
+	admin := newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))
+	if err := proxy.StartPort("admin", admin); !errors.Is(err, errListen) {
+		t.Fatalf("listener error: got %v", err)
+	}
+	if _, ok := proxy.Config.Ports["admin"]; ok {
+		t.Error("failed port kept in the proxy configuration")
+	}
+
+	// the port can be started again
+	fake.mtx.Lock()
+	fake.listenErr = nil
+	fake.mtx.Unlock()
+
+	if err := proxy.StartPort("admin", admin); err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if body, err := get(fake.addr("admin")); err != nil || body != "admin" {
+		t.Errorf("admin port: got %q, %v", body, err)
+	}
+}
+
+func TestProxyCloseWithPortChanges(t *testing.T) {
+	loadTestConfig(t, "")
+
+	provider := newFakeProvider()
+	proxy := startTestProxy(t, provider, &model.Config{
+		Hostname: "web",
+		Ports:    model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))},
+	})
+	admin := newTestPort(t, "8443/https:8080/http", newTestTarget(t, "admin"))
+
+	// ports changed while the proxy closes, checked by the race detector
+	var wg sync.WaitGroup
+	started := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := range 200 {
+			_ = proxy.StartPort("admin", admin)
+			if i == 0 {
+				close(started)
+			}
+			_ = proxy.StopPort("admin")
+		}
+	}()
+	<-started
+	proxy.Close()
+	wg.Wait()
+
+	if got := proxy.GetStatus(); got != model.ProxyStatusStopped {
+		t.Errorf("status: got %s", got.String())
+	}
+}
@@ -6,6 +6,8 @@ package proxymanager
 import (
 	"context"
 	"errors"
+	"maps"
+	"slices"
 	"sync"
 	"time"
 
@@ -14,12 +16,16 @@ import (
 	"github.com/xybydy/tsdproxy/internal/consts"
 
 	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/history"
+	"github.com/xybydy/tsdproxy/internal/metrics"
 	"github.com/xybydy/tsdproxy/internal/model"
 	"github.com/xybydy/tsdproxy/internal/proxyproviders"
 	"github.com/xybydy/tsdproxy/internal/proxyproviders/tailscale"
 	"github.com/xybydy/tsdproxy/internal/targetproviders"
 	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/kubernetes"
 	"github.com/xybydy/tsdproxy/internal/targetproviders/list"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/podman"
 )
 
 type (
@@ -38,9 +44,35 @@ type (
 
 		statusSubscribers map[chan model.ProxyEvent]*subscriber
 
+		// history stores the status timeline of the proxies, nil if disabled
+		history *history.Store
+
+		// supervisor restarts failed proxies
+		supervisor *supervisor
+
+		// stoppedProxies stores proxies stopped from the management API to be started again
+		stoppedProxies map[string]stoppedProxy
+
+		// maintenance stores the proxies in maintenance mode, kept when the proxy is recreated
+		maintenance map[string]bool
+
+		// conflicts stores the targets not started because of a hostname conflict, indexed by targetKey
+		conflicts map[string]Conflict
+
+		// reserved stores the hostnames of proxies being created, with the target that reserved them
+		reserved map[string]string
+
 		// eventWorkerPool limits concurrent event handler goroutines
 		eventWorkerPool chan struct{}
 
+		// watchCtx is the context of WatchEvents, used by target providers added on reload
+		watchCtx context.Context
+
+		// watchers stores the cancel function of each target provider watcher
+		watchers map[string]context.CancelFunc
+
+		watchersWG sync.WaitGroup
+
 		mtx sync.RWMutex
 	}
 
@@ -48,24 +80,39 @@ type (
 		ch       chan model.ProxyEvent
 		lastSeen time.Time
 	}
+
+	stoppedProxy struct {
+		config *model.Config
+		event  targetproviders.TargetEvent
+	}
 )
 
 var (
 	ErrProxyProviderNotFound  = errors.New("proxyProvider not found")
 	ErrTargetProviderNotFound = errors.New("targetProvider not found")
+	ErrProxyNotFound          = errors.New("proxy not found")
 )
 
-// NewProxyManager function creates a new ProxyManager.
-func NewProxyManager(logger zerolog.Logger) *ProxyManager {
+// NewProxyManager function creates a new ProxyManager. The status events of
+// the proxies are recorded in the history store, if not nil.
+func NewProxyManager(logger zerolog.Logger, historyStore *history.Store) *ProxyManager {
 	pm := &ProxyManager{
+		history:           historyStore,
 		Proxies:           make(ProxyList),
 		TargetProviders:   make(TargetProviderList),
 		ProxyProviders:    make(ProxyProviderList),
 		statusSubscribers: make(map[chan model.ProxyEvent]*subscriber),
+		stoppedProxies:    make(map[string]stoppedProxy),
+		maintenance:       make(map[string]bool),
+		conflicts:         make(map[string]Conflict),
+		reserved:          make(map[string]string),
+		watchers:          make(map[string]context.CancelFunc),
 		eventWorkerPool:   make(chan struct{}, consts.MaxConcurrentEventHandlers),
 		log:               logger.With().Str("module", "proxymanager").Logger(),
 	}
 
+	pm.supervisor = newSupervisor(pm.log, pm.restartFailedProxy)
+
 	// Start cleanup routine for stale subscribers
 	// go pm.startSubscriberCleanup()
 
@@ -116,60 +163,74 @@ func (pm *ProxyManager) StopAllProxies() {
 
 // WatchEvents method watches for events from all target providers.
 func (pm *ProxyManager) WatchEvents(ctx context.Context) {
-	var wg sync.WaitGroup
+	pm.mtx.Lock()
+	pm.watchCtx = ctx
+	providers := maps.Clone(pm.TargetProviders)
+	pm.mtx.Unlock()
 
-	for _, provider := range pm.TargetProviders {
-		wg.Add(1)
-		go func(provider targetproviders.TargetProvider) {
-			defer wg.Done()
-			defer func() {
-				if r := recover(); r != nil {
-					pm.log.Error().Interface("panic", r).Msg("event watcher panicked")
-				}
-			}()
+	for name, provider := range providers {
+		pm.watchTargetProvider(name, provider)
+	}
+
+	// Wait for all watchers to finish when context is canceled
+	go func() {
+		<-ctx.Done()
+		pm.log.Debug().Msg("Context canceled, waiting for watchers to finish")
+		pm.watchersWG.Wait()
+		pm.log.Debug().Msg("All watchers finished")
+	}()
+}
 
-			eventsChan := make(chan targetproviders.TargetEvent, consts.EventChannelBufferSize)
-			errChan := make(chan error, consts.ErrorChannelBufferSize)
+// watchTargetProvider method watches for events from a target provider until
+// the WatchEvents context is canceled or the provider is removed.
+func (pm *ProxyManager) watchTargetProvider(name string, provider targetproviders.TargetProvider) {
+	pm.mtx.Lock()
+	ctx, cancel := context.WithCancel(pm.watchCtx)
+	pm.watchers[name] = cancel
+	pm.mtx.Unlock()
 
-			// Start provider's event watcher
-			go provider.WatchEvents(ctx, eventsChan, errChan)
+	pm.watchersWG.Add(1)
+	go func() {
+		defer pm.watchersWG.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				pm.log.Error().Interface("panic", r).Msg("event watcher panicked")
+			}
+		}()
 
-			for {
+		eventsChan := make(chan targetproviders.TargetEvent, consts.EventChannelBufferSize)
+		errChan := make(chan error, consts.ErrorChannelBufferSize)
+
+		// Start provider's event watcher
+		go provider.WatchEvents(ctx, eventsChan, errChan)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-eventsChan:
+				if !ok {
+					pm.log.Debug().Msg("events channel closed, stopping watcher")
+					return
+				}
+				// Use worker pool to limit concurrent event handlers
 				select {
+				case pm.eventWorkerPool <- struct{}{}: // Acquire semaphore
+					go func(e targetproviders.TargetEvent) {
+						defer func() { <-pm.eventWorkerPool }() // Release semaphore
+						pm.HandleProxyEvent(e)
+					}(event)
 				case <-ctx.Done():
 					return
-				case event, ok := <-eventsChan:
-					if !ok {
-						pm.log.Debug().Msg("events channel closed, stopping watcher")
-						return
-					}
-					// Use worker pool to limit concurrent event handlers
-					select {
-					case pm.eventWorkerPool <- struct{}{}: // Acquire semaphore
-						go func(e targetproviders.TargetEvent) {
-							defer func() { <-pm.eventWorkerPool }() // Release semaphore
-							pm.HandleProxyEvent(e)
-						}(event)
-					case <-ctx.Done():
-						return
-					}
-				case err, ok := <-errChan:
-					if !ok {
-						pm.log.Debug().Msg("error channel closed, stopping watcher")
-						return
-					}
-					pm.log.Err(err).Msg("Error watching events")
 				}
+			case err, ok := <-errChan:
+				if !ok {
+					pm.log.Debug().Msg("error channel closed, stopping watcher")
+					return
+				}
+				pm.log.Err(err).Msg("Error watching events")
 			}
-		}(provider)
-	}
-
-	// Wait for all watchers to finish when context is canceled
-	go func() {
-		<-ctx.Done()
-		pm.log.Debug().Msg("Context canceled, waiting for watchers to finish")
-		wg.Wait()
-		pm.log.Debug().Msg("All watchers finished")
+		}
 	}()
 }
 
@@ -183,6 +244,13 @@ func (pm *ProxyManager) HandleProxyEvent(event targetproviders.TargetEvent) {
 	case targetproviders.ActionRestartProxy:
 		pm.eventStop(event)
 		pm.eventStart(event)
+	case targetproviders.ActionStartPort:
+		pm.eventStartPort(event)
+	case targetproviders.ActionStopPort:
+		pm.eventStopPort(event)
+	case targetproviders.ActionRestartPort:
+		pm.eventStopPort(event)
+		pm.eventStartPort(event)
 	}
 }
 
@@ -201,19 +269,122 @@ func (pm *ProxyManager) SubscribeStatusEvents() <-chan model.ProxyEvent {
 	return ch
 }
 
-// UnsubscribeStatusEvents remove the channel subscrived in SubscribeStatusEvents
-func (pm *ProxyManager) UnsubscribeStatusEvents(ch chan model.ProxyEvent) {
+// UnsubscribeStatusEvents remove the channel subscrived in SubscribeStatusEvents.
+// The channel isn't closed, as a broadcast may be sending to it.
+func (pm *ProxyManager) UnsubscribeStatusEvents(ch <-chan model.ProxyEvent) {
 	pm.mtx.Lock()
-	delete(pm.statusSubscribers, ch)
-	pm.mtx.Unlock()
-	close(ch)
+	defer pm.mtx.Unlock()
+
+	for sub := range pm.statusSubscribers {
+		if sub == ch {
+			delete(pm.statusSubscribers, sub)
+			return
+		}
+	}
 }
 
+// GetProxies method returns a copy of the proxies list.
 func (pm *ProxyManager) GetProxies() ProxyList {
 	pm.mtx.RLock()
 	defer pm.mtx.RUnlock()
 
-	return pm.Proxies
+	return maps.Clone(pm.Proxies)
+}
+
+// GetStoppedProxies method returns the configuration of the proxies
+// stopped with StopProxy, indexed by hostname.
+func (pm *ProxyManager) GetStoppedProxies() map[string]*model.Config {
+	pm.mtx.RLock()
+	defer pm.mtx.RUnlock()
+
+	stopped := make(map[string]*model.Config, len(pm.stoppedProxies))
+	for name, p := range pm.stoppedProxies {
+		stopped[name] = p.config
+	}
+
+	return stopped
+}
+
+// StartProxy method starts a proxy previously stopped with StopProxy.
+func (pm *ProxyManager) StartProxy(name string) error {
+	pm.mtx.Lock()
+	stopped, ok := pm.stoppedProxies[name]
+	delete(pm.stoppedProxies, name)
+	pm.mtx.Unlock()
+
+	if !ok {
+		return ErrProxyNotFound
+	}
+
+	stopped.event.Action = targetproviders.ActionStartProxy
+	pm.HandleProxyEvent(stopped.event)
+
+	return nil
+}
+
+// StopProxy method stops a proxy and keeps it to be started with StartProxy.
+func (pm *ProxyManager) StopProxy(name string) error {
+	event, err := pm.getProxyEvent(name, targetproviders.ActionStopProxy)
+	if err != nil {
+		return err
+	}
+
+	proxy, _ := pm.GetProxy(name)
+
+	pm.mtx.Lock()
+	pm.stoppedProxies[name] = stoppedProxy{
+		config: proxy.Config,
+		event:  event,
+	}
+	pm.mtx.Unlock()
+
+	pm.HandleProxyEvent(event)
+
+	return nil
+}
+
+// RestartProxy method restarts a proxy.
+func (pm *ProxyManager) RestartProxy(name string) error {
+	event, err := pm.getProxyEvent(name, targetproviders.ActionRestartProxy)
+	if err != nil {
+		return err
+	}
+
+	pm.HandleProxyEvent(event)
+
+	return nil
+}
+
+// restartFailedProxy method restarts a proxy from the supervisor, if the proxy
+// wasn't removed or replaced since it failed.
+func (pm *ProxyManager) restartFailedProxy(proxy *Proxy) error {
+	if p, ok := pm.GetProxy(proxy.Config.Hostname); !ok || p != proxy {
+		return ErrProxyNotFound
+	}
+
+	return pm.RestartProxy(proxy.Config.Hostname)
+}
+
+// getProxyEvent method returns a TargetEvent to apply the action on a running proxy.
+func (pm *ProxyManager) getProxyEvent(name string, action targetproviders.ActionType) (targetproviders.TargetEvent, error) {
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return targetproviders.TargetEvent{}, ErrProxyNotFound
+	}
+
+	pm.mtx.RLock()
+	targetProvider, ok := pm.TargetProviders[proxy.Config.TargetProvider]
+	pm.mtx.RUnlock()
+
+	if !ok {
+		return targetproviders.TargetEvent{}, ErrTargetProviderNotFound
+	}
+
+	return targetproviders.TargetEvent{
+		TargetProvider: targetProvider,
+		ID:             proxy.Config.TargetID,
+		Action:         action,
+	}, nil
 }
 
 func (pm *ProxyManager) GetProxy(name string) (*Proxy, bool) {
@@ -225,8 +396,66 @@ func (pm *ProxyManager) GetProxy(name string) (*Proxy, bool) {
 	return proxy, ok
 }
 
+// CreateShareLink method creates a one-time share link of a Funnel port of a proxy.
+func (pm *ProxyManager) CreateShareLink(name, port string) (ShareLink, error) {
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return ShareLink{}, ErrProxyNotFound
+	}
+
+	return proxy.CreateShareLink(port)
+}
+
+// PurgeCache method removes the cached responses of a port of a proxy, or of
+// all ports if port is empty.
+func (pm *ProxyManager) PurgeCache(name, port, path string) (int, error) {
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return 0, ErrProxyNotFound
+	}
+
+	return proxy.PurgeCache(port, path)
+}
+
+// SetMaintenance method enables or disables the maintenance mode of a proxy.
+// The mode is kept if the proxy is recreated, like when its container restarts.
+func (pm *ProxyManager) SetMaintenance(name string, enabled bool) error {
+	proxy, ok := pm.GetProxy(name)
+	if !ok {
+		return ErrProxyNotFound
+	}
+
+	pm.mtx.Lock()
+	if enabled {
+		pm.maintenance[name] = true
+	} else {
+		delete(pm.maintenance, name)
+	}
+	pm.mtx.Unlock()
+
+	if proxy.IsMaintenance() == enabled {
+		return nil
+	}
+	proxy.SetMaintenance(enabled)
+
+	pm.log.Info().Str("proxy", name).Bool("enabled", enabled).Msg("maintenance mode changed")
+
+	status := model.ProxyStatusMaintenance
+	if !enabled {
+		status = proxy.GetStatus()
+	}
+	pm.broadcastStatusEvents(model.ProxyEvent{
+		ID:     name,
+		Status: status,
+	})
+
+	return nil
+}
+
 // broadcastStatusEvents broadcasts proxy status event to all SubscribeStatusEvents
 func (pm *ProxyManager) broadcastStatusEvents(event model.ProxyEvent) {
+	pm.recordEvent(event)
+
 	pm.mtx.RLock()
 	subscribers := make([]chan model.ProxyEvent, 0, len(pm.statusSubscribers))
 	for ch := range pm.statusSubscribers {
@@ -251,21 +480,60 @@ func (pm *ProxyManager) broadcastStatusEvents(event model.ProxyEvent) {
 	}
 }
 
-// addTargetProviders method adds TargetProviders from configuration file.
-func (pm *ProxyManager) addTargetProviders() {
-	for name, provider := range config.Config.Docker {
-		p, err := docker.New(pm.log, name, provider)
-		if err != nil {
-			pm.log.Error().Err(err).Msg("Error creating Docker provider")
-			continue
+// recordEvent method adds the proxy event to the history store.
+func (pm *ProxyManager) recordEvent(event model.ProxyEvent) {
+	if pm.history == nil {
+		return
+	}
+
+	entry := history.Event{
+		Time:    time.Now(),
+		Status:  event.Status.String(),
+		Port:    event.Port,
+		Target:  event.Target,
+		AuthURL: event.AuthURL,
+		Error:   event.Error,
+		Attempt: event.Attempt,
+	}
+
+	if event.Target != "" {
+		entry.Health = event.Health.String()
+	}
+
+	if event.Status == model.ProxyStatusAuthenticating && entry.AuthURL == "" {
+		if p, ok := pm.GetProxy(event.ID); ok {
+			entry.AuthURL = p.GetAuthURL()
 		}
+	}
 
-		pm.addTargetProvider(p, name)
+	if err := pm.history.Add(event.ID, entry); err != nil {
+		pm.log.Error().Err(err).Str("proxy", event.ID).Msg("Error recording proxy event")
+	}
+}
+
+// GetHistory method returns the last status events of a proxy, newest first.
+func (pm *ProxyManager) GetHistory(name string, limit int) ([]history.Event, error) {
+	if pm.history == nil {
+		return []history.Event{}, nil
 	}
-	for name, file := range config.Config.Lists {
-		p, err := list.New(pm.log, name, file)
+
+	return pm.history.Get(name, limit)
+}
+
+// addTargetProviders method adds TargetProviders from configuration file.
+func (pm *ProxyManager) addTargetProviders() {
+	cfg := config.Get()
+	names := slices.Concat(
+		slices.Collect(maps.Keys(cfg.Docker)),
+		slices.Collect(maps.Keys(cfg.Kubernetes)),
+		slices.Collect(maps.Keys(cfg.Podman)),
+		slices.Collect(maps.Keys(cfg.Lists)),
+	)
+
+	for _, name := range names {
+		p, err := newTargetProvider(pm.log, name)
 		if err != nil {
-			pm.log.Error().Err(err).Msg("Error creating Files provider")
+			pm.log.Error().Err(err).Str("provider", name).Msg("Error creating target provider")
 			continue
 		}
 
@@ -277,8 +545,8 @@ func (pm *ProxyManager) addTargetProviders() {
 func (pm *ProxyManager) addProxyProviders() {
 	pm.log.Debug().Msg("Setting up Tailscale Providers")
 	// add Tailscale Providers
-	for name, provider := range config.Config.Tailscale.Providers {
-		if p, err := tailscale.New(pm.log, name, provider); err != nil {
+	for name := range config.Get().Tailscale.Providers {
+		if p, err := newProxyProvider(pm.log, name); err != nil {
 			pm.log.Error().Err(err).Msg("Error creating Tailscale provider")
 		} else {
 			pm.log.Debug().Str("provider", name).Msg("Created Proxy provider")
@@ -287,6 +555,37 @@ func (pm *ProxyManager) addProxyProviders() {
 	}
 }
 
+// newTargetProvider function creates the TargetProvider defined with name in
+// the configuration file.
+func newTargetProvider(log zerolog.Logger, name string) (targetproviders.TargetProvider, error) {
+	cfg := config.Get()
+	if provider, ok := cfg.Docker[name]; ok {
+		return docker.New(log, name, provider)
+	}
+	if provider, ok := cfg.Kubernetes[name]; ok {
+		return kubernetes.New(log, name, provider)
+	}
+	if provider, ok := cfg.Podman[name]; ok {
+		return podman.New(log, name, provider)
+	}
+	if file, ok := cfg.Lists[name]; ok {
+		return list.New(log, name, file)
+	}
+
+	return nil, ErrTargetProviderNotFound
+}
+
+// newProxyProvider function creates the ProxyProvider defined with name in
+// the configuration file.
+func newProxyProvider(log zerolog.Logger, name string) (proxyproviders.Provider, error) {
+	provider, ok := config.Get().Tailscale.Providers[name]
+	if !ok {
+		return nil, ErrProxyProviderNotFound
+	}
+
+	return tailscale.New(log, name, provider)
+}
+
 // addTargetProvider method adds a TargetProvider to the ProxyManager.
 func (pm *ProxyManager) addTargetProvider(provider targetproviders.TargetProvider, name string) {
 	pm.mtx.Lock()
@@ -321,6 +620,8 @@ func (pm *ProxyManager) removeProxy(hostname string) {
 		return
 	}
 
+	pm.supervisor.cancel(hostname)
+
 	proxy.Close()
 
 	pm.mtx.Lock()
@@ -328,6 +629,8 @@ func (pm *ProxyManager) removeProxy(hostname string) {
 
 	delete(pm.Proxies, hostname)
 
+	metrics.DeleteProxy(hostname)
+
 	pm.log.Debug().Str("proxy", hostname).Msg("Removed proxy")
 }
 
@@ -348,28 +651,105 @@ func (pm *ProxyManager) eventStart(event targetproviders.TargetEvent) {
 func (pm *ProxyManager) eventStop(event targetproviders.TargetEvent) {
 	pm.log.Debug().Str("targetID", event.ID).Msg("Stopping target")
 
-	proxy := pm.getProxyByTargetID(event.ID)
+	proxy := pm.getProxyByEvent(event)
 	if proxy == nil {
+		if pm.removeConflict(event) {
+			event.TargetProvider.RemoveTarget(event.ID)
+			pm.log.Debug().Str("target", event.ID).Msg("Removed target with hostname conflict")
+			return
+		}
 		pm.log.Error().Int("action", int(event.Action)).Str("target", event.ID).Msg("No proxy found for target")
 		return
 	}
 
-	targetprovider := pm.TargetProviders[proxy.Config.TargetProvider]
+	pm.mtx.RLock()
+	targetprovider, ok := pm.TargetProviders[proxy.Config.TargetProvider]
+	pm.mtx.RUnlock()
+
+	// the target provider was removed by a configuration reload
+	if !ok {
+		pm.removeProxy(proxy.Config.Hostname)
+		pm.startWaitingTarget(proxy.Config.Hostname)
+		return
+	}
+
 	if err := targetprovider.DeleteProxy(event.ID); err != nil {
 		pm.log.Error().Err(err).Msg("No proxy found for target")
 		return
 	}
 
 	pm.removeProxy(proxy.Config.Hostname)
+	pm.startWaitingTarget(proxy.Config.Hostname)
+}
+
+// eventStartPort method starts a port of a running Proxy from a event trigger.
+// The proxy is restarted if the port can't be started on the running proxy.
+func (pm *ProxyManager) eventStartPort(event targetproviders.TargetEvent) {
+	pm.log.Debug().Str("targetID", event.ID).Str("port", event.Port).Msg("Starting port")
+
+	proxy := pm.getProxyByEvent(event)
+	if proxy == nil {
+		pm.log.Error().Int("action", int(event.Action)).Str("target", event.ID).Msg("No proxy found for target")
+		return
+	}
+
+	pcfg, err := event.TargetProvider.AddTarget(event.ID)
+	if err != nil {
+		pm.log.Error().Err(err).Str("targetID", event.ID).Msg("Error adding target")
+		return
+	}
+
+	pconfig, ok := pcfg.Ports[event.Port]
+	if !ok {
+		pm.log.Error().Str("targetID", event.ID).Str("port", event.Port).Msg("Port not found in target")
+		return
+	}
+
+	if err := proxy.StartPort(event.Port, pconfig); err != nil {
+		pm.log.Warn().Err(err).Str("proxy", proxy.Config.Hostname).Str("port", event.Port).
+			Msg("Error starting port, restarting proxy")
+
+		event.Action = targetproviders.ActionRestartProxy
+		pm.HandleProxyEvent(event)
+	}
+}
+
+// eventStopPort method stops a port of a running Proxy from a event trigger.
+func (pm *ProxyManager) eventStopPort(event targetproviders.TargetEvent) {
+	pm.log.Debug().Str("targetID", event.ID).Str("port", event.Port).Msg("Stopping port")
+
+	proxy := pm.getProxyByEvent(event)
+	if proxy == nil {
+		pm.log.Error().Int("action", int(event.Action)).Str("target", event.ID).Msg("No proxy found for target")
+		return
+	}
+
+	if err := proxy.StopPort(event.Port); err != nil {
+		pm.log.Error().Err(err).Str("proxy", proxy.Config.Hostname).Str("port", event.Port).Msg("Error stopping port")
+	}
 }
 
-// getProxyByTargetID method returns a Proxy by TargetID.
-func (pm *ProxyManager) getProxyByTargetID(targetID string) *Proxy {
+// getProxyByEvent method returns the Proxy of the target of an event. Target
+// IDs are only unique in their target provider.
+func (pm *ProxyManager) getProxyByEvent(event targetproviders.TargetEvent) *Proxy {
 	pm.mtx.RLock()
 	defer pm.mtx.RUnlock()
 
 	for _, p := range pm.Proxies {
-		if p.Config.TargetID == targetID {
+		if p.Config.TargetID == event.ID && pm.TargetProviders[p.Config.TargetProvider] == event.TargetProvider {
+			return p
+		}
+	}
+	return nil
+}
+
+// getProxyByTarget method returns the Proxy of a target of a target provider.
+func (pm *ProxyManager) getProxyByTarget(targetProvider, targetID string) *Proxy {
+	pm.mtx.RLock()
+	defer pm.mtx.RUnlock()
+
+	for _, p := range pm.Proxies {
+		if p.Config.TargetProvider == targetProvider && p.Config.TargetID == targetID {
 			return p
 		}
 	}
@@ -380,25 +760,59 @@ func (pm *ProxyManager) getProxyByTargetID(targetID string) *Proxy {
 func (pm *ProxyManager) newAndStartProxy(name string, proxyConfig *model.Config) {
 	pm.log.Debug().Str("proxy", name).Msg("Creating proxy")
 
-	proxyProvider, err := pm.getProxyProvider(proxyConfig)
+	proxyProviderName, err := pm.getProxyProviderName(proxyConfig)
 	if err != nil {
 		pm.log.Error().Err(err).Msg("Error to get ProxyProvider")
 		return
 	}
 
+	pm.mtx.RLock()
+	proxyProvider := pm.ProxyProviders[proxyProviderName]
+	pm.mtx.RUnlock()
+
+	// a new start of a running target replaces its proxy
+	if old := pm.getProxyByTarget(proxyConfig.TargetProvider, proxyConfig.TargetID); old != nil {
+		pm.removeProxy(old.Config.Hostname)
+	}
+
+	if err := pm.reserveHostname(proxyConfig); err != nil {
+		pm.log.Error().Err(err).Str("proxy", name).Str("target", targetKey(proxyConfig)).Msg("Hostname conflict, proxy not started")
+
+		// the conflict is recorded in the history of the hostname
+		pm.broadcastStatusEvents(model.ProxyEvent{
+			ID:     name,
+			Status: model.ProxyStatusError,
+			Error:  err.Error(),
+		})
+		return
+	}
+	defer pm.releaseHostname(proxyConfig.Hostname)
+
 	p, err := NewProxy(pm.log, proxyConfig, proxyProvider)
 	if err != nil {
 		pm.log.Error().Err(err).Msg("Error creating proxy")
 		return
 	}
+	p.proxyProvider = proxyProviderName
+
+	// the hostname can have a suffix after a conflict
+	pm.mtx.RLock()
+	p.SetMaintenance(pm.maintenance[proxyConfig.Hostname])
+	pm.mtx.RUnlock()
 
 	// any status change in proxy will be broadcasted
 	p.onUpdate = func(event model.ProxyEvent) {
 		pm.broadcastStatusEvents(event)
+		pm.supervisor.onProxyEvent(p, event)
 	}
 
 	pm.addProxy(p)
 
+	// a proxy started by its target provider is no longer stopped
+	pm.mtx.Lock()
+	delete(pm.stoppedProxies, p.Config.Hostname)
+	pm.mtx.Unlock()
+
 	// broadcasts ProxyStatusInitializing
 	pm.broadcastStatusEvents(model.ProxyEvent{
 		ID:     p.Config.Hostname,
@@ -408,34 +822,39 @@ func (pm *ProxyManager) newAndStartProxy(name string, proxyConfig *model.Config)
 	p.Start()
 }
 
-// getProxyProvider method returns a ProxyProvider.
-func (pm *ProxyManager) getProxyProvider(proxy *model.Config) (proxyproviders.Provider, error) {
+// getProxyProviderName method returns the name of the ProxyProvider of a proxy.
+func (pm *ProxyManager) getProxyProviderName(proxy *model.Config) (string, error) {
+	pm.mtx.RLock()
+	defer pm.mtx.RUnlock()
+
 	// return ProxyProvider defined in configurtion
 	//
 	if proxy.ProxyProvider != "" {
-		p, ok := pm.ProxyProviders[proxy.ProxyProvider]
-		if !ok {
-			return nil, ErrProxyProviderNotFound
+		if _, ok := pm.ProxyProviders[proxy.ProxyProvider]; !ok {
+			return "", ErrProxyProviderNotFound
 		}
-		return p, nil
+		return proxy.ProxyProvider, nil
 	}
 
 	// return default ProxyProvider defined in TargetProvider
 	targetProvider, ok := pm.TargetProviders[proxy.TargetProvider]
 	if !ok {
-		return nil, ErrTargetProviderNotFound
+		return "", ErrTargetProviderNotFound
 	}
-	if p, ok := pm.ProxyProviders[targetProvider.GetDefaultProxyProviderName()]; ok {
-		return p, nil
+	if name := targetProvider.GetDefaultProxyProviderName(); name != "" {
+		if _, ok := pm.ProxyProviders[name]; ok {
+			return name, nil
+		}
 	}
 
 	// return default ProxyProvider from global configurtion
 	//
-	if p, ok := pm.ProxyProviders[config.Config.DefaultProxyProvider]; ok {
-		return p, nil
+	defaultProvider := config.Get().DefaultProxyProvider
+	if _, ok := pm.ProxyProviders[defaultProvider]; ok {
+		return defaultProvider, nil
 	}
 
 	// return the first ProxyProvider
 	//
-	return nil, ErrProxyProviderNotFound
+	return "", ErrProxyProviderNotFound
 }
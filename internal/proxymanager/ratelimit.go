@@ -0,0 +1,220 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog"
+	"golang.org/x/time/rate"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/metrics"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+const (
+	// rateLimitCleanupInterval is the time between removals of idle clients.
+	rateLimitCleanupInterval = time.Minute
+	// rateLimitMinIdle is the minimum time a client is kept after its last request.
+	rateLimitMinIdle = time.Minute
+)
+
+type (
+	// rateLimiter struct limits the requests of each client with a token bucket.
+	rateLimiter struct {
+		clients     map[string]*rateClient
+		lastCleanup time.Time
+		limit       rate.Limit
+		burst       int
+		// idle is the time a full bucket takes to refill, after which a
+		// client can be removed without changing its limit
+		idle time.Duration
+		mtx  sync.Mutex
+	}
+
+	rateClient struct {
+		limiter  *rate.Limiter
+		lastSeen time.Time
+	}
+)
+
+// newRateLimiter function returns the rate limiter of a limit, or nil if the
+// limit is disabled.
+func newRateLimiter(limit model.RateLimit) *rateLimiter {
+	if !limit.IsEnabled() {
+		return nil
+	}
+
+	idle := time.Duration(float64(limit.GetBurst()) / limit.PerSecond() * float64(time.Second))
+
+	return &rateLimiter{
+		clients:     make(map[string]*rateClient),
+		lastCleanup: time.Now(),
+		limit:       rate.Limit(limit.PerSecond()),
+		burst:       limit.GetBurst(),
+		idle:        max(idle, rateLimitMinIdle),
+	}
+}
+
+// globalLimiter stores the rate limiter shared by all proxies, with the
+// configuration it was created from.
+var globalLimiter struct {
+	limiter *rateLimiter
+	config  config.RateLimitConfig
+	mtx     sync.Mutex
+}
+
+// globalRateLimiter function returns the rate limiter shared by all proxies,
+// or nil if the global rate limit is disabled. The limiter is replaced when a
+// reload changes the rateLimit of the configuration.
+func globalRateLimiter() *rateLimiter {
+	c := config.Get()
+	if c == nil {
+		return nil
+	}
+
+	globalLimiter.mtx.Lock()
+	defer globalLimiter.mtx.Unlock()
+
+	if c.RateLimit != globalLimiter.config {
+		globalLimiter.config = c.RateLimit
+		globalLimiter.limiter = newRateLimiter(model.RateLimit{
+			Requests: c.RateLimit.Requests,
+			Period:   c.RateLimit.Period,
+			Burst:    c.RateLimit.Burst,
+		})
+	}
+
+	return globalLimiter.limiter
+}
+
+// allow method takes a token from the bucket of the client, returning false
+// and the time to wait for the next token if the bucket is empty.
+func (rl *rateLimiter) allow(key string) (bool, time.Duration) {
+	_, delay := rl.reserve(key, time.Now())
+
+	return delay == 0, delay
+}
+
+// reserve method takes a token from the bucket of the client and returns its
+// reservation, to be canceled if the request is rejected by another limit. If
+// the bucket is empty, no token is taken and the time to wait for the next
+// token is returned.
+func (rl *rateLimiter) reserve(key string, now time.Time) (*rate.Reservation, time.Duration) {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	if now.Sub(rl.lastCleanup) > rateLimitCleanupInterval {
+		rl.cleanup(now)
+	}
+
+	c, ok := rl.clients[key]
+	if !ok {
+		c = &rateClient{limiter: rate.NewLimiter(rl.limit, rl.burst)}
+		rl.clients[key] = c
+	}
+	c.lastSeen = now
+
+	r := c.limiter.ReserveN(now, 1)
+	if delay := r.DelayFrom(now); delay > 0 {
+		r.CancelAt(now)
+		return nil, delay
+	}
+
+	return r, 0
+}
+
+// wait method returns the time to wait for the next token of the client,
+// without taking it.
+func (rl *rateLimiter) wait(key string) time.Duration {
+	rl.mtx.Lock()
+	defer rl.mtx.Unlock()
+
+	c, ok := rl.clients[key]
+	if !ok {
+		return 0
+	}
+
+	tokens := c.limiter.TokensAt(time.Now())
+	if tokens >= 1 {
+		return 0
+	}
+
+	return time.Duration((1 - tokens) / float64(rl.limit) * float64(time.Second))
+}
+
+// cleanup method removes the clients idle long enough to have a full bucket.
+func (rl *rateLimiter) cleanup(now time.Time) {
+	for key, c := range rl.clients {
+		if now.Sub(c.lastSeen) > rl.idle {
+			delete(rl.clients, key)
+		}
+	}
+	rl.lastCleanup = now
+}
+
+// rateLimitMiddleware function returns a middleware that rejects the requests
+// of clients over the global limit or any of the limits with 429 Too Many
+// Requests. Rejected requests don't count in the other limits.
+func rateLimitMiddleware(log zerolog.Logger, proxyName, portName string, limiters []*rateLimiter, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := rateLimitKey(r)
+		now := time.Now()
+
+		// the global limiter is read on each request, to follow reloads
+		all := limiters
+		if global := globalRateLimiter(); global != nil {
+			all = append([]*rateLimiter{global}, limiters...)
+		}
+
+		reservations := make([]*rate.Reservation, 0, len(all))
+		for _, rl := range all {
+			reservation, delay := rl.reserve(key, now)
+			if delay > 0 {
+				for _, res := range reservations {
+					res.CancelAt(now)
+				}
+
+				log.Debug().Str("client", key).Dur("retry", delay).Msg("request rate limited")
+				metrics.ObserveRateLimited(proxyName, portName)
+
+				writeTooManyRequests(w, delay)
+
+				return
+			}
+			reservations = append(reservations, reservation)
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// writeTooManyRequests function writes a 429 Too Many Requests response with
+// the seconds to wait before retrying.
+func writeTooManyRequests(w http.ResponseWriter, delay time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delay.Seconds()))))
+	http.Error(w, http.StatusText(http.StatusTooManyRequests), http.StatusTooManyRequests)
+}
+
+// rateLimitKey function returns the client of a request for the rate limits:
+// the Tailscale user ID, or the client IP on Funnel requests. Tagged devices
+// share the same user, so they are limited by IP.
+func rateLimitKey(r *http.Request) string {
+	if who, ok := model.WhoisFromContext(r.Context()); ok && who.ID != "" && len(who.Tags) == 0 {
+		return "user:" + who.ID
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
@@ -0,0 +1,152 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func TestRateLimiter(t *testing.T) {
+	if newRateLimiter(model.RateLimit{}) != nil {
+		t.Error("rate limiter without requests")
+	}
+
+	rl := newRateLimiter(model.RateLimit{Requests: 2, Period: time.Minute})
+
+	for i := range 2 {
+		if ok, _ := rl.allow("user:1"); !ok {
+			t.Fatalf("request %d limited", i)
+		}
+	}
+
+	// the next token comes after 30 seconds
+	ok, delay := rl.allow("user:1")
+	if ok || delay <= 25*time.Second || delay > 30*time.Second {
+		t.Errorf("request over the limit: got %v, %s", ok, delay)
+	}
+	if wait := rl.wait("user:1"); wait <= 25*time.Second {
+		t.Errorf("wait: got %s", wait)
+	}
+
+	// clients have their own limit
+	if ok, _ := rl.allow("user:2"); !ok {
+		t.Error("other client limited")
+	}
+	if wait := rl.wait("user:3"); wait != 0 {
+		t.Errorf("wait of a new client: got %s", wait)
+	}
+}
+
+func TestGlobalRateLimit(t *testing.T) {
+	loadTestConfig(t, "rateLimit:\n  requests: 2\n  period: 1m\n")
+
+	newPort := func(hostname string) *port {
+		proxyConfig := &model.Config{Hostname: hostname}
+		errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+		p, err := newPortProxy(context.Background(), newTestPort(t, "443/https:80/http", newTestTarget(t, hostname)),
+			zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages, nil, func(*backend) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	serve := func(p *port) int {
+		w := httptest.NewRecorder()
+		p.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Code
+	}
+	web, admin := newPort("web"), newPort("admin")
+
+	// the limit is shared by the ports of all proxies
+	for i, tt := range []struct {
+		port   *port
+		status int
+	}{
+		{port: web, status: http.StatusOK},
+		{port: admin, status: http.StatusOK},
+		{port: web, status: http.StatusTooManyRequests},
+		{port: admin, status: http.StatusTooManyRequests},
+	} {
+		if got := serve(tt.port); got != tt.status {
+			t.Errorf("request %d: got %d, want %d", i, got, tt.status)
+		}
+	}
+
+	// reloads replace the limit of the running ports
+	loadTestConfig(t, "")
+	if got := serve(web); got != http.StatusOK {
+		t.Errorf("global limit disabled: got %d", got)
+	}
+}
+
+func TestRateLimitKey(t *testing.T) {
+	tests := []struct {
+		who  *model.Whois
+		want string
+	}{
+		{who: &alice, want: "user:" + alice.ID},
+		{who: &model.Whois{ID: "2", Tags: []string{"tag:server"}}, want: "ip:192.0.2.1"},
+		{want: "ip:192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.who != nil {
+			r = r.WithContext(model.WhoisNewContext(r.Context(), *tt.who))
+		}
+		if got := rateLimitKey(r); got != tt.want {
+			t.Errorf("key: got %s, want %s", got, tt.want)
+		}
+	}
+}
+
+func TestPortRateLimit(t *testing.T) {
+	loadTestConfig(t, "")
+
+	// the proxy allows 3 requests and the port 2
+	pconfig := newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))
+	pconfig.RateLimit = model.RateLimit{Requests: 2, Period: time.Minute}
+	proxyLimiter := newRateLimiter(model.RateLimit{Requests: 3, Period: time.Minute})
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	newPort := func() *port {
+		p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice),
+			proxyLimiter, errPages, nil, func(*backend) {})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	web, admin := newPort(), newPort()
+
+	for i, tt := range []struct {
+		port   *port
+		status int
+	}{
+		{port: web, status: http.StatusOK},
+		{port: web, status: http.StatusOK},
+		{port: web, status: http.StatusTooManyRequests},
+		{port: admin, status: http.StatusOK},
+		{port: admin, status: http.StatusTooManyRequests},
+	} {
+		w := httptest.NewRecorder()
+		tt.port.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if w.Code != tt.status {
+			t.Errorf("request %d: got %d, want %d", i, w.Code, tt.status)
+		}
+		if w.Code == http.StatusTooManyRequests && w.Header().Get("Retry-After") == "" {
+			t.Errorf("request %d: no Retry-After", i)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+)
+
+// Reload method applies the provider changes of a configuration reload.
+// Removed providers are closed, changed providers are recreated and added
+// providers are started. Only the proxies of affected providers are restarted.
+func (pm *ProxyManager) Reload(changes config.Changes) {
+	pm.log.Info().
+		Strs("targetProvidersAdded", changes.TargetProviders.Added).
+		Strs("targetProvidersRemoved", changes.TargetProviders.Removed).
+		Strs("targetProvidersChanged", changes.TargetProviders.Changed).
+		Strs("proxyProvidersAdded", changes.ProxyProviders.Added).
+		Strs("proxyProvidersRemoved", changes.ProxyProviders.Removed).
+		Strs("proxyProvidersChanged", changes.ProxyProviders.Changed).
+		Msg("Reloading configuration")
+
+	// stop target providers first, their proxies don't need a restart
+	//
+	for _, name := range slices.Concat(changes.TargetProviders.Removed, changes.TargetProviders.Changed) {
+		pm.removeTargetProvider(name)
+	}
+
+	// update proxy providers and restart the proxies using them
+	//
+	for _, name := range changes.ProxyProviders.Removed {
+		pm.mtx.Lock()
+		delete(pm.ProxyProviders, name)
+		pm.mtx.Unlock()
+	}
+	for _, name := range slices.Concat(changes.ProxyProviders.Added, changes.ProxyProviders.Changed) {
+		p, err := newProxyProvider(pm.log, name)
+		if err != nil {
+			pm.log.Error().Err(err).Str("provider", name).Msg("Error creating Tailscale provider")
+			pm.mtx.Lock()
+			delete(pm.ProxyProviders, name)
+			pm.mtx.Unlock()
+			continue
+		}
+		pm.addProxyProvider(p, name)
+	}
+
+	pm.restartProxiesOfProxyProviders(slices.Concat(changes.ProxyProviders.Removed, changes.ProxyProviders.Changed))
+
+	// start new target providers
+	//
+	for _, name := range slices.Concat(changes.TargetProviders.Added, changes.TargetProviders.Changed) {
+		p, err := newTargetProvider(pm.log, name)
+		if err != nil {
+			pm.log.Error().Err(err).Str("provider", name).Msg("Error creating target provider")
+			continue
+		}
+
+		pm.addTargetProvider(p, name)
+		pm.watchTargetProvider(name, p)
+	}
+}
+
+// removeTargetProvider method stops watching a TargetProvider, closes it and
+// removes its proxies.
+func (pm *ProxyManager) removeTargetProvider(name string) {
+	pm.mtx.Lock()
+	provider, ok := pm.TargetProviders[name]
+	cancel := pm.watchers[name]
+	delete(pm.TargetProviders, name)
+	delete(pm.watchers, name)
+
+	// proxies stopped with the API can't be started without their provider
+	for hostname, stopped := range pm.stoppedProxies {
+		if stopped.config.TargetProvider == name {
+			delete(pm.stoppedProxies, hostname)
+		}
+	}
+	for key, c := range pm.conflicts {
+		if c.Config.TargetProvider == name {
+			delete(pm.conflicts, key)
+		}
+	}
+	pm.mtx.Unlock()
+
+	if !ok {
+		return
+	}
+
+	pm.log.Info().Str("provider", name).Msg("Removing target provider")
+
+	if cancel != nil {
+		cancel()
+	}
+	provider.Close()
+
+	pm.forEachProxy(func(proxy *Proxy) bool {
+		return proxy.Config.TargetProvider == name
+	}, func(proxy *Proxy) {
+		pm.removeProxy(proxy.Config.Hostname)
+	})
+}
+
+// restartProxiesOfProxyProviders method restarts the proxies that use one of
+// the ProxyProviders, or whose ProxyProvider is different after the reload.
+func (pm *ProxyManager) restartProxiesOfProxyProviders(names []string) {
+	pm.forEachProxy(func(proxy *Proxy) bool {
+		if slices.Contains(names, proxy.proxyProvider) {
+			return true
+		}
+		name, err := pm.getProxyProviderName(proxy.Config)
+		return err != nil || name != proxy.proxyProvider
+	}, func(proxy *Proxy) {
+		event, err := pm.getProxyEvent(proxy.Config.Hostname, targetproviders.ActionRestartProxy)
+		if err != nil {
+			pm.log.Error().Err(err).Str("proxy", proxy.Config.Hostname).Msg("Error restarting proxy")
+			return
+		}
+
+		pm.log.Info().Str("proxy", proxy.Config.Hostname).Msg("Restarting proxy after configuration reload")
+		pm.HandleProxyEvent(event)
+	})
+}
+
+// forEachProxy method runs fn concurrently on the proxies matched by filter
+// and waits for all of them.
+func (pm *ProxyManager) forEachProxy(filter func(*Proxy) bool, fn func(*Proxy)) {
+	wg := sync.WaitGroup{}
+
+	for _, proxy := range pm.GetProxies() {
+		if !filter(proxy) {
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn(proxy)
+		}()
+	}
+
+	wg.Wait()
+}
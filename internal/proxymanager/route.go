@@ -0,0 +1,94 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"cmp"
+	"net/http"
+	"net/url"
+	"slices"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+// route struct is a path route of a port, with its own targets.
+type route struct {
+	balancer      *balancer
+	healthChecker *healthChecker
+	handler       http.Handler
+	config        model.Route
+}
+
+// newRoutes function returns the routes of the port, sorted from the longest
+// path to the shortest, so the most specific route matches first.
+func newRoutes(
+	log zerolog.Logger,
+	pconfig model.PortConfig,
+	next http.Handler,
+	onHealthChange func(target *backend),
+) []*route {
+	//
+	routes := make([]*route, 0, len(pconfig.Routes))
+
+	for _, rc := range pconfig.Routes {
+		if len(rc.GetTargets()) == 0 {
+			log.Error().Str("route", rc.Path).Msg("no targets found for route")
+			continue
+		}
+
+		lb := newBalancer(pconfig.LoadBalance, rc.GetTargets())
+		routes = append(routes, &route{
+			config:        rc,
+			balancer:      lb,
+			healthChecker: newPortHealthChecker(log.With().Str("route", rc.Path).Logger(), pconfig, lb, onHealthChange),
+			handler:       lb.middleware(next),
+		})
+	}
+
+	slices.SortStableFunc(routes, func(a, b *route) int {
+		return cmp.Compare(len(b.config.Path), len(a.config.Path))
+	})
+
+	return routes
+}
+
+// routerMiddleware function dispatches the requests to the route matching the
+// path, requests not matching any route are sent to next.
+func routerMiddleware(routes []*route, next http.Handler) http.Handler {
+	if len(routes) == 0 {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, rt := range routes {
+			if rt.config.Match(r.URL.Path) {
+				rt.handler.ServeHTTP(w, rt.rewrite(r))
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// rewrite method returns the request with the path sent to the route targets.
+func (rt *route) rewrite(r *http.Request) *http.Request {
+	path := rt.config.RewritePath(r.URL.Path)
+	if path == r.URL.Path {
+		return r
+	}
+
+	r2 := new(http.Request)
+	*r2 = *r
+	r2.URL = new(url.URL)
+	*r2.URL = *r.URL
+	r2.URL.Path = path
+	if r.URL.RawPath != "" {
+		r2.URL.RawPath = rt.config.RewritePath(r.URL.RawPath)
+	}
+
+	return r2
+}
@@ -0,0 +1,121 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// newPathTarget function starts a HTTP target that answers its name and the
+// path of the request.
+func newPathTarget(t *testing.T, name string) *url.URL {
+	t.Helper()
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, name+" "+r.URL.Path)
+	}))
+	t.Cleanup(srv.Close)
+
+	target, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return target
+}
+
+// newTestRoute function returns a route of the path to the target.
+func newTestRoute(t *testing.T, path string, target *url.URL) model.Route {
+	t.Helper()
+
+	route, err := model.NewRouteLabel(path + " -> " + target.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return route
+}
+
+func TestPortRoutes(t *testing.T) {
+	loadTestConfig(t, "")
+
+	pconfig := newTestPort(t, "443/https:80/http", newPathTarget(t, "web"))
+
+	api := newTestRoute(t, "/api", newPathTarget(t, "api"))
+	api.StripPrefix = true
+	users := newTestRoute(t, "/api/users", newPathTarget(t, "users"))
+	users.Rewrite = "/v2/users"
+	pconfig.Routes = []model.Route{api, users}
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(p.httpServer.Handler)
+	t.Cleanup(srv.Close)
+
+	tests := map[string]string{
+		"/":             "web /",
+		"/apis":         "web /apis",
+		"/api":          "api /",
+		"/api/items":    "api /items",
+		"/api/users":    "users /v2/users",
+		"/api/users/42": "users /v2/users/42",
+	}
+
+	for path, want := range tests {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(body) != want {
+			t.Errorf("%s: got %q, want %q", path, body, want)
+		}
+	}
+}
+
+func TestPortOnlyRoutes(t *testing.T) {
+	loadTestConfig(t, "")
+
+	pconfig, err := model.NewPortShortLabel("443/https")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pconfig.Routes = []model.Route{newTestRoute(t, "/api", newPathTarget(t, "api"))}
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for path, status := range map[string]int{"/api/items": http.StatusOK, "/": http.StatusNotFound} {
+		w := httptest.NewRecorder()
+		p.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != status {
+			t.Errorf("%s: got %d, want %d", path, w.Code, status)
+		}
+	}
+}
@@ -0,0 +1,259 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/metrics"
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+// streamDialTimeout is the time to wait to connect to a target.
+const streamDialTimeout = 10 * time.Second
+
+type (
+	// streamServer struct forwards raw TCP or UDP connections to the port targets.
+	streamServer struct {
+		log         zerolog.Logger
+		balancer    *balancer
+		access      *accessControl
+		whois       func(ctx context.Context, conn net.Conn) model.Whois
+		proxyName   string
+		portName    string
+		conns       map[net.Conn]struct{}
+		network     string
+		idleTimeout time.Duration
+		active      atomic.Int64
+		total       atomic.Uint64
+		accessLog   bool
+		mtx         sync.Mutex
+	}
+
+	// activityConn wraps a net.Conn and records the last time data was transferred.
+	activityConn struct {
+		net.Conn
+		lastActivity *atomic.Int64
+	}
+)
+
+// newStreamServer function returns a streamServer for the port.
+func newStreamServer(log zerolog.Logger, pconfig model.PortConfig, proxyName string, lb *balancer, accessLog bool) *streamServer {
+	idleTimeout := pconfig.IdleTimeout
+	if idleTimeout <= 0 {
+		idleTimeout = model.DefaultTCPIdleTimeout
+		if pconfig.ProxyProtocol == model.ProtocolUDP {
+			idleTimeout = model.DefaultUDPIdleTimeout
+		}
+	}
+
+	return &streamServer{
+		log:         log,
+		balancer:    lb,
+		proxyName:   proxyName,
+		portName:    pconfig.String(),
+		network:     pconfig.ProxyProtocol,
+		idleTimeout: idleTimeout,
+		accessLog:   accessLog,
+		conns:       make(map[net.Conn]struct{}),
+	}
+}
+
+// serve method accepts connections until the listener is closed.
+func (s *streamServer) serve(ctx context.Context, l net.Listener) error {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			return err
+		}
+
+		go s.handle(ctx, conn)
+	}
+}
+
+// handle method connects a client to a target and copies data in both directions
+// until one of the sides closes or the connection is idle.
+func (s *streamServer) handle(ctx context.Context, client net.Conn) {
+	defer client.Close()
+
+	if s.access != nil {
+		if who := s.whois(ctx, client); !s.access.allowed(who) {
+			s.log.Warn().Str("user", who.Username).Strs("tags", who.Tags).
+				Str("client", client.RemoteAddr().String()).Msg("connection denied")
+			return
+		}
+	}
+
+	target := s.balancer.pick()
+	if target == nil {
+		s.log.Error().Str("client", client.RemoteAddr().String()).Msg("no target available")
+		return
+	}
+
+	target.active.Add(1)
+	defer target.active.Add(-1)
+
+	dialer := net.Dialer{Timeout: streamDialTimeout}
+	upstream, err := dialer.DialContext(ctx, s.network, targetAddress(target.url))
+	if err != nil {
+		s.log.Error().Err(err).Str("client", client.RemoteAddr().String()).
+			Str("target", target.url.String()).Msg("error connecting to target")
+		return
+	}
+	defer upstream.Close()
+
+	s.track(client, upstream)
+	defer s.untrack(client, upstream)
+
+	start := time.Now()
+	sent, received := s.pipe(client, upstream)
+
+	metrics.ObserveStream(s.proxyName, s.portName, sent, received)
+
+	if s.accessLog {
+		s.log.Info().
+			Str("client", client.RemoteAddr().String()).
+			Str("target", target.url.String()).
+			Int64("sent", sent).
+			Int64("received", received).
+			Dur("duration", time.Since(start)).
+			Int64("active", s.active.Load()).
+			Uint64("total", s.total.Load()).
+			Msg("connection")
+	}
+}
+
+// pipe method copies data between both connections and returns the bytes sent
+// to and received from the target. Connections idle for longer than the idle
+// timeout are closed.
+func (s *streamServer) pipe(client, upstream net.Conn) (int64, int64) {
+	lastActivity := new(atomic.Int64)
+	lastActivity.Store(time.Now().UnixNano())
+
+	clientConn := &activityConn{Conn: client, lastActivity: lastActivity}
+	upstreamConn := &activityConn{Conn: upstream, lastActivity: lastActivity}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		ticker := time.NewTicker(s.idleTimeout / 2) //nolint:mnd
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if time.Since(time.Unix(0, lastActivity.Load())) > s.idleTimeout {
+					s.log.Debug().Str("client", client.RemoteAddr().String()).Msg("closing idle connection")
+					client.Close()
+					upstream.Close()
+					return
+				}
+			}
+		}
+	}()
+
+	var (
+		sent, received int64
+		wg             sync.WaitGroup
+	)
+
+	wg.Add(2) //nolint:mnd
+
+	go func() {
+		defer wg.Done()
+		sent, _ = io.Copy(upstreamConn, clientConn)
+		closeWrite(upstream)
+	}()
+
+	go func() {
+		defer wg.Done()
+		received, _ = io.Copy(clientConn, upstreamConn)
+		closeWrite(client)
+	}()
+
+	wg.Wait()
+
+	return sent, received
+}
+
+// connections method returns the number of active connections.
+func (s *streamServer) connections() int64 {
+	return s.active.Load()
+}
+
+// close method closes all active connections.
+func (s *streamServer) close() error {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	var errs error
+	for conn := range s.conns {
+		if err := conn.Close(); err != nil && !errors.Is(err, net.ErrClosed) {
+			errs = errors.Join(errs, err)
+		}
+	}
+	clear(s.conns)
+
+	return errs
+}
+
+func (s *streamServer) track(conns ...net.Conn) {
+	s.active.Add(1)
+	s.total.Add(1)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, conn := range conns {
+		s.conns[conn] = struct{}{}
+	}
+}
+
+func (s *streamServer) untrack(conns ...net.Conn) {
+	s.active.Add(-1)
+
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	for _, conn := range conns {
+		delete(s.conns, conn)
+	}
+}
+
+func (c *activityConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+func (c *activityConn) Write(b []byte) (int, error) {
+	n, err := c.Conn.Write(b)
+	if n > 0 {
+		c.lastActivity.Store(time.Now().UnixNano())
+	}
+	return n, err
+}
+
+// closeWrite function half closes the connection if supported,
+// so the other side receives EOF while responses can still be read.
+func closeWrite(conn net.Conn) {
+	if cw, ok := conn.(interface{ CloseWrite() error }); ok {
+		_ = cw.CloseWrite()
+		return
+	}
+	_ = conn.Close()
+}
@@ -0,0 +1,161 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// startEchoTarget function starts a TCP server that echoes the data it
+// receives and returns its URL.
+func startEchoTarget(t *testing.T) *url.URL {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	return &url.URL{Scheme: model.ProtocolTCP, Host: l.Addr().String()}
+}
+
+// startStreamServer function serves the stream server on a local listener and
+// returns its address.
+func startStreamServer(t *testing.T, s *streamServer) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() { _ = s.serve(context.Background(), l) }()
+
+	return l.Addr().String()
+}
+
+func TestStreamServer(t *testing.T) {
+	pconfig := newTestPort(t, "5432/tcp:5432/tcp", startEchoTarget(t))
+	s := newStreamServer(zerolog.Nop(), pconfig, "db", newBalancer(pconfig.LoadBalance, pconfig.GetTargets()), false)
+	addr := startStreamServer(t, s)
+
+	for range 2 {
+		if !streamEcho(t, addr) {
+			t.Fatal("data not forwarded to the target")
+		}
+	}
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.connections() != 0 {
+		if time.Now().After(deadline) {
+			t.Fatalf("active connections: got %d, want 0", s.connections())
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if s.total.Load() != 2 {
+		t.Errorf("total connections: got %d, want 2", s.total.Load())
+	}
+}
+
+func TestStreamServerNoTarget(t *testing.T) {
+	pconfig := newTestPort(t, "5432/tcp:5432/tcp", startEchoTarget(t))
+	lb := newBalancer(pconfig.LoadBalance, pconfig.GetTargets())
+	lb.backends[0].setHealth(model.HealthStatusUnhealthy)
+	addr := startStreamServer(t, newStreamServer(zerolog.Nop(), pconfig, "db", lb, false))
+
+	if streamEcho(t, addr) {
+		t.Error("connection forwarded to an unhealthy target")
+	}
+}
+
+func TestStreamServerIdleTimeout(t *testing.T) {
+	pconfig := newTestPort(t, "5432/tcp:5432/tcp", startEchoTarget(t))
+	pconfig.IdleTimeout = 50 * time.Millisecond
+	s := newStreamServer(zerolog.Nop(), pconfig, "db", newBalancer(pconfig.LoadBalance, pconfig.GetTargets()), false)
+
+	conn, err := net.Dial("tcp", startStreamServer(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	// the idle connection is closed by the server
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("idle connection not closed: %v", err)
+	}
+}
+
+func TestStreamServerClose(t *testing.T) {
+	pconfig := newTestPort(t, "5432/tcp:5432/tcp", startEchoTarget(t))
+	s := newStreamServer(zerolog.Nop(), pconfig, "db", newBalancer(pconfig.LoadBalance, pconfig.GetTargets()), false)
+
+	conn, err := net.Dial("tcp", startStreamServer(t, s))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for s.connections() != 1 {
+		if time.Now().After(deadline) {
+			t.Fatal("connection not tracked")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if err := s.close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := conn.SetDeadline(time.Now().Add(5 * time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.ReadAll(conn); err != nil {
+		t.Fatalf("connection not closed: %v", err)
+	}
+}
+
+func TestStreamIdleTimeoutDefaults(t *testing.T) {
+	for label, want := range map[string]time.Duration{
+		"5432/tcp:5432/tcp": model.DefaultTCPIdleTimeout,
+		"53/udp:53/udp":     model.DefaultUDPIdleTimeout,
+	} {
+		pconfig, err := model.NewPortLongLabel(label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !pconfig.IsStream() {
+			t.Errorf("%s: not a stream port", label)
+		}
+		if got := newStreamServer(zerolog.Nop(), pconfig, "dns", nil, false).idleTimeout; got != want {
+			t.Errorf("%s: idle timeout got %s, want %s", label, got, want)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"errors"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// supervisor struct restarts failed proxies with exponential backoff.
+	supervisor struct {
+		log      zerolog.Logger
+		restarts map[string]*restartState
+		restart  func(proxy *Proxy) error
+		mtx      sync.Mutex
+	}
+
+	// restartState struct stores the restart attempts of a proxy.
+	restartState struct {
+		timer *time.Timer
+		// proxy is the failed proxy, the restart is canceled if it is replaced
+		proxy      *Proxy
+		attempts   int
+		restarting bool
+	}
+)
+
+// newSupervisor function returns a supervisor that restarts proxies with the
+// restart function.
+func newSupervisor(log zerolog.Logger, restart func(proxy *Proxy) error) *supervisor {
+	return &supervisor{
+		log:      log.With().Str("module", "supervisor").Logger(),
+		restarts: make(map[string]*restartState),
+		restart:  restart,
+	}
+}
+
+// onProxyEvent method schedules a restart when the proxy fails and resets the
+// attempts when it is running.
+func (s *supervisor) onProxyEvent(proxy *Proxy, event model.ProxyEvent) {
+	// port and target events don't change the proxy status
+	if event.Port != "" || event.Target != "" {
+		return
+	}
+
+	switch event.Status {
+	case model.ProxyStatusError:
+		s.schedule(proxy, event.ID)
+	case model.ProxyStatusRunning, model.ProxyStatusDegraded:
+		s.reset(event.ID)
+	}
+}
+
+// schedule method schedules the next restart of a failed proxy, unless the
+// restarts are disabled or the maximum number of attempts is reached.
+func (s *supervisor) schedule(proxy *Proxy, name string) {
+	cfg := config.Get().Restart
+	if cfg.Disabled {
+		return
+	}
+
+	s.mtx.Lock()
+	state, ok := s.restarts[name]
+	if !ok {
+		state = &restartState{}
+		s.restarts[name] = state
+	}
+
+	if state.timer != nil {
+		s.mtx.Unlock()
+		return
+	}
+
+	if cfg.MaxAttempts > 0 && state.attempts >= cfg.MaxAttempts {
+		s.mtx.Unlock()
+		s.log.Warn().Str("proxy", name).Int("attempts", state.attempts).Msg("Giving up restarting proxy")
+		return
+	}
+
+	state.attempts++
+	state.proxy = proxy
+	attempt := state.attempts
+	delay := backoff(cfg.InitialDelay, cfg.MaxDelay, attempt)
+	state.timer = time.AfterFunc(delay, func() { s.run(name, state) })
+	s.mtx.Unlock()
+
+	s.log.Info().Str("proxy", name).Int("attempt", attempt).Dur("delay", delay).Msg("Scheduling proxy restart")
+
+	proxy.setRestarting(attempt)
+}
+
+// run method restarts the proxy, if it wasn't stopped or replaced since the
+// restart was scheduled.
+func (s *supervisor) run(name string, state *restartState) {
+	s.mtx.Lock()
+	if s.restarts[name] != state {
+		s.mtx.Unlock()
+		return
+	}
+	state.timer = nil
+	state.restarting = true
+	proxy := state.proxy
+	s.mtx.Unlock()
+
+	defer func() {
+		s.mtx.Lock()
+		state.restarting = false
+		s.mtx.Unlock()
+	}()
+
+	if proxy.GetStatus() != model.ProxyStatusRestarting {
+		return
+	}
+
+	s.log.Info().Str("proxy", name).Int("attempt", state.attempts).Msg("Restarting proxy")
+
+	err := s.restart(proxy)
+	switch {
+	case errors.Is(err, ErrProxyNotFound):
+		s.log.Debug().Str("proxy", name).Msg("Proxy removed, restart canceled")
+	case err != nil:
+		s.log.Error().Err(err).Str("proxy", name).Msg("Error restarting proxy")
+	}
+}
+
+// cancel method cancels the pending restart of a removed proxy. The attempts
+// are kept while the supervisor is restarting the proxy.
+func (s *supervisor) cancel(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	state, ok := s.restarts[name]
+	if !ok || state.restarting {
+		return
+	}
+
+	if state.timer != nil {
+		state.timer.Stop()
+	}
+	delete(s.restarts, name)
+}
+
+// reset method clears the restart attempts of a running proxy.
+func (s *supervisor) reset(name string) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if state, ok := s.restarts[name]; ok && state.timer == nil {
+		delete(s.restarts, name)
+	}
+}
+
+// backoff function returns the delay of a restart attempt, doubling the
+// initial delay on each attempt up to max, with a random jitter of up to half
+// of the delay.
+func backoff(initial, maxDelay time.Duration, attempt int) time.Duration {
+	delay := initial
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxDelay)
+
+	if delay <= 0 {
+		return 0
+	}
+
+	jitter := rand.N(delay/2 + 1) //nolint:gosec,mnd
+
+	return delay - jitter
+}
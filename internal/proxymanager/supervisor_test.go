@@ -0,0 +1,178 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// restartConfig is the configuration of fast restarts, given up after two attempts.
+const restartConfig = `
+restart:
+  initialDelay: 10ms
+  maxDelay: 20ms
+  maxAttempts: 2
+`
+
+// noRestartWait is the time to wait to check that no restart is attempted
+const noRestartWait = 200 * time.Millisecond
+
+var errStart = errors.New("start failed")
+
+// newFailedProxy function returns a proxy that failed to start.
+func newFailedProxy(t *testing.T) *Proxy {
+	t.Helper()
+
+	provider := newFakeProvider()
+	provider.startErr = errStart
+
+	proxy, err := NewProxy(zerolog.Nop(), &model.Config{
+		Hostname: "web",
+		Ports:    model.PortConfigList{"web": newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))},
+	}, provider)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(proxy.Close)
+
+	proxy.Start()
+	waitStatus(t, proxy, model.ProxyStatusError)
+
+	return proxy
+}
+
+// errorEvent function returns the error event of the proxy.
+func errorEvent(proxy *Proxy) model.ProxyEvent {
+	return model.ProxyEvent{ID: proxy.Config.Hostname, Status: model.ProxyStatusError, Error: proxy.GetError()}
+}
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{attempt: 1, min: 5 * time.Second, max: 10 * time.Second},
+		{attempt: 2, min: 10 * time.Second, max: 20 * time.Second},
+		{attempt: 3, min: 20 * time.Second, max: 40 * time.Second},
+		{attempt: 10, min: 30 * time.Second, max: time.Minute},
+	}
+
+	for _, tt := range tests {
+		for range 100 {
+			if got := backoff(10*time.Second, time.Minute, tt.attempt); got < tt.min || got > tt.max {
+				t.Fatalf("attempt %d: got %s, want between %s and %s", tt.attempt, got, tt.min, tt.max)
+			}
+		}
+	}
+
+	if got := backoff(0, time.Minute, 3); got != 0 { //nolint:mnd
+		t.Errorf("no delay: got %s", got)
+	}
+}
+
+func TestSupervisorRestart(t *testing.T) {
+	loadTestConfig(t, restartConfig)
+
+	proxy := newFailedProxy(t)
+
+	// the restarted proxy fails again
+	restarts := make(chan int, 10) //nolint:mnd
+	var s *supervisor
+	s = newSupervisor(zerolog.Nop(), func(p *Proxy) error {
+		restarts <- p.restarts
+		p.setError(errStart)
+		s.onProxyEvent(p, errorEvent(p))
+
+		return nil
+	})
+
+	s.onProxyEvent(proxy, errorEvent(proxy))
+	if got := proxy.GetStatus(); got != model.ProxyStatusRestarting {
+		t.Errorf("status: got %s, want Restarting", got.String())
+	}
+
+	for want := 1; want <= 2; want++ {
+		select {
+		case attempt := <-restarts:
+			if attempt != want {
+				t.Errorf("attempt: got %d, want %d", attempt, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("attempt %d not restarted", want)
+		}
+	}
+
+	// restarts are given up after the maximum attempts
+	select {
+	case attempt := <-restarts:
+		t.Errorf("restarted after the maximum attempts: %d", attempt)
+	case <-time.After(noRestartWait):
+	}
+	if got := proxy.GetStatus(); got != model.ProxyStatusError {
+		t.Errorf("status: got %s, want Error", got.String())
+	}
+
+	// the attempts restart from zero once the proxy runs
+	s.onProxyEvent(proxy, model.ProxyEvent{ID: "web", Status: model.ProxyStatusRunning})
+	s.onProxyEvent(proxy, errorEvent(proxy))
+	select {
+	case attempt := <-restarts:
+		if attempt != 1 {
+			t.Errorf("attempt after running: got %d, want 1", attempt)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("not restarted after running")
+	}
+}
+
+func TestSupervisorCancel(t *testing.T) {
+	loadTestConfig(t, restartConfig)
+
+	proxy := newFailedProxy(t)
+
+	restarts := make(chan struct{}, 1)
+	s := newSupervisor(zerolog.Nop(), func(*Proxy) error {
+		restarts <- struct{}{}
+		return nil
+	})
+
+	// port events don't restart the proxy
+	s.onProxyEvent(proxy, model.ProxyEvent{ID: "web", Port: "web", Status: model.ProxyStatusError})
+	if got := proxy.GetStatus(); got != model.ProxyStatusError {
+		t.Errorf("status after a port event: got %s", got.String())
+	}
+
+	s.onProxyEvent(proxy, errorEvent(proxy))
+	s.cancel("web")
+
+	select {
+	case <-restarts:
+		t.Error("canceled restart attempted")
+	case <-time.After(noRestartWait):
+	}
+}
+
+func TestSupervisorDisabled(t *testing.T) {
+	loadTestConfig(t, restartConfig+"  disabled: true\n")
+
+	proxy := newFailedProxy(t)
+
+	s := newSupervisor(zerolog.Nop(), func(*Proxy) error {
+		t.Error("restart attempted")
+		return nil
+	})
+	s.onProxyEvent(proxy, errorEvent(proxy))
+
+	time.Sleep(noRestartWait)
+	if got := proxy.GetStatus(); got != model.ProxyStatusError {
+		t.Errorf("status: got %s, want Error", got.String())
+	}
+}
@@ -0,0 +1,111 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// transport struct is the http.RoundTripper to the targets of a port. It selects
+// the HTTP version from the target protocol.
+type transport struct {
+	http1 *http.Transport
+	h2c   *http.Transport
+	h2    *http.Transport
+}
+
+var _ http.RoundTripper = (*transport)(nil)
+
+// newTransport function returns the transport to the targets with the dial,
+// keep-alive and idle connection settings. Zero settings use the defaults of
+// net/http.
+func newTransport(tlsValidate bool, disableKeepAlives bool, settings model.Transport) *transport {
+	tlsConfig := &tls.Config{InsecureSkipVerify: !tlsValidate} //nolint
+
+	// a negative keep-alive disables TCP keep-alives
+	dialer := &net.Dialer{
+		Timeout:   max(settings.DialTimeout, 0),
+		KeepAlive: settings.KeepAlive,
+	}
+
+	newHTTPTransport := func() *http.Transport {
+		return &http.Transport{
+			DialContext:           dialer.DialContext,
+			ResponseHeaderTimeout: max(settings.ResponseHeaderTimeout, 0),
+			IdleConnTimeout:       max(settings.IdleConnTimeout, 0),
+			MaxIdleConns:          settings.MaxIdleConns,
+			MaxIdleConnsPerHost:   settings.MaxIdleConnsPerHost,
+			DisableKeepAlives:     disableKeepAlives,
+		}
+	}
+
+	// HTTP/2 without TLS, using prior knowledge
+	h2cProtocols := new(http.Protocols)
+	h2cProtocols.SetUnencryptedHTTP2(true)
+
+	// HTTP/2 over TLS, without fallback to HTTP/1.1
+	h2Protocols := new(http.Protocols)
+	h2Protocols.SetHTTP2(true)
+
+	http1 := newHTTPTransport()
+	http1.TLSClientConfig = tlsConfig
+
+	h2c := newHTTPTransport()
+	h2c.Protocols = h2cProtocols
+
+	h2 := newHTTPTransport()
+	h2.Protocols = h2Protocols
+	h2.TLSClientConfig = tlsConfig.Clone()
+	h2.ForceAttemptHTTP2 = true
+
+	return &transport{
+		http1: http1,
+		h2c:   h2c,
+		h2:    h2,
+	}
+}
+
+// portTransport function returns the transport settings of a port, with the
+// global defaults of the configuration on the settings not configured.
+func portTransport(pconfig model.PortConfig) model.Transport {
+	defaults := config.Get().Transport
+
+	return pconfig.Transport.WithDefaults(model.Transport{
+		DialTimeout:           defaults.DialTimeout,
+		KeepAlive:             defaults.KeepAlive,
+		ResponseHeaderTimeout: defaults.ResponseHeaderTimeout,
+		IdleConnTimeout:       defaults.IdleConnTimeout,
+		ReadHeaderTimeout:     defaults.ReadHeaderTimeout,
+		ReadTimeout:           defaults.ReadTimeout,
+		WriteTimeout:          defaults.WriteTimeout,
+		MaxIdleConns:          defaults.MaxIdleConns,
+		MaxIdleConnsPerHost:   defaults.MaxIdleConnsPerHost,
+	})
+}
+
+// RoundTrip method implements http.RoundTripper.
+func (t *transport) RoundTrip(r *http.Request) (*http.Response, error) {
+	switch r.URL.Scheme {
+	case model.ProtocolGRPC, model.ProtocolH2C:
+		return t.h2c.RoundTrip(withScheme(r, "http"))
+	case model.ProtocolHTTPSH2:
+		return t.h2.RoundTrip(withScheme(r, "https"))
+	default:
+		return t.http1.RoundTrip(r)
+	}
+}
+
+// withScheme function returns a copy of the request to the target with the scheme
+// understood by http.Transport.
+func withScheme(r *http.Request, scheme string) *http.Request {
+	out := r.Clone(r.Context())
+	out.URL.Scheme = scheme
+
+	return out
+}
@@ -0,0 +1,185 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package proxymanager
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// protoHandler is a target that answers the protocol of the request, with a
+// trailer like gRPC.
+var protoHandler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Trailer", "Grpc-Status")
+	_, _ = io.WriteString(w, r.Proto)
+	w.Header().Set("Grpc-Status", "0")
+})
+
+// serveTestPort function serves a HTTP port with the target and returns its
+// response to a HTTP/2 request.
+func serveTestPort(t *testing.T, label string, target *url.URL) *http.Response {
+	t.Helper()
+
+	loadTestConfig(t, "")
+
+	pconfig := newTestPort(t, label, target)
+	proxyConfig := &model.Config{Hostname: "grpc"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// gRPC clients use HTTP/2, like the Tailscale listeners of the ports
+	srv := httptest.NewUnstartedServer(p.httpServer.Handler)
+	srv.EnableHTTP2 = true
+	srv.StartTLS()
+	t.Cleanup(srv.Close)
+
+	resp, err := srv.Client().Post(srv.URL+"/echo.Echo/Say", "application/grpc", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	return resp
+}
+
+// checkProto function checks the protocol used by the target and the trailer.
+func checkProto(t *testing.T, resp *http.Response, proto string) {
+	t.Helper()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != proto {
+		t.Errorf("target protocol: got %s, want %s", body, proto)
+	}
+	if got := resp.Trailer.Get("Grpc-Status"); got != "0" {
+		t.Errorf("trailer: got %q, want 0", got)
+	}
+}
+
+func TestTransportH2C(t *testing.T) {
+	target := httptest.NewUnstartedServer(protoHandler)
+	target.Config.Protocols = new(http.Protocols)
+	target.Config.Protocols.SetUnencryptedHTTP2(true)
+	target.Start()
+	t.Cleanup(target.Close)
+
+	for _, scheme := range []string{model.ProtocolH2C, model.ProtocolGRPC} {
+		resp := serveTestPort(t, "443/https:80/"+scheme, &url.URL{Scheme: scheme, Host: target.Listener.Addr().String()})
+		checkProto(t, resp, "HTTP/2.0")
+	}
+}
+
+func TestTransportHTTPSH2(t *testing.T) {
+	target := httptest.NewUnstartedServer(protoHandler)
+	target.EnableHTTP2 = true
+	target.StartTLS()
+	t.Cleanup(target.Close)
+
+	resp := serveTestPort(t, "443/https:443/https+h2",
+		&url.URL{Scheme: model.ProtocolHTTPSH2, Host: target.Listener.Addr().String()})
+	checkProto(t, resp, "HTTP/2.0")
+}
+
+func TestTransportHTTP1(t *testing.T) {
+	target := httptest.NewServer(protoHandler)
+	t.Cleanup(target.Close)
+
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkProto(t, serveTestPort(t, "443/https:80/http", u), "HTTP/1.1")
+}
+
+func TestPortTransport(t *testing.T) {
+	loadTestConfig(t, `
+transport:
+  dialTimeout: 5s
+  responseHeaderTimeout: 1m
+  readTimeout: 30s
+  writeTimeout: 30s
+  maxIdleConnsPerHost: 4
+`)
+
+	pconfig := newTestPort(t, "443/https:80/http", newTestTarget(t, "web"))
+	pconfig.Transport = model.Transport{DialTimeout: time.Second, WriteTimeout: -1}
+
+	// the port settings replace the global defaults
+	want := model.Transport{
+		DialTimeout:           time.Second,
+		KeepAlive:             30 * time.Second,
+		ResponseHeaderTimeout: time.Minute,
+		IdleConnTimeout:       90 * time.Second,
+		ReadHeaderTimeout:     5 * time.Second,
+		ReadTimeout:           30 * time.Second,
+		WriteTimeout:          -1,
+		MaxIdleConns:          100, //nolint:mnd
+		MaxIdleConnsPerHost:   4,   //nolint:mnd
+	}
+	if got := portTransport(pconfig); got != want {
+		t.Errorf("transport: got %+v, want %+v", got, want)
+	}
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// negative timeouts are disabled
+	if p.httpServer.ReadTimeout != 30*time.Second || p.httpServer.WriteTimeout != 0 {
+		t.Errorf("server timeouts: read %s, write %s", p.httpServer.ReadTimeout, p.httpServer.WriteTimeout)
+	}
+}
+
+func TestTransportResponseHeaderTimeout(t *testing.T) {
+	loadTestConfig(t, "")
+
+	target := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	t.Cleanup(target.Close)
+
+	u, err := url.Parse(target.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pconfig := newTestPort(t, "443/https:80/http", u)
+	pconfig.Transport.ResponseHeaderTimeout = 50 * time.Millisecond
+
+	proxyConfig := &model.Config{Hostname: "web"}
+	errPages := newErrorPages(zerolog.Nop(), proxyConfig, func() bool { return false })
+	p, err := newPortProxy(context.Background(), pconfig, zerolog.Nop(), proxyConfig, testWhois(alice), nil, errPages,
+		nil, func(*backend) {})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	p.httpServer.Handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusGatewayTimeout {
+		t.Errorf("slow target: got %d, want %d", w.Code, http.StatusGatewayTimeout)
+	}
+}
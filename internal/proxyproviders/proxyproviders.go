@@ -26,5 +26,8 @@ type (
 		GetAuthURL() string
 		WatchEvents() chan model.ProxyEvent
 		Whois(r *http.Request) model.Whois
+		// ConnContext adds the provider information of a connection to the context
+		// of its HTTP requests, used as http.Server ConnContext.
+		ConnContext(ctx context.Context, c net.Conn) context.Context
 	}
 )
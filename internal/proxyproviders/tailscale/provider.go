@@ -40,7 +40,7 @@ type (
 var _ proxyproviders.Provider = (*Client)(nil)
 
 func New(log zerolog.Logger, name string, provider *config.TailscaleServerConfig) (*Client, error) {
-	datadir := filepath.Join(config.Config.Tailscale.DataDir, name)
+	datadir := filepath.Join(config.Get().Tailscale.DataDir, name)
 
 	return &Client{
 		log:          log.With().Str("tailscale", name).Logger(),
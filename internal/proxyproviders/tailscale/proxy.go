@@ -5,6 +5,7 @@ package tailscale
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"net"
 	"net/http"
@@ -18,9 +19,15 @@ import (
 	"github.com/rs/zerolog"
 	"tailscale.com/client/local"
 	"tailscale.com/ipn"
+	"tailscale.com/tailcfg"
 	"tailscale.com/tsnet"
 )
 
+// groupsCapability struct is the value of the model.CapabilityGroups peer capability.
+type groupsCapability struct {
+	Groups []string `json:"groups"`
+}
+
 // Proxy struct implements proxyconfig.Proxy.
 type Proxy struct {
 	log      zerolog.Logger
@@ -42,6 +49,7 @@ var (
 	_ proxyproviders.ProxyInterface = (*Proxy)(nil)
 
 	ErrProxyPortNotFound = errors.New("proxy port not found")
+	ErrHTTPSNotEnabled   = errors.New("tsnet: you must enable MagicDNS and HTTPS in the admin panel to proceed. See https://tailscale.com/s/https")
 )
 
 // Start method implements proxyconfig.Proxy Start method.
@@ -103,14 +111,45 @@ func (p *Proxy) GetListener(port string) (net.Listener, error) {
 	addr := ":" + strconv.Itoa(portCfg.ProxyPort)
 
 	if portCfg.Tailscale.Funnel {
-		return p.tsServer.ListenFunnel(network, addr)
+		return p.tsServer.ListenFunnel(network, addr, tsnet.FunnelTLSConfig(p.tlsConfig()))
 	}
 	if portCfg.ProxyProtocol == "https" {
-		return p.tsServer.ListenTLS(network, addr)
+		return p.listenTLS(network, addr)
 	}
 	return p.tsServer.Listen(network, addr)
 }
 
+// listenTLS method listens for TLS connections with the Tailscale certificate,
+// like tsnet.Server ListenTLS, but also negotiates HTTP/2 with the clients.
+func (p *Proxy) listenTLS(network, addr string) (net.Listener, error) {
+	st, err := p.tsServer.Up(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	if len(st.CertDomains) == 0 {
+		return nil, ErrHTTPSNotEnabled
+	}
+
+	ln, err := p.tsServer.Listen(network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	return tls.NewListener(ln, p.tlsConfig()), nil
+}
+
+// tlsConfig method returns the TLS configuration of the HTTPS ports.
+func (p *Proxy) tlsConfig() *tls.Config {
+	p.mtx.Lock()
+	lc := p.lc
+	p.mtx.Unlock()
+
+	return &tls.Config{
+		GetCertificate: lc.GetCertificate,
+		NextProtos:     []string{"h2", "http/1.1"},
+	}
+}
+
 func (p *Proxy) WatchEvents() chan model.ProxyEvent {
 	return p.events
 }
@@ -120,17 +159,50 @@ func (p *Proxy) GetAuthURL() string {
 }
 
 func (p *Proxy) Whois(r *http.Request) model.Whois {
+	// Funnel clients are on the internet, without Tailscale identity
+	if _, ok := model.FunnelClientFromContext(r.Context()); ok {
+		return model.Whois{}
+	}
+
 	who, err := p.lc.WhoIs(r.Context(), r.RemoteAddr)
 	if err != nil {
 		return model.Whois{}
 	}
 
-	return model.Whois{
+	whois := model.Whois{
 		DisplayName:   who.UserProfile.DisplayName,
 		Username:      who.UserProfile.LoginName,
 		ID:            who.UserProfile.ID.String(),
 		ProfilePicURL: who.UserProfile.ProfilePicURL,
 	}
+
+	if who.Node != nil {
+		whois.Tags = who.Node.Tags
+	}
+
+	groups, err := tailcfg.UnmarshalCapJSON[groupsCapability](who.CapMap, model.CapabilityGroups)
+	if err != nil {
+		p.log.Warn().Err(err).Msg("invalid groups capability")
+	}
+	for _, g := range groups {
+		whois.Groups = append(whois.Groups, g.Groups...)
+	}
+
+	return whois
+}
+
+// ConnContext method implements ProxyInterface ConnContext method. The remote
+// address of Funnel connections is the Funnel relay, so the address of the
+// internet client is added to the context.
+func (p *Proxy) ConnContext(ctx context.Context, c net.Conn) context.Context {
+	if tc, ok := c.(*tls.Conn); ok {
+		c = tc.NetConn()
+	}
+	if fc, ok := c.(*ipn.FunnelConn); ok {
+		return model.FunnelClientNewContext(ctx, fc.Src.String())
+	}
+
+	return ctx
 }
 
 func (p *Proxy) watchStatus() {
@@ -152,6 +224,10 @@ func (p *Proxy) watchStatus() {
 
 		if n.ErrMessage != nil {
 			p.log.Error().Str("error", *n.ErrMessage).Msg("tailscale.watchStatus: backend")
+			p.events <- model.ProxyEvent{
+				Status: model.ProxyStatusError,
+				Error:  *n.ErrMessage,
+			}
 			return
 		}
 
@@ -18,6 +18,7 @@ const (
 	LabelContainerAccessLog = LabelPrefix + "containeraccesslog"
 	LabelProxyProvider      = LabelPrefix + "proxyprovider"
 	LabelPort               = LabelPrefix + "port."
+	LabelReplicas           = LabelPrefix + "replicas"
 	// Tailscale
 	LabelEphemeral    = LabelPrefix + "ephemeral"
 	LabelRunWebClient = LabelPrefix + "runwebclient"
@@ -32,6 +33,23 @@ const (
 	LabelTLSValidate   = LabelPrefix + "tlsvalidate"
 	// Legacy Tailscale
 	LabelFunnel = LabelPrefix + "funnel"
+	// Access control labels, also used as port sub labels
+	LabelAccess            = "access."
+	LabelAccessAllow       = LabelAccess + "allow."
+	LabelAccessDeny        = LabelAccess + "deny."
+	LabelAccessDeniedPage  = LabelAccess + "deniedpage"
+	LabelAccessRuleUsers   = "users"
+	LabelAccessRuleUserIDs = "userids"
+	LabelAccessRuleTags    = "tags"
+	LabelAccessRuleGroups  = "groups"
+	// Rate limit labels, also used as port sub labels
+	LabelRateLimit      = "ratelimit"
+	LabelRateLimitBurst = LabelRateLimit + ".burst"
+	// Access log labels
+	LabelAccessLog       = LabelPrefix + "accesslog."
+	LabelAccessLogFormat = LabelAccessLog + "format"
+	LabelAccessLogOutput = LabelAccessLog + "output"
+	LabelAccessLogFile   = LabelAccessLog + "file"
 	// Dashboard config labels
 	LabelDashboardPrefix  = LabelPrefix + "dash."
 	LabelDashboardVisible = LabelDashboardPrefix + "visible"
@@ -49,4 +67,66 @@ const (
 	// Port options
 	PortOptionNoTLSValidate   = "no_tlsvalidate"
 	PortOptionTailscaleFunnel = "tailscale_funnel"
+
+	// Port sub labels, used as tsdproxy.port.<index>.<option>
+	PortLabelLoadBalance                   = "loadbalance"
+	PortLabelIdleTimeout                   = "idletimeout"
+	PortLabelHealthCheck                   = "healthcheck"
+	PortLabelHealthCheckPath               = PortLabelHealthCheck + ".path"
+	PortLabelHealthCheckInterval           = PortLabelHealthCheck + ".interval"
+	PortLabelHealthCheckTimeout            = PortLabelHealthCheck + ".timeout"
+	PortLabelHealthCheckExpectedStatus     = PortLabelHealthCheck + ".status"
+	PortLabelHealthCheckHealthyThreshold   = PortLabelHealthCheck + ".healthy"
+	PortLabelHealthCheckUnhealthyThreshold = PortLabelHealthCheck + ".unhealthy"
+
+	// Cache sub labels, used as tsdproxy.port.<index>.cache.<option>
+	PortLabelCache             = "cache"
+	PortLabelCacheDir          = PortLabelCache + ".dir"
+	PortLabelCacheMaxSize      = PortLabelCache + ".maxsize"
+	PortLabelCacheMaxEntrySize = PortLabelCache + ".maxentrysize"
+
+	// Transport sub labels, used as tsdproxy.port.<index>.transport.<option>
+	PortLabelTransport                      = "transport."
+	PortLabelTransportDialTimeout           = PortLabelTransport + "dialtimeout"
+	PortLabelTransportKeepAlive             = PortLabelTransport + "keepalive"
+	PortLabelTransportResponseHeaderTimeout = PortLabelTransport + "responseheadertimeout"
+	PortLabelTransportIdleConnTimeout       = PortLabelTransport + "idleconntimeout"
+	PortLabelTransportMaxIdleConns          = PortLabelTransport + "maxidleconns"
+	PortLabelTransportMaxIdleConnsPerHost   = PortLabelTransport + "maxidleconnsperhost"
+	PortLabelTransportReadHeaderTimeout     = PortLabelTransport + "readheadertimeout"
+	PortLabelTransportReadTimeout           = PortLabelTransport + "readtimeout"
+	PortLabelTransportWriteTimeout          = PortLabelTransport + "writetimeout"
+
+	// Funnel sub labels, used as tsdproxy.port.<index>.funnel.<option>
+	PortLabelFunnel             = "funnel."
+	PortLabelFunnelBasicAuth    = PortLabelFunnel + "basicauth"
+	PortLabelFunnelBearerTokens = PortLabelFunnel + "bearertokens"
+	PortLabelFunnelShareLinks   = PortLabelFunnel + "sharelinks"
+	PortLabelFunnelShareLinkTTL = PortLabelFunnel + "sharelinkttl"
+	PortLabelFunnelSessionTTL   = PortLabelFunnel + "sessionttl"
+	PortLabelFunnelAllow        = PortLabelFunnel + "allow"
+	PortLabelFunnelDeny         = PortLabelFunnel + "deny"
+	PortLabelFunnelFailureLimit = PortLabelFunnel + "failurelimit"
+
+	// Header sub labels, used as tsdproxy.port.<index>.headers.<request|response>.<rule>
+	PortLabelHeaders         = "headers."
+	PortLabelHeadersRequest  = PortLabelHeaders + "request."
+	PortLabelHeadersResponse = PortLabelHeaders + "response."
+	LabelHeaderSet           = "set."
+	LabelHeaderAdd           = "add."
+	LabelHeaderRemove        = "remove"
+
+	// Route sub labels, used as tsdproxy.port.<index>.route.<name>.<option>
+	PortLabelRoute            = "route."
+	PortLabelRouteStripPrefix = "stripprefix"
+	PortLabelRouteRewrite     = "rewrite"
+
+	// replicas
+	labelComposeProject   = "com.docker.compose.project"
+	labelComposeService   = "com.docker.compose.service"
+	labelSwarmServiceID   = "com.docker.swarm.service.id"
+	labelSwarmServiceName = "com.docker.swarm.service.name"
+	replicaTargetIDPrefix = "replicas:"
+	replicaSettleDelay    = 2 * time.Second
+	replicaGroupSeparator = "/"
 )
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/url"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -115,6 +116,16 @@ func (c *container) setContainerNetwork(dcontainer ctypes.InspectResponse) {
 	}
 }
 
+// NewContainerProxyConfig function returns the proxy configuration of a docker
+// compatible container from its labels. Used by target providers of other
+// container engines, like Podman.
+func NewContainerProxyConfig(log zerolog.Logger, dcontainer ctypes.InspectResponse, autodetect bool,
+	opts ...ContainerOption,
+) (*model.Config, error) {
+	//
+	return newContainer(log, dcontainer, swarm.Service{}, autodetect, opts...).newProxyConfig()
+}
+
 // newProxyConfig method returns a new proxyconfig.Config.
 func (c *container) newProxyConfig() (*model.Config, error) {
 	c.log.Trace().Msg("New ProxyConfig")
@@ -143,7 +154,12 @@ func (c *container) newProxyConfig() (*model.Config, error) {
 	pcfg.TargetProvider = c.targetProviderName
 	pcfg.Tailscale = *tailscale
 	pcfg.ProxyProvider = c.getLabelString(LabelProxyProvider, model.DefaultProxyProvider)
-	pcfg.ProxyAccessLog = c.getLabelBool(LabelContainerAccessLog, model.DefaultProxyAccessLog)
+	pcfg.AccessLog = model.AccessLog{
+		Enabled: c.getLabelBool(LabelContainerAccessLog, model.DefaultProxyAccessLog),
+		Format:  c.getLabelString(LabelAccessLogFormat, ""),
+		Output:  c.getLabelString(LabelAccessLogOutput, ""),
+		File:    c.getLabelString(LabelAccessLogFile, ""),
+	}
 	pcfg.Dashboard.Visible = c.getLabelBool(LabelDashboardVisible, model.DefaultDashboardVisible)
 	pcfg.Dashboard.Label = c.getLabelString(LabelDashboardLabel, pcfg.Hostname)
 
@@ -152,16 +168,25 @@ func (c *container) newProxyConfig() (*model.Config, error) {
 		pcfg.Dashboard.Icon = web.GuessIcon(c.image)
 	}
 
-	pcfg.Ports = c.getPorts()
+	pcfg.AccessControl = c.getAccessControl(LabelPrefix)
+	pcfg.RateLimit = c.getRateLimit(LabelPrefix)
+	pcfg.Ports = c.getPortsWithLegacy()
+
+	return pcfg, nil
+}
+
+// getPortsWithLegacy method returns the ports from port labels
+// or the port from legacy labels if no port configured.
+func (c *container) getPortsWithLegacy() model.PortConfigList {
+	ports := c.getPorts()
 
-	// add port from legacy labels if no port configured
-	if len(pcfg.Ports) == 0 {
+	if len(ports) == 0 {
 		if legacyPort, err := c.getLegacyPort(); err == nil {
-			pcfg.Ports["legacy"] = legacyPort
+			ports["legacy"] = legacyPort
 		}
 	}
 
-	return pcfg, nil
+	return ports
 }
 
 func (c *container) getPorts() model.PortConfigList {
@@ -170,7 +195,8 @@ func (c *container) getPorts() model.PortConfigList {
 
 	ports := make(model.PortConfigList)
 	for k, v := range c.labels {
-		if !strings.HasPrefix(k, LabelPort) {
+		// skip labels that aren't ports and port sub labels
+		if !strings.HasPrefix(k, LabelPort) || strings.Contains(strings.TrimPrefix(k, LabelPort), ".") {
 			continue
 		}
 
@@ -192,6 +218,20 @@ func (c *container) getPorts() model.PortConfigList {
 			}
 		}
 
+		port.LoadBalance = c.getPortLabelString(k, PortLabelLoadBalance, port.LoadBalance)
+		port.HealthCheck = c.getPortHealthCheck(k)
+		port.IdleTimeout = c.getPortLabelDuration(k, PortLabelIdleTimeout, 0)
+		port.Transport = c.getPortTransport(k)
+		port.AccessControl = c.getAccessControl(k + ".")
+		port.RateLimit = c.getRateLimit(k + ".")
+		port.Tailscale.Protection = c.getPortFunnelProtection(k)
+		port.Cache = c.getPortCache(k)
+		port.Routes = c.getPortRoutes(k)
+		port.Headers = model.Headers{
+			Request:  c.getHeaderRules(k + "." + PortLabelHeadersRequest),
+			Response: c.getHeaderRules(k + "." + PortLabelHeadersResponse),
+		}
+
 		if port.IsRedirect {
 			ports[k] = port
 		} else {
@@ -225,6 +265,173 @@ func (c *container) generateTargetFromFirstTarget(port model.PortConfig) (model.
 	return port, nil
 }
 
+// getPortHealthCheck method returns the health check configuration from the port sub labels.
+// Health checks are enabled when tsdproxy.port.<index>.healthcheck is set to http or tcp.
+func (c *container) getPortHealthCheck(portLabel string) model.HealthCheck {
+	checkType := c.getPortLabelString(portLabel, PortLabelHealthCheck, "")
+
+	return model.HealthCheck{
+		Enabled:            checkType == model.HealthCheckHTTP || checkType == model.HealthCheckTCP,
+		Type:               checkType,
+		Path:               c.getPortLabelString(portLabel, PortLabelHealthCheckPath, ""),
+		Interval:           c.getPortLabelDuration(portLabel, PortLabelHealthCheckInterval, 0),
+		Timeout:            c.getPortLabelDuration(portLabel, PortLabelHealthCheckTimeout, 0),
+		ExpectedStatus:     c.getPortLabelInt(portLabel, PortLabelHealthCheckExpectedStatus, 0),
+		HealthyThreshold:   c.getPortLabelInt(portLabel, PortLabelHealthCheckHealthyThreshold, 0),
+		UnhealthyThreshold: c.getPortLabelInt(portLabel, PortLabelHealthCheckUnhealthyThreshold, 0),
+	}
+}
+
+// getPortTransport method returns the timeouts and connection settings from
+// the port sub labels tsdproxy.port.<index>.transport.<option>.
+func (c *container) getPortTransport(portLabel string) model.Transport {
+	return model.Transport{
+		DialTimeout:           c.getPortLabelDuration(portLabel, PortLabelTransportDialTimeout, 0),
+		KeepAlive:             c.getPortLabelDuration(portLabel, PortLabelTransportKeepAlive, 0),
+		ResponseHeaderTimeout: c.getPortLabelDuration(portLabel, PortLabelTransportResponseHeaderTimeout, 0),
+		IdleConnTimeout:       c.getPortLabelDuration(portLabel, PortLabelTransportIdleConnTimeout, 0),
+		ReadHeaderTimeout:     c.getPortLabelDuration(portLabel, PortLabelTransportReadHeaderTimeout, 0),
+		ReadTimeout:           c.getPortLabelDuration(portLabel, PortLabelTransportReadTimeout, 0),
+		WriteTimeout:          c.getPortLabelDuration(portLabel, PortLabelTransportWriteTimeout, 0),
+		MaxIdleConns:          c.getPortLabelInt(portLabel, PortLabelTransportMaxIdleConns, 0),
+		MaxIdleConnsPerHost:   c.getPortLabelInt(portLabel, PortLabelTransportMaxIdleConnsPerHost, 0),
+	}
+}
+
+// getPortRoutes method returns the path routes from the port sub labels
+// tsdproxy.port.<index>.route.<name>, sorted by path.
+func (c *container) getPortRoutes(portLabel string) []model.Route {
+	prefix := portLabel + "." + PortLabelRoute
+
+	var routes []model.Route
+	for k, v := range c.labels {
+		name := strings.TrimPrefix(k, prefix)
+		// skip labels that aren't routes and route sub labels
+		if !strings.HasPrefix(k, prefix) || name == "" || strings.Contains(name, ".") {
+			continue
+		}
+
+		route, err := model.NewRouteLabel(v)
+		if err != nil {
+			c.log.Error().Err(err).Str("route", k).Msg("error creating route config")
+			continue
+		}
+
+		route.StripPrefix = c.getLabelBool(k+"."+PortLabelRouteStripPrefix, false)
+		route.Rewrite = c.getLabelString(k+"."+PortLabelRouteRewrite, "")
+
+		routes = append(routes, route)
+	}
+
+	slices.SortFunc(routes, func(a, b model.Route) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return routes
+}
+
+// getHeaderRules method returns the header rules from the labels with the prefix,
+// <prefix>set.<header>, <prefix>add.<header> and <prefix>remove.
+func (c *container) getHeaderRules(prefix string) model.HeaderRules {
+	rules := model.HeaderRules{
+		Set:    make(map[string]string),
+		Add:    make(map[string]string),
+		Remove: c.getLabelList(prefix + LabelHeaderRemove),
+	}
+
+	for k, v := range c.labels {
+		if name, ok := strings.CutPrefix(k, prefix+LabelHeaderSet); ok {
+			rules.Set[name] = v
+		}
+		if name, ok := strings.CutPrefix(k, prefix+LabelHeaderAdd); ok {
+			rules.Add[name] = v
+		}
+	}
+
+	return rules
+}
+
+// getAccessControl method returns the access control configuration from the labels
+// with the prefix, tsdproxy. for the proxy or tsdproxy.port.<index>. for a port.
+func (c *container) getAccessControl(prefix string) model.AccessControl {
+	return model.AccessControl{
+		Allow:      c.getAccessRules(prefix + LabelAccessAllow),
+		Deny:       c.getAccessRules(prefix + LabelAccessDeny),
+		DeniedPage: c.getLabelString(prefix+LabelAccessDeniedPage, ""),
+	}
+}
+
+// getRateLimit method returns the rate limit from the labels with the prefix,
+// <prefix>ratelimit as <requests>/<period> and <prefix>ratelimit.burst.
+func (c *container) getRateLimit(prefix string) model.RateLimit {
+	limit := c.getLabelRateLimit(prefix + LabelRateLimit)
+	if limit.IsEnabled() {
+		limit.Burst = c.getLabelInt(prefix+LabelRateLimitBurst, 0)
+	}
+
+	return limit
+}
+
+// getLabelRateLimit method returns a rate limit <requests>/<period> from a container label.
+func (c *container) getLabelRateLimit(label string) model.RateLimit {
+	value := c.getLabelString(label, "")
+	if value == "" {
+		return model.RateLimit{}
+	}
+
+	limit, err := model.ParseRateLimit(value)
+	if err != nil {
+		c.log.Warn().Err(err).Str("label", label).Msg("invalid rate limit in label")
+		return model.RateLimit{}
+	}
+
+	return limit
+}
+
+// getPortFunnelProtection method returns the Funnel protections from the port
+// sub labels tsdproxy.port.<index>.funnel.<option>.
+func (c *container) getPortFunnelProtection(portLabel string) model.FunnelProtection {
+	protection := model.FunnelProtection{
+		BasicAuth:    c.getLabelList(portLabel + "." + PortLabelFunnelBasicAuth),
+		BearerTokens: c.getLabelList(portLabel + "." + PortLabelFunnelBearerTokens),
+		Allow:        c.getLabelList(portLabel + "." + PortLabelFunnelAllow),
+		Deny:         c.getLabelList(portLabel + "." + PortLabelFunnelDeny),
+		RateLimit:    c.getRateLimit(portLabel + "." + PortLabelFunnel),
+		FailureLimit: c.getLabelRateLimit(portLabel + "." + PortLabelFunnelFailureLimit),
+		ShareLinkTTL: c.getPortLabelDuration(portLabel, PortLabelFunnelShareLinkTTL, 0),
+		SessionTTL:   c.getPortLabelDuration(portLabel, PortLabelFunnelSessionTTL, 0),
+		ShareLinks:   c.getLabelBool(portLabel+"."+PortLabelFunnelShareLinks, false),
+	}
+
+	// the Funnel gate denies all the internet clients with invalid CIDRs
+	if err := protection.Validate(); err != nil {
+		c.log.Error().Err(err).Str("label", portLabel).Msg("invalid funnel protection in labels")
+	}
+
+	return protection
+}
+
+// getPortCache method returns the response cache from the port sub labels
+// tsdproxy.port.<index>.cache.<option>.
+func (c *container) getPortCache(portLabel string) model.Cache {
+	return model.Cache{
+		Enabled:      c.getLabelBool(portLabel+"."+PortLabelCache, false),
+		Dir:          c.getPortLabelString(portLabel, PortLabelCacheDir, ""),
+		MaxSize:      c.getPortLabelInt(portLabel, PortLabelCacheMaxSize, 0),
+		MaxEntrySize: c.getPortLabelInt(portLabel, PortLabelCacheMaxEntrySize, 0),
+	}
+}
+
+// getAccessRules method returns the access rules from the labels with the prefix.
+func (c *container) getAccessRules(prefix string) model.AccessRules {
+	return model.AccessRules{
+		Users:   c.getLabelList(prefix + LabelAccessRuleUsers),
+		UserIDs: c.getLabelList(prefix + LabelAccessRuleUserIDs),
+		Tags:    c.getLabelList(prefix + LabelAccessRuleTags),
+		Groups:  c.getLabelList(prefix + LabelAccessRuleGroups),
+	}
+}
+
 // getTailscaleConfig method returns the tailscale configuration.
 func (c *container) getTailscaleConfig() (*model.Tailscale, error) {
 	c.log.Trace().Msg("getTailscaleConfig")
@@ -326,19 +533,27 @@ func (c *container) getProxyHostname() (string, error) {
 	return c.getName(), nil
 }
 
-func withTargetProviderName(name string) ContainerOption {
+func WithTargetProviderName(name string) ContainerOption {
 	return func(c *container) {
 		c.targetProviderName = name
 	}
 }
 
-func withDefaultBridgeAddress(address string) ContainerOption {
+func withName(name string) ContainerOption {
+	return func(c *container) {
+		if name != "" {
+			c.name = name
+		}
+	}
+}
+
+func WithDefaultBridgeAddress(address string) ContainerOption {
 	return func(c *container) {
 		c.defaultBridgeAddress = address
 	}
 }
 
-func withDefaultTargetHostname(hostname string) ContainerOption {
+func WithDefaultTargetHostname(hostname string) ContainerOption {
 	return func(c *container) {
 		c.defaultTargetHostname = hostname
 	}
@@ -31,6 +31,8 @@ type (
 		docker                   *client.Client
 		log                      zerolog.Logger
 		containers               map[string]*container
+		replicaGroups            map[string]replicaGroup
+		replicaPorts             map[string]model.PortConfigList
 		name                     string
 		host                     string
 		defaultTargetHostname    string
@@ -67,6 +69,8 @@ func New(log zerolog.Logger, name string, provider *config.DockerTargetProviderC
 		defaultProxyProvider:     provider.DefaultProxyProvider,
 		tryDockerInternalNetwork: provider.TryDockerInternalNetwork,
 		containers:               make(map[string]*container),
+		replicaGroups:            make(map[string]replicaGroup),
+		replicaPorts:             make(map[string]model.PortConfigList),
 	}
 
 	c.setDefaultBridgeAddress()
@@ -92,18 +96,32 @@ func (c *Client) AddTarget(id string) (*model.Config, error) {
 
 	ctx := context.Background()
 
-	dcontainer, err := c.docker.ContainerInspect(ctx, id)
+	if isReplicaTargetID(id) {
+		return c.addReplicaTarget(ctx, id)
+	}
+
+	dcontainer, dservice, err := c.inspect(ctx, id)
 	if err != nil {
-		return nil, fmt.Errorf("error inspecting container: %w", err)
+		return nil, err
 	}
 
+	return c.newProxyConfig(dcontainer, dservice)
+}
+
+// inspect method returns the container and its swarm service if exists.
+func (c *Client) inspect(ctx context.Context, id string) (ctypes.InspectResponse, swarm.Service, error) {
 	var dservice swarm.Service
 
-	if serviceID, ok := dcontainer.Config.Labels["com.docker.swarm.service.id"]; ok {
+	dcontainer, err := c.docker.ContainerInspect(ctx, id)
+	if err != nil {
+		return dcontainer, dservice, fmt.Errorf("error inspecting container: %w", err)
+	}
+
+	if serviceID, ok := dcontainer.Config.Labels[labelSwarmServiceID]; ok {
 		dservice, _, _ = c.docker.ServiceInspectWithRaw(ctx, serviceID, swarm.ServiceInspectOptions{})
 	}
 
-	return c.newProxyConfig(dcontainer, dservice)
+	return dcontainer, dservice, nil
 }
 
 // DeleteProxy method implements TargetProvider DeleteProxy method
@@ -150,10 +168,17 @@ func (c *Client) WatchEvents(ctx context.Context, eventsChan chan targetprovider
 	})
 
 	go func() {
+		// replica events are delayed until the replicas settle,
+		// so scaling a service only updates the proxy once
+		pendingReplicas := make(map[string]replicaGroup)
+		replicaTimer := time.NewTimer(replicaSettleDelay)
+		replicaTimer.Stop()
+
 		defer func() {
 			if r := recover(); r != nil {
 				c.log.Error().Interface("panic", r).Msg("docker event watcher panicked")
 			}
+			replicaTimer.Stop()
 			close(eventsChan)
 			close(errChan)
 		}()
@@ -163,11 +188,32 @@ func (c *Client) WatchEvents(ctx context.Context, eventsChan chan targetprovider
 			case <-ctx.Done():
 				return
 
+			case <-replicaTimer.C:
+				for id, group := range pendingReplicas {
+					delete(pendingReplicas, id)
+
+					events, err := c.getReplicaEvents(ctx, group)
+					if err != nil {
+						errChan <- err
+						continue
+					}
+					for _, event := range events {
+						eventsChan <- event
+					}
+				}
+
 			case devent, ok := <-dockereventsChan:
 				if !ok {
 					return
 				}
 
+				if group, ok := getReplicaGroup(devent.Actor.Attributes); ok {
+					c.setReplicaGroup(group)
+					pendingReplicas[group.id] = group
+					replicaTimer.Reset(replicaSettleDelay)
+					continue
+				}
+
 				switch devent.Action {
 				case devents.ActionStart:
 					eventsChan <- c.getStartEvent(devent.Actor.ID)
@@ -208,9 +254,23 @@ func (c *Client) startAllProxies(ctx context.Context, eventsChan chan targetprov
 		return
 	}
 
+	startedReplicas := make(map[string]bool)
+
 	for _, container := range containers {
+		event := c.getStartEvent(container.ID)
+
+		if group, ok := getReplicaGroup(container.Labels); ok {
+			if startedReplicas[group.id] {
+				continue
+			}
+			startedReplicas[group.id] = true
+
+			c.setReplicaGroup(group)
+			event.ID = group.id
+		}
+
 		select {
-		case eventsChan <- c.getStartEvent(container.ID):
+		case eventsChan <- event:
 		case <-ctx.Done():
 			return
 		}
@@ -222,11 +282,7 @@ func (c *Client) newProxyConfig(dcontainer ctypes.InspectResponse, dservice swar
 	c.log.Trace().Msg("newProxyConfig")
 	defer c.log.Trace().Msg("End newProxyConfig")
 
-	ctn := newContainer(c.log, dcontainer, dservice, c.tryDockerInternalNetwork,
-		withDefaultBridgeAddress(c.defaultBridgeAdress),
-		withDefaultTargetHostname(c.defaultTargetHostname),
-		withTargetProviderName(c.name),
-	)
+	ctn := c.newContainer(dcontainer, dservice)
 
 	pcfg, err := ctn.newProxyConfig()
 	if err != nil {
@@ -236,6 +292,17 @@ func (c *Client) newProxyConfig(dcontainer ctypes.InspectResponse, dservice swar
 	return pcfg, nil
 }
 
+// newContainer method returns a new container with the provider defaults.
+func (c *Client) newContainer(dcontainer ctypes.InspectResponse, dservice swarm.Service, opts ...ContainerOption) *container {
+	opts = append([]ContainerOption{
+		WithDefaultBridgeAddress(c.defaultBridgeAdress),
+		WithDefaultTargetHostname(c.defaultTargetHostname),
+		WithTargetProviderName(c.name),
+	}, opts...)
+
+	return newContainer(c.log, dcontainer, dservice, c.tryDockerInternalNetwork, opts...)
+}
+
 // getStartEvent method returns a targetproviders.TargetEvent for a container start
 func (c *Client) getStartEvent(id string) targetproviders.TargetEvent {
 	c.log.Trace().Msgf("getStartEvent %s", id)
@@ -284,6 +351,7 @@ func (c *Client) deleteContainer(name string) {
 	defer c.mutex.Unlock()
 
 	delete(c.containers, name)
+	delete(c.replicaPorts, name)
 }
 
 // setDefaultBridgeAddress method returns the default bridge network address
@@ -347,6 +415,9 @@ func (c *Client) reconcileContainers(ctx context.Context) {
 	actualMap := make(map[string]bool)
 	for _, container := range actualContainers {
 		actualMap[container.ID] = true
+		if group, ok := getReplicaGroup(container.Labels); ok {
+			actualMap[group.id] = true
+		}
 	}
 
 	// Remove containers that no longer exist
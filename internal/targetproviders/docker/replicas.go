@@ -0,0 +1,221 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package docker
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	ctypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+)
+
+// replicaGroup struct identifies the containers that are replicas of the same
+// compose or swarm service and are proxied as a single target.
+type replicaGroup struct {
+	filters filters.Args
+	id      string
+	name    string
+}
+
+// getReplicaGroup function returns the replica group of a container from its labels.
+// Only containers with tsdproxy.replicas set to true are grouped.
+func getReplicaGroup(labels map[string]string) (replicaGroup, bool) {
+	if enabled, err := strconv.ParseBool(labels[LabelReplicas]); err != nil || !enabled {
+		return replicaGroup{}, false
+	}
+
+	args := filters.NewArgs()
+	args.Add("label", LabelIsEnabled)
+
+	if serviceID, ok := labels[labelSwarmServiceID]; ok {
+		args.Add("label", labelSwarmServiceID+"="+serviceID)
+		return replicaGroup{
+			id:      replicaTargetIDPrefix + serviceID,
+			name:    labels[labelSwarmServiceName],
+			filters: args,
+		}, true
+	}
+
+	project, hasProject := labels[labelComposeProject]
+	service, hasService := labels[labelComposeService]
+	if !hasProject || !hasService {
+		return replicaGroup{}, false
+	}
+
+	args.Add("label", labelComposeProject+"="+project)
+	args.Add("label", labelComposeService+"="+service)
+
+	return replicaGroup{
+		id:      replicaTargetIDPrefix + project + replicaGroupSeparator + service,
+		name:    service,
+		filters: args,
+	}, true
+}
+
+// isReplicaTargetID function returns true if the target id is a replica group.
+func isReplicaTargetID(id string) bool {
+	return strings.HasPrefix(id, replicaTargetIDPrefix)
+}
+
+// setReplicaGroup method stores the replica group to be found by its target id.
+func (c *Client) setReplicaGroup(group replicaGroup) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.replicaGroups[group.id] = group
+}
+
+// getReplicaEvents method returns the events for a replica group after
+// one of its containers started or stopped. Running proxies get port events
+// to update the targets without restarting the proxy.
+func (c *Client) getReplicaEvents(ctx context.Context, group replicaGroup) ([]targetproviders.TargetEvent, error) {
+	c.log.Trace().Msgf("getReplicaEvents %s", group.id)
+	defer c.log.Trace().Msgf("End getReplicaEvents %s", group.id)
+
+	replicas, err := c.listReplicas(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	_, active := c.containers[group.id]
+	oldPorts, hasPorts := c.replicaPorts[group.id]
+	c.mutex.Unlock()
+
+	event := targetproviders.TargetEvent{
+		TargetProvider: c,
+		ID:             group.id,
+	}
+
+	switch {
+	case len(replicas) == 0:
+		c.log.Info().Str("replicas", group.id).Msg("All replicas stopped")
+		event.Action = targetproviders.ActionStopProxy
+	case active:
+		c.log.Info().Str("replicas", group.id).Int("count", len(replicas)).Msg("Replicas changed")
+		event.Action = targetproviders.ActionRestartProxy
+
+		if hasPorts {
+			if pcfg, _, err := c.getReplicaProxyConfig(ctx, group, replicas); err == nil {
+				return targetproviders.PortEvents(c, group.id, oldPorts, pcfg.Ports), nil
+			}
+		}
+	default:
+		c.log.Info().Str("replicas", group.id).Int("count", len(replicas)).Msg("Replicas started")
+		event.Action = targetproviders.ActionStartProxy
+	}
+
+	return []targetproviders.TargetEvent{event}, nil
+}
+
+// listReplicas method returns the running containers of a replica group sorted by name.
+func (c *Client) listReplicas(ctx context.Context, group replicaGroup) ([]ctypes.Summary, error) {
+	replicas, err := c.docker.ContainerList(ctx, ctypes.ListOptions{
+		Filters: group.filters,
+		All:     false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error listing replicas: %w", err)
+	}
+
+	slices.SortFunc(replicas, func(a, b ctypes.Summary) int {
+		return strings.Compare(strings.Join(a.Names, ","), strings.Join(b.Names, ","))
+	})
+
+	return replicas, nil
+}
+
+// addReplicaTarget method returns the proxy config of a replica group.
+// The first replica defines the proxy configuration, and the targets
+// of all replicas are added to each port.
+func (c *Client) addReplicaTarget(ctx context.Context, id string) (*model.Config, error) {
+	c.mutex.Lock()
+	group, ok := c.replicaGroups[id]
+	c.mutex.Unlock()
+
+	if !ok {
+		return nil, fmt.Errorf("replica group %s not found", id)
+	}
+
+	replicas, err := c.listReplicas(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	pcfg, primary, err := c.getReplicaProxyConfig(ctx, group, replicas)
+	if err != nil {
+		return nil, err
+	}
+
+	c.addContainer(primary, id)
+
+	c.mutex.Lock()
+	c.replicaPorts[id] = pcfg.Ports
+	c.mutex.Unlock()
+
+	return pcfg, nil
+}
+
+// getReplicaProxyConfig method returns the proxy config of the replicas of a
+// group, and the container of the first replica.
+func (c *Client) getReplicaProxyConfig(ctx context.Context, group replicaGroup,
+	replicas []ctypes.Summary,
+) (*model.Config, *container, error) {
+	//
+	var (
+		pcfg    *model.Config
+		primary *container
+	)
+
+	for _, replica := range replicas {
+		dcontainer, dservice, err := c.inspect(ctx, replica.ID)
+		if err != nil {
+			c.log.Warn().Err(err).Str("container", replica.ID).Msg("Error inspecting replica")
+			continue
+		}
+
+		ctn := c.newContainer(dcontainer, dservice, withName(group.name))
+
+		if pcfg == nil {
+			if pcfg, err = ctn.newProxyConfig(); err != nil {
+				return nil, nil, fmt.Errorf("error getting proxy config: %w", err)
+			}
+			primary = ctn
+			continue
+		}
+
+		mergeReplicaPorts(pcfg, ctn.getPortsWithLegacy())
+	}
+
+	if pcfg == nil {
+		return nil, nil, fmt.Errorf("no running replicas found for %s", group.id)
+	}
+
+	pcfg.TargetID = group.id
+
+	return pcfg, primary, nil
+}
+
+// mergeReplicaPorts function adds the targets of a replica to the proxy ports.
+func mergeReplicaPorts(pcfg *model.Config, ports model.PortConfigList) {
+	for name, replicaPort := range ports {
+		port, ok := pcfg.Ports[name]
+		if !ok || port.IsRedirect {
+			continue
+		}
+
+		for _, target := range replicaPort.GetTargets() {
+			port.AddTarget(target)
+		}
+
+		pcfg.Ports[name] = port
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // getLabelBool method returns a bool from a container label.
@@ -37,6 +38,55 @@ func (c *container) getLabelString(label string, defaultValue string) string {
 	return value
 }
 
+// getLabelList method returns a list from a comma separated container label.
+func (c *container) getLabelList(label string) []string {
+	valueString, ok := c.labels[label]
+	if !ok {
+		return nil
+	}
+
+	var values []string
+	for _, v := range strings.Split(valueString, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+
+	return values
+}
+
+// getPortLabelString method returns a string from a port sub label (tsdproxy.port.<index>.<option>).
+func (c *container) getPortLabelString(portLabel string, option string, defaultValue string) string {
+	return c.getLabelString(portLabel+"."+option, defaultValue)
+}
+
+// getLabelInt method returns an int from a container label.
+func (c *container) getLabelInt(label string, defaultValue int) int {
+	if valueString, ok := c.labels[label]; ok {
+		if value, err := strconv.Atoi(valueString); err == nil {
+			return value
+		}
+		c.log.Warn().Str("label", label).Msg("invalid number in label")
+	}
+	return defaultValue
+}
+
+// getPortLabelInt method returns an int from a port sub label.
+func (c *container) getPortLabelInt(portLabel string, option string, defaultValue int) int {
+	return c.getLabelInt(portLabel+"."+option, defaultValue)
+}
+
+// getPortLabelDuration method returns a duration from a port sub label.
+func (c *container) getPortLabelDuration(portLabel string, option string, defaultValue time.Duration) time.Duration {
+	if valueString, ok := c.labels[portLabel+"."+option]; ok {
+		if value, err := time.ParseDuration(valueString); err == nil {
+			return value
+		}
+		c.log.Warn().Str("label", portLabel+"."+option).Msg("invalid duration in label")
+	}
+	return defaultValue
+}
+
 // getAuthKeyFromAuthFile method returns a auth key from a file.
 func (c *container) getAuthKeyFromAuthFile(authKey string) (string, error) {
 	authKeyFile, ok := c.labels[LabelAuthKeyFile]
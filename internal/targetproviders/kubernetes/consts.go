@@ -0,0 +1,48 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package kubernetes
+
+import (
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+)
+
+const (
+	// Annotations use the same scheme as the docker container labels.
+	AnnotationEnable             = docker.LabelEnable
+	AnnotationName               = docker.LabelName
+	AnnotationContainerAccessLog = docker.LabelContainerAccessLog
+	AnnotationProxyProvider      = docker.LabelProxyProvider
+	AnnotationPort               = docker.LabelPort
+	// Tailscale
+	AnnotationEphemeral    = docker.LabelEphemeral
+	AnnotationRunWebClient = docker.LabelRunWebClient
+	AnnotationTsnetVerbose = docker.LabelTsnetVerbose
+	AnnotationAuthKey      = docker.LabelAuthKey
+	AnnotationTags         = docker.LabelTags
+	// Access control
+	AnnotationAccessAllow      = docker.LabelAccessAllow
+	AnnotationAccessDeny       = docker.LabelAccessDeny
+	AnnotationAccessDeniedPage = docker.LabelAccessDeniedPage
+	// Access log
+	AnnotationAccessLogFormat = docker.LabelAccessLogFormat
+	AnnotationAccessLogOutput = docker.LabelAccessLogOutput
+	AnnotationAccessLogFile   = docker.LabelAccessLogFile
+	// Dashboard
+	AnnotationDashboardVisible = docker.LabelDashboardVisible
+	AnnotationDashboardLabel   = docker.LabelDashboardLabel
+	AnnotationDashboardIcon    = docker.LabelDashboardIcon
+
+	// Kinds of the watched resources, used as prefix of the target id
+	KindService = "service"
+	KindPod     = "pod"
+
+	// targetIDSeparator separates kind, namespace and name in the target id
+	targetIDSeparator = "/"
+
+	// resyncPeriod is the interval the informers replay all resources to
+	// fix missed events
+	resyncPeriod = 10 * time.Minute
+)
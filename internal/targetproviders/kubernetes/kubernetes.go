@@ -0,0 +1,343 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+	"sync"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	k8s "k8s.io/client-go/kubernetes"
+	listersv1 "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type (
+	// Client struct implements TargetProvider for Kubernetes Services and Pods
+	// with tsdproxy.* annotations.
+	Client struct {
+		log                  zerolog.Logger
+		clientset            k8s.Interface
+		services             listersv1.ServiceLister
+		pods                 listersv1.PodLister
+		eventsChan           chan targetproviders.TargetEvent
+		ctx                  context.Context
+		cancel               context.CancelFunc
+		targets              map[string]*resource
+		name                 string
+		namespace            string
+		clusterDomain        string
+		defaultProxyProvider string
+		mtx                  sync.Mutex
+	}
+)
+
+var _ targetproviders.TargetProvider = (*Client)(nil)
+
+// New function returns a new Kubernetes TargetProvider. It uses the kubeconfig
+// file if defined, or the in-cluster configuration otherwise.
+func New(log zerolog.Logger, name string, provider *config.KubernetesTargetProviderConfig) (*Client, error) {
+	var (
+		restConfig *rest.Config
+		err        error
+	)
+
+	if provider.Kubeconfig != "" {
+		restConfig, err = clientcmd.BuildConfigFromFlags("", provider.Kubeconfig)
+	} else {
+		restConfig, err = rest.InClusterConfig()
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubernetes config: %w", err)
+	}
+
+	clientset, err := k8s.NewForConfig(restConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating kubernetes client: %w", err)
+	}
+
+	return NewWithClientset(log, name, provider, clientset), nil
+}
+
+// NewWithClientset function returns a new Kubernetes TargetProvider using the clientset,
+// like the client-go fake clientset.
+func NewWithClientset(log zerolog.Logger, name string, provider *config.KubernetesTargetProviderConfig,
+	clientset k8s.Interface,
+) *Client {
+	//
+	ctx, cancel := context.WithCancel(context.Background())
+
+	return &Client{
+		log:                  log.With().Str("kubernetes", name).Logger(),
+		clientset:            clientset,
+		ctx:                  ctx,
+		cancel:               cancel,
+		targets:              make(map[string]*resource),
+		name:                 name,
+		namespace:            provider.Namespace,
+		clusterDomain:        provider.ClusterDomain,
+		defaultProxyProvider: provider.DefaultProxyProvider,
+	}
+}
+
+// WatchEvents method implements TargetProvider WatchEvents method.
+// Annotated Services and Pods found at start and added later start proxies,
+// changes restart them and deletions stop them.
+func (c *Client) WatchEvents(ctx context.Context, eventsChan chan targetproviders.TargetEvent, errChan chan error) {
+	c.log.Debug().Msg("Start WatchEvents")
+
+	c.eventsChan = eventsChan
+
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		// stop watching if the provider is closed
+		select {
+		case <-c.ctx.Done():
+		case <-ctx.Done():
+		}
+		cancel()
+	}()
+
+	factory := informers.NewSharedInformerFactoryWithOptions(c.clientset, resyncPeriod,
+		informers.WithNamespace(c.namespace))
+
+	serviceInformer := factory.Core().V1().Services()
+	podInformer := factory.Core().V1().Pods()
+
+	c.services = serviceInformer.Lister()
+	c.pods = podInformer.Lister()
+
+	handlers := []struct {
+		informer cache.SharedIndexInformer
+		kind     string
+	}{
+		{serviceInformer.Informer(), KindService},
+		{podInformer.Informer(), KindPod},
+	}
+
+	for _, h := range handlers {
+		_, err := h.informer.AddEventHandler(c.eventHandler(h.kind))
+		if err != nil {
+			errChan <- fmt.Errorf("error adding %s event handler: %w", h.kind, err)
+			return
+		}
+	}
+
+	factory.Start(ctx.Done())
+
+	go func() {
+		for kind, synced := range factory.WaitForCacheSync(ctx.Done()) {
+			if !synced {
+				errChan <- fmt.Errorf("error syncing kubernetes cache of %s", kind)
+			}
+		}
+	}()
+}
+
+// GetDefaultProxyProviderName method implements TargetProvider GetDefaultProxyProviderName method
+func (c *Client) GetDefaultProxyProviderName() string {
+	return c.defaultProxyProvider
+}
+
+// Close method implements TargetProvider Close method
+func (c *Client) Close() {
+	c.cancel()
+}
+
+// AddTarget method implements TargetProvider AddTarget method
+func (c *Client) AddTarget(id string) (*model.Config, error) {
+	kind, namespace, name, err := parseTargetID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	var r *resource
+
+	switch kind {
+	case KindService:
+		svc, err := c.services.Services(namespace).Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting service %s: %w", id, err)
+		}
+		r = newServiceResource(c.log, svc, c.clusterDomain)
+	case KindPod:
+		pod, err := c.pods.Pods(namespace).Get(name)
+		if err != nil {
+			return nil, fmt.Errorf("error getting pod %s: %w", id, err)
+		}
+		r = newPodResource(c.log, pod)
+	default:
+		return nil, fmt.Errorf("invalid target id %s", id)
+	}
+
+	r.targetProviderName = c.name
+
+	pcfg, err := r.newProxyConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	c.addTarget(r)
+
+	return pcfg, nil
+}
+
+// DeleteProxy method implements TargetProvider DeleteProxy method
+func (c *Client) DeleteProxy(id string) error {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	if _, ok := c.targets[id]; !ok {
+		return fmt.Errorf("target %s not found", id)
+	}
+
+	delete(c.targets, id)
+
+	return nil
+}
+
+// RemoveTarget method implements TargetProvider RemoveTarget method
+func (c *Client) RemoveTarget(id string) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	delete(c.targets, id)
+}
+
+// eventHandler method returns the informer handler of a kind of resource.
+func (c *Client) eventHandler(kind string) cache.ResourceEventHandlerFuncs {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj any) {
+			if r := c.toResource(obj); r != nil && c.isReady(obj) {
+				c.sendEvent(r.id(), targetproviders.ActionStartProxy)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj any) {
+			oldR, newR := c.toResource(oldObj), c.toResource(newObj)
+			wasReady := oldR != nil && c.isReady(oldObj)
+			isReady := newR != nil && c.isReady(newObj)
+
+			switch {
+			case !wasReady && isReady:
+				c.sendEvent(newR.id(), targetproviders.ActionStartProxy)
+			case wasReady && !isReady:
+				c.sendEvent(oldR.id(), targetproviders.ActionStopProxy)
+			case wasReady && isReady && changed(oldR, newR):
+				c.sendChangeEvents(oldR, newR)
+			}
+		},
+		DeleteFunc: func(obj any) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if r := c.toResource(obj); r != nil {
+				c.sendEvent(r.id(), targetproviders.ActionStopProxy)
+			}
+		},
+	}
+}
+
+// toResource method returns the resource of an annotated Service or Pod, or nil
+// if the object is not enabled.
+func (c *Client) toResource(obj any) *resource {
+	switch o := obj.(type) {
+	case *corev1.Service:
+		if isEnabled(o.Annotations) {
+			return newServiceResource(c.log, o, c.clusterDomain)
+		}
+	case *corev1.Pod:
+		if isEnabled(o.Annotations) {
+			return newPodResource(c.log, o)
+		}
+	}
+
+	return nil
+}
+
+// isReady method returns true if the resource can receive requests.
+// Services are always ready, Pods are ready when running with an IP.
+func (c *Client) isReady(obj any) bool {
+	if pod, ok := obj.(*corev1.Pod); ok {
+		return pod.Status.Phase == corev1.PodRunning && pod.Status.PodIP != "" && pod.DeletionTimestamp == nil
+	}
+
+	return true
+}
+
+// changed function returns true if the changes of a resource require a proxy update.
+// Only tsdproxy annotations are compared, other annotations change often, like
+// on every kubectl apply.
+func changed(oldR, newR *resource) bool {
+	return oldR.host != newR.host ||
+		!maps.Equal(tsdproxyAnnotations(oldR.annotations, true), tsdproxyAnnotations(newR.annotations, true))
+}
+
+// tsdproxyAnnotations function returns the tsdproxy annotations, with or
+// without the port annotations.
+func tsdproxyAnnotations(annotations map[string]string, withPorts bool) map[string]string {
+	filtered := maps.Clone(annotations)
+	maps.DeleteFunc(filtered, func(k, _ string) bool {
+		return !strings.HasPrefix(k, docker.LabelPrefix) || (!withPorts && strings.HasPrefix(k, AnnotationPort))
+	})
+	return filtered
+}
+
+// sendChangeEvents method sends the events to apply the changes of a resource.
+// Running proxies with only port changes get port events, so the other ports
+// and the Tailscale node keep running.
+func (c *Client) sendChangeEvents(oldR, newR *resource) {
+	c.mtx.Lock()
+	_, running := c.targets[newR.id()]
+	c.mtx.Unlock()
+
+	if !running ||
+		!maps.Equal(tsdproxyAnnotations(oldR.annotations, false), tsdproxyAnnotations(newR.annotations, false)) {
+		c.sendEvent(newR.id(), targetproviders.ActionRestartProxy)
+		return
+	}
+
+	for _, event := range targetproviders.PortEvents(c, newR.id(), oldR.getPorts(), newR.getPorts()) {
+		c.sendTargetEvent(event)
+	}
+}
+
+// sendEvent method sends a target event unless the provider is closed.
+func (c *Client) sendEvent(id string, action targetproviders.ActionType) {
+	c.sendTargetEvent(targetproviders.TargetEvent{
+		TargetProvider: c,
+		ID:             id,
+		Action:         action,
+	})
+}
+
+// sendTargetEvent method sends an event unless the provider is closed.
+func (c *Client) sendTargetEvent(event targetproviders.TargetEvent) {
+	c.log.Info().Str("target", event.ID).Str("port", event.Port).Int("action", int(event.Action)).
+		Msg("kubernetes event")
+
+	select {
+	case c.eventsChan <- event:
+	case <-c.ctx.Done():
+	}
+}
+
+// addTarget method adds a target to the targets map
+func (c *Client) addTarget(r *resource) {
+	c.mtx.Lock()
+	defer c.mtx.Unlock()
+
+	c.targets[r.id()] = r
+}
@@ -0,0 +1,243 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package kubernetes
+
+import (
+	"context"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+)
+
+const (
+	testNamespace = "default"
+	eventTimeout  = 5 * time.Second
+	// noEventWait is the time to wait to check that no event is sent
+	noEventWait = 200 * time.Millisecond
+)
+
+// providerTest struct is a provider watching a fake clientset.
+type providerTest struct {
+	client    *Client
+	clientset *fake.Clientset
+	events    chan targetproviders.TargetEvent
+}
+
+// newProviderTest function starts a provider with the objects, and waits until
+// the informers watch the clientset, so no change is missed.
+func newProviderTest(t *testing.T, objects ...runtime.Object) *providerTest {
+	t.Helper()
+
+	clientset := fake.NewSimpleClientset(objects...)
+
+	watching := make(chan string, 10) //nolint:mnd
+	clientset.PrependWatchReactor("*", func(action k8stesting.Action) (bool, watch.Interface, error) {
+		select {
+		case watching <- action.GetResource().Resource:
+		default:
+		}
+		return false, nil, nil
+	})
+
+	cfg := &config.KubernetesTargetProviderConfig{Namespace: testNamespace, ClusterDomain: "cluster.local"}
+	pt := &providerTest{
+		client:    NewWithClientset(zerolog.Nop(), "k8s", cfg, clientset),
+		clientset: clientset,
+		events:    make(chan targetproviders.TargetEvent, 10), //nolint:mnd
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(func() {
+		cancel()
+		pt.client.Close()
+	})
+
+	errs := make(chan error, 2) //nolint:mnd
+	pt.client.WatchEvents(ctx, pt.events, errs)
+
+	watched := make(map[string]bool)
+	for len(watched) < 2 {
+		select {
+		case resource := <-watching:
+			watched[resource] = true
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(eventTimeout):
+			t.Fatal("informers not watching")
+		}
+	}
+
+	return pt
+}
+
+// expectEvent method waits for the next event and checks it.
+func (pt *providerTest) expectEvent(t *testing.T, id, port string, action targetproviders.ActionType) {
+	t.Helper()
+
+	select {
+	case e := <-pt.events:
+		if e.ID != id || e.Port != port || e.Action != action {
+			t.Fatalf("event: got %s %q %d, want %s %q %d", e.ID, e.Port, e.Action, id, port, action)
+		}
+	case <-time.After(eventTimeout):
+		t.Fatalf("no event, want %s %q %d", id, port, action)
+	}
+}
+
+// expectNoEvent method checks that no event is sent.
+func (pt *providerTest) expectNoEvent(t *testing.T) {
+	t.Helper()
+
+	select {
+	case e := <-pt.events:
+		t.Fatalf("unexpected event %s %q %d", e.ID, e.Port, e.Action)
+	case <-time.After(noEventWait):
+	}
+}
+
+func newService(name string, annotations map[string]string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Annotations: annotations},
+		Spec:       corev1.ServiceSpec{ClusterIP: "10.0.0.10"},
+	}
+}
+
+func newPod(name string, annotations map[string]string, phase corev1.PodPhase, ip string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace, Annotations: annotations},
+		Status:     corev1.PodStatus{Phase: phase, PodIP: ip},
+	}
+}
+
+// firstTarget function returns the first target of a port.
+func firstTarget(ports model.PortConfigList, name string) *url.URL {
+	port := ports[name]
+
+	return port.GetFirstTarget()
+}
+
+func TestServiceEvents(t *testing.T) {
+	ctx := context.Background()
+	annotations := map[string]string{
+		AnnotationEnable:          "true",
+		AnnotationPort + "1":      "443/https:80/http",
+		AnnotationPort + "2":      "8443/https:8080/http",
+		"kubectl.kubernetes.io/x": "1",
+	}
+	pt := newProviderTest(t, newService("existing", annotations), newService("disabled", nil))
+	services := pt.clientset.CoreV1().Services(testNamespace)
+
+	// services found at start
+	pt.expectEvent(t, "service/default/existing", "", targetproviders.ActionStartProxy)
+	pt.expectNoEvent(t)
+
+	svc, err := services.Create(ctx, newService("web", annotations), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := "service/default/web"
+	pt.expectEvent(t, id, "", targetproviders.ActionStartProxy)
+
+	pcfg, err := pt.client.AddTarget(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pcfg.Hostname != "web" || pcfg.TargetProvider != "k8s" {
+		t.Errorf("proxy config: got hostname %q, provider %q", pcfg.Hostname, pcfg.TargetProvider)
+	}
+	if target := firstTarget(pcfg.Ports, AnnotationPort+"1"); target.Host != "10.0.0.10:80" {
+		t.Errorf("target: got %s", target)
+	}
+
+	// other annotations don't update the proxy
+	svc.Annotations["kubectl.kubernetes.io/x"] = "2"
+	if svc, err = services.Update(ctx, svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectNoEvent(t)
+
+	// port changes of a running proxy only restart the port
+	svc.Annotations[AnnotationPort+"2"] = "8443/https:9090/http"
+	if svc, err = services.Update(ctx, svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, AnnotationPort+"2", targetproviders.ActionRestartPort)
+
+	svc.Annotations[AnnotationName] = "website"
+	if _, err = services.Update(ctx, svc, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, "", targetproviders.ActionRestartProxy)
+
+	if err := services.Delete(ctx, "web", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, "", targetproviders.ActionStopProxy)
+}
+
+func TestPodEvents(t *testing.T) {
+	ctx := context.Background()
+	annotations := map[string]string{
+		AnnotationEnable:     "true",
+		AnnotationPort + "1": "443/https:80/http",
+	}
+	pt := newProviderTest(t)
+	pods := pt.clientset.CoreV1().Pods(testNamespace)
+	id := "pod/default/app"
+
+	// pods start proxies when they are running with an IP
+	pod, err := pods.Create(ctx, newPod("app", annotations, corev1.PodPending, ""), metav1.CreateOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pt.expectNoEvent(t)
+
+	pod.Status = corev1.PodStatus{Phase: corev1.PodRunning, PodIP: "10.1.0.5"}
+	if pod, err = pods.Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, "", targetproviders.ActionStartProxy)
+
+	pcfg, err := pt.client.AddTarget(id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if target := firstTarget(pcfg.Ports, AnnotationPort+"1"); target.Host != "10.1.0.5:80" {
+		t.Errorf("target: got %s", target)
+	}
+
+	pod.Status.Phase = corev1.PodFailed
+	if _, err = pods.Update(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, "", targetproviders.ActionStopProxy)
+
+	if err := pods.Delete(ctx, "app", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	pt.expectEvent(t, id, "", targetproviders.ActionStopProxy)
+}
+
+func TestParseTargetID(t *testing.T) {
+	kind, namespace, name, err := parseTargetID(targetID(KindService, "apps", "web"))
+	if err != nil || kind != KindService || namespace != "apps" || name != "web" {
+		t.Errorf("got %q %q %q %v", kind, namespace, name, err)
+	}
+
+	if _, _, _, err := parseTargetID("service/web"); err == nil {
+		t.Error("invalid id parsed")
+	}
+}
@@ -0,0 +1,395 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package kubernetes
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+
+	"github.com/rs/zerolog"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// resource struct stores the data of an annotated Service or Pod needed to create a proxy.
+type resource struct {
+	log                zerolog.Logger
+	annotations        map[string]string
+	kind               string
+	namespace          string
+	name               string
+	host               string
+	targetProviderName string
+}
+
+var ErrNoTargetHost = errors.New("resource has no address to proxy")
+
+// newServiceResource function returns the resource of a Service.
+// Services are reached by their cluster IP, or by DNS name if headless.
+func newServiceResource(log zerolog.Logger, svc *corev1.Service, clusterDomain string) *resource {
+	host := svc.Spec.ClusterIP
+	switch {
+	case svc.Spec.Type == corev1.ServiceTypeExternalName:
+		host = svc.Spec.ExternalName
+	case host == "" || host == corev1.ClusterIPNone:
+		host = svc.Name + "." + svc.Namespace + ".svc." + clusterDomain
+	}
+
+	return &resource{
+		log:         log.With().Str(KindService, svc.Namespace+targetIDSeparator+svc.Name).Logger(),
+		annotations: svc.Annotations,
+		kind:        KindService,
+		namespace:   svc.Namespace,
+		name:        svc.Name,
+		host:        host,
+	}
+}
+
+// newPodResource function returns the resource of a Pod, reached by the pod IP.
+func newPodResource(log zerolog.Logger, pod *corev1.Pod) *resource {
+	return &resource{
+		log:         log.With().Str(KindPod, pod.Namespace+targetIDSeparator+pod.Name).Logger(),
+		annotations: pod.Annotations,
+		kind:        KindPod,
+		namespace:   pod.Namespace,
+		name:        pod.Name,
+		host:        pod.Status.PodIP,
+	}
+}
+
+// targetID function returns the target id of a resource.
+func targetID(kind, namespace, name string) string {
+	return kind + targetIDSeparator + namespace + targetIDSeparator + name
+}
+
+// parseTargetID function returns kind, namespace and name of a target id.
+func parseTargetID(id string) (string, string, string, error) {
+	parts := strings.SplitN(id, targetIDSeparator, 3) //nolint:mnd
+	if len(parts) != 3 {                              //nolint:mnd
+		return "", "", "", fmt.Errorf("invalid target id %s", id)
+	}
+
+	return parts[0], parts[1], parts[2], nil
+}
+
+// isEnabled function returns true if the annotations enable tsdproxy.
+func isEnabled(annotations map[string]string) bool {
+	enabled, err := strconv.ParseBool(annotations[AnnotationEnable])
+	return err == nil && enabled
+}
+
+// id method returns the target id of the resource.
+func (r *resource) id() string {
+	return targetID(r.kind, r.namespace, r.name)
+}
+
+// newProxyConfig method returns the proxy configuration from the resource annotations.
+func (r *resource) newProxyConfig() (*model.Config, error) {
+	if r.host == "" {
+		return nil, ErrNoTargetHost
+	}
+
+	hostname := r.getAnnotationString(AnnotationName, r.name)
+	if _, err := url.Parse("https://" + hostname); err != nil {
+		return nil, fmt.Errorf("error parsing Hostname: %w", err)
+	}
+
+	pcfg, err := model.NewConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	pcfg.TargetID = r.id()
+	pcfg.Hostname = hostname
+	pcfg.TargetProvider = r.targetProviderName
+	pcfg.ProxyProvider = r.getAnnotationString(AnnotationProxyProvider, model.DefaultProxyProvider)
+	pcfg.AccessLog = model.AccessLog{
+		Enabled: r.getAnnotationBool(AnnotationContainerAccessLog, model.DefaultProxyAccessLog),
+		Format:  r.getAnnotationString(AnnotationAccessLogFormat, ""),
+		Output:  r.getAnnotationString(AnnotationAccessLogOutput, ""),
+		File:    r.getAnnotationString(AnnotationAccessLogFile, ""),
+	}
+	pcfg.Tailscale = model.Tailscale{
+		Ephemeral:    r.getAnnotationBool(AnnotationEphemeral, model.DefaultTailscaleEphemeral),
+		RunWebClient: r.getAnnotationBool(AnnotationRunWebClient, model.DefaultTailscaleRunWebClient),
+		Verbose:      r.getAnnotationBool(AnnotationTsnetVerbose, model.DefaultTailscaleVerbose),
+		AuthKey:      r.getAnnotationString(AnnotationAuthKey, ""),
+		Tags:         r.getAnnotationString(AnnotationTags, ""),
+	}
+	pcfg.Dashboard.Visible = r.getAnnotationBool(AnnotationDashboardVisible, model.DefaultDashboardVisible)
+	pcfg.Dashboard.Label = r.getAnnotationString(AnnotationDashboardLabel, hostname)
+	pcfg.Dashboard.Icon = r.getAnnotationString(AnnotationDashboardIcon, model.DefaultDashboardIcon)
+	pcfg.AccessControl = r.getAccessControl(docker.LabelPrefix)
+	pcfg.RateLimit = r.getRateLimit(docker.LabelPrefix)
+	pcfg.Ports = r.getPorts()
+
+	return pcfg, nil
+}
+
+// getPorts method returns the ports from the tsdproxy.port.<index> annotations.
+// Target ports are the Service ports or the Pod container ports.
+func (r *resource) getPorts() model.PortConfigList {
+	ports := make(model.PortConfigList)
+
+	for k, v := range r.annotations {
+		// skip annotations that aren't ports and port sub annotations
+		if !strings.HasPrefix(k, AnnotationPort) || strings.Contains(strings.TrimPrefix(k, AnnotationPort), ".") {
+			continue
+		}
+
+		parts := strings.Split(v, ",")
+
+		port, err := model.NewPortLongLabel(parts[0])
+		if err != nil {
+			r.log.Error().Err(err).Str("port", k).Msg("error creating port config")
+			continue
+		}
+
+		for _, option := range parts[1:] {
+			switch strings.TrimSpace(option) {
+			case docker.PortOptionNoTLSValidate:
+				port.TLSValidate = false
+			case docker.PortOptionTailscaleFunnel:
+				port.Tailscale.Funnel = true
+			}
+		}
+
+		port.LoadBalance = r.getAnnotationString(k+"."+docker.PortLabelLoadBalance, port.LoadBalance)
+		port.IdleTimeout = r.getAnnotationDuration(k+"."+docker.PortLabelIdleTimeout, 0)
+		port.HealthCheck = r.getHealthCheck(k + ".")
+		port.Transport = r.getTransport(k + ".")
+		port.AccessControl = r.getAccessControl(k + ".")
+		port.RateLimit = r.getRateLimit(k + ".")
+		port.Tailscale.Protection = r.getFunnelProtection(k + ".")
+		port.Cache = r.getCache(k + ".")
+		port.Routes = r.getRoutes(k)
+		port.Headers = model.Headers{
+			Request:  r.getHeaderRules(k + "." + docker.PortLabelHeadersRequest),
+			Response: r.getHeaderRules(k + "." + docker.PortLabelHeadersResponse),
+		}
+
+		if !port.IsRedirect {
+			target := port.GetFirstTarget()
+			targetURL := &url.URL{
+				Scheme: target.Scheme,
+				Host:   net.JoinHostPort(r.host, target.Port()),
+			}
+			port.ReplaceTarget(target, targetURL)
+		}
+
+		ports[k] = port
+	}
+
+	return ports
+}
+
+// getRoutes method returns the path routes from the port sub annotations
+// tsdproxy.port.<index>.route.<name>, sorted by path.
+func (r *resource) getRoutes(portAnnotation string) []model.Route {
+	prefix := portAnnotation + "." + docker.PortLabelRoute
+
+	var routes []model.Route
+	for k, v := range r.annotations {
+		name := strings.TrimPrefix(k, prefix)
+		// skip annotations that aren't routes and route sub annotations
+		if !strings.HasPrefix(k, prefix) || name == "" || strings.Contains(name, ".") {
+			continue
+		}
+
+		route, err := model.NewRouteLabel(v)
+		if err != nil {
+			r.log.Error().Err(err).Str("route", k).Msg("error creating route config")
+			continue
+		}
+
+		route.StripPrefix = r.getAnnotationBool(k+"."+docker.PortLabelRouteStripPrefix, false)
+		route.Rewrite = r.getAnnotationString(k+"."+docker.PortLabelRouteRewrite, "")
+
+		routes = append(routes, route)
+	}
+
+	slices.SortFunc(routes, func(a, b model.Route) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	return routes
+}
+
+// getHeaderRules method returns the header rules from the annotations with the prefix.
+func (r *resource) getHeaderRules(prefix string) model.HeaderRules {
+	rules := model.HeaderRules{
+		Set:    make(map[string]string),
+		Add:    make(map[string]string),
+		Remove: r.getAnnotationList(prefix + docker.LabelHeaderRemove),
+	}
+
+	for k, v := range r.annotations {
+		if name, ok := strings.CutPrefix(k, prefix+docker.LabelHeaderSet); ok {
+			rules.Set[name] = v
+		}
+		if name, ok := strings.CutPrefix(k, prefix+docker.LabelHeaderAdd); ok {
+			rules.Add[name] = v
+		}
+	}
+
+	return rules
+}
+
+// getHealthCheck method returns the health check configuration from the port sub annotations.
+func (r *resource) getHealthCheck(prefix string) model.HealthCheck {
+	checkType := r.getAnnotationString(prefix+docker.PortLabelHealthCheck, "")
+
+	return model.HealthCheck{
+		Enabled:            checkType == model.HealthCheckHTTP || checkType == model.HealthCheckTCP,
+		Type:               checkType,
+		Path:               r.getAnnotationString(prefix+docker.PortLabelHealthCheckPath, ""),
+		Interval:           r.getAnnotationDuration(prefix+docker.PortLabelHealthCheckInterval, 0),
+		Timeout:            r.getAnnotationDuration(prefix+docker.PortLabelHealthCheckTimeout, 0),
+		ExpectedStatus:     r.getAnnotationInt(prefix+docker.PortLabelHealthCheckExpectedStatus, 0),
+		HealthyThreshold:   r.getAnnotationInt(prefix+docker.PortLabelHealthCheckHealthyThreshold, 0),
+		UnhealthyThreshold: r.getAnnotationInt(prefix+docker.PortLabelHealthCheckUnhealthyThreshold, 0),
+	}
+}
+
+// getTransport method returns the timeouts and connection settings from the port sub annotations.
+func (r *resource) getTransport(prefix string) model.Transport {
+	return model.Transport{
+		DialTimeout:           r.getAnnotationDuration(prefix+docker.PortLabelTransportDialTimeout, 0),
+		KeepAlive:             r.getAnnotationDuration(prefix+docker.PortLabelTransportKeepAlive, 0),
+		ResponseHeaderTimeout: r.getAnnotationDuration(prefix+docker.PortLabelTransportResponseHeaderTimeout, 0),
+		IdleConnTimeout:       r.getAnnotationDuration(prefix+docker.PortLabelTransportIdleConnTimeout, 0),
+		ReadHeaderTimeout:     r.getAnnotationDuration(prefix+docker.PortLabelTransportReadHeaderTimeout, 0),
+		ReadTimeout:           r.getAnnotationDuration(prefix+docker.PortLabelTransportReadTimeout, 0),
+		WriteTimeout:          r.getAnnotationDuration(prefix+docker.PortLabelTransportWriteTimeout, 0),
+		MaxIdleConns:          r.getAnnotationInt(prefix+docker.PortLabelTransportMaxIdleConns, 0),
+		MaxIdleConnsPerHost:   r.getAnnotationInt(prefix+docker.PortLabelTransportMaxIdleConnsPerHost, 0),
+	}
+}
+
+// getAccessControl method returns the access control configuration from the annotations with the prefix.
+func (r *resource) getAccessControl(prefix string) model.AccessControl {
+	rules := func(prefix string) model.AccessRules {
+		return model.AccessRules{
+			Users:   r.getAnnotationList(prefix + docker.LabelAccessRuleUsers),
+			UserIDs: r.getAnnotationList(prefix + docker.LabelAccessRuleUserIDs),
+			Tags:    r.getAnnotationList(prefix + docker.LabelAccessRuleTags),
+			Groups:  r.getAnnotationList(prefix + docker.LabelAccessRuleGroups),
+		}
+	}
+
+	return model.AccessControl{
+		Allow:      rules(prefix + docker.LabelAccessAllow),
+		Deny:       rules(prefix + docker.LabelAccessDeny),
+		DeniedPage: r.getAnnotationString(prefix+docker.LabelAccessDeniedPage, ""),
+	}
+}
+
+// getRateLimit method returns the rate limit from the annotations with the prefix.
+func (r *resource) getRateLimit(prefix string) model.RateLimit {
+	limit := r.getAnnotationRateLimit(prefix + docker.LabelRateLimit)
+	if limit.IsEnabled() {
+		limit.Burst = r.getAnnotationInt(prefix+docker.LabelRateLimitBurst, 0)
+	}
+
+	return limit
+}
+
+// getAnnotationRateLimit method returns a rate limit <requests>/<period> from an annotation.
+func (r *resource) getAnnotationRateLimit(annotation string) model.RateLimit {
+	value := r.getAnnotationString(annotation, "")
+	if value == "" {
+		return model.RateLimit{}
+	}
+
+	limit, err := model.ParseRateLimit(value)
+	if err != nil {
+		r.log.Warn().Err(err).Str("annotation", annotation).Msg("invalid rate limit in annotation")
+		return model.RateLimit{}
+	}
+
+	return limit
+}
+
+// getFunnelProtection method returns the Funnel protections from the port sub annotations.
+func (r *resource) getFunnelProtection(prefix string) model.FunnelProtection {
+	protection := model.FunnelProtection{
+		BasicAuth:    r.getAnnotationList(prefix + docker.PortLabelFunnelBasicAuth),
+		BearerTokens: r.getAnnotationList(prefix + docker.PortLabelFunnelBearerTokens),
+		Allow:        r.getAnnotationList(prefix + docker.PortLabelFunnelAllow),
+		Deny:         r.getAnnotationList(prefix + docker.PortLabelFunnelDeny),
+		RateLimit:    r.getRateLimit(prefix + docker.PortLabelFunnel),
+		FailureLimit: r.getAnnotationRateLimit(prefix + docker.PortLabelFunnelFailureLimit),
+		ShareLinkTTL: r.getAnnotationDuration(prefix+docker.PortLabelFunnelShareLinkTTL, 0),
+		SessionTTL:   r.getAnnotationDuration(prefix+docker.PortLabelFunnelSessionTTL, 0),
+		ShareLinks:   r.getAnnotationBool(prefix+docker.PortLabelFunnelShareLinks, false),
+	}
+
+	// the Funnel gate denies all the internet clients with invalid CIDRs
+	if err := protection.Validate(); err != nil {
+		r.log.Error().Err(err).Str("annotation", prefix).Msg("invalid funnel protection in annotations")
+	}
+
+	return protection
+}
+
+// getCache method returns the response cache from the port sub annotations.
+func (r *resource) getCache(prefix string) model.Cache {
+	return model.Cache{
+		Enabled:      r.getAnnotationBool(prefix+docker.PortLabelCache, false),
+		Dir:          r.getAnnotationString(prefix+docker.PortLabelCacheDir, ""),
+		MaxSize:      r.getAnnotationInt(prefix+docker.PortLabelCacheMaxSize, 0),
+		MaxEntrySize: r.getAnnotationInt(prefix+docker.PortLabelCacheMaxEntrySize, 0),
+	}
+}
+
+// getAnnotationString method returns a string from an annotation.
+func (r *resource) getAnnotationString(annotation string, defaultValue string) string {
+	if value, ok := r.annotations[annotation]; ok {
+		return value
+	}
+	return defaultValue
+}
+
+// getAnnotationBool method returns a bool from an annotation.
+func (r *resource) getAnnotationBool(annotation string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(r.annotations[annotation]); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getAnnotationInt method returns an int from an annotation.
+func (r *resource) getAnnotationInt(annotation string, defaultValue int) int {
+	if value, err := strconv.Atoi(r.annotations[annotation]); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getAnnotationDuration method returns a duration from an annotation.
+func (r *resource) getAnnotationDuration(annotation string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(r.annotations[annotation]); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getAnnotationList method returns a list from a comma separated annotation.
+func (r *resource) getAnnotationList(annotation string) []string {
+	var values []string
+	for _, v := range strings.Split(r.annotations[annotation], ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
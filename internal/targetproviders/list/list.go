@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"reflect"
 	"sync"
+	"time"
 
 	"github.com/xybydy/tsdproxy/internal/config"
 	"github.com/xybydy/tsdproxy/internal/model"
@@ -37,17 +38,36 @@ type (
 	configProxyList map[string]proxyConfig
 
 	proxyConfig struct {
-		Dashboard     model.Dashboard `validate:"dive" yaml:"dashboard"`
-		Ports         map[string]port `yaml:"ports"`
-		ProxyProvider string          `yaml:"proxyProvider"`
-		Tailscale     model.Tailscale `yaml:"tailscale"`
+		Dashboard     model.Dashboard     `validate:"dive" yaml:"dashboard"`
+		Ports         map[string]port     `yaml:"ports"`
+		ProxyProvider string              `yaml:"proxyProvider"`
+		Tailscale     model.Tailscale     `yaml:"tailscale"`
+		AccessControl model.AccessControl `yaml:"accessControl,omitempty"`
+		AccessLog     model.AccessLog     `validate:"dive" yaml:"accessLog,omitempty"`
+		RateLimit     model.RateLimit     `yaml:"rateLimit,omitempty"`
 	}
 
 	port struct {
-		Targets     []string            `yaml:"targets,omitempty"`
-		Tailscale   model.TailscalePort `validate:"dive" yaml:"tailscale"`
-		IsRedirect  bool                `default:"false" validate:"boolean" yaml:"isRedirect,omitempty"`
-		TLSValidate bool                `validate:"boolean" default:"true" yaml:"tlsValidate"`
+		Targets       []string            `yaml:"targets,omitempty"`
+		LoadBalance   string              `validate:"omitempty,oneof=roundrobin leastconn random" yaml:"loadBalance,omitempty"`
+		HealthCheck   model.HealthCheck   `validate:"dive" yaml:"healthCheck,omitempty"`
+		AccessControl model.AccessControl `yaml:"accessControl,omitempty"`
+		Routes        []route             `validate:"dive" yaml:"routes,omitempty"`
+		Headers       model.Headers       `validate:"dive" yaml:"headers,omitempty"`
+		Cache         model.Cache         `yaml:"cache,omitempty"`
+		Tailscale     model.TailscalePort `validate:"dive" yaml:"tailscale"`
+		Transport     model.Transport     `yaml:"transport,omitempty"`
+		RateLimit     model.RateLimit     `yaml:"rateLimit,omitempty"`
+		IdleTimeout   time.Duration       `yaml:"idleTimeout,omitempty"`
+		IsRedirect    bool                `default:"false" validate:"boolean" yaml:"isRedirect,omitempty"`
+		TLSValidate   bool                `validate:"boolean" default:"true" yaml:"tlsValidate"`
+	}
+
+	route struct {
+		Path        string   `validate:"required,startswith=/" yaml:"path"`
+		Rewrite     string   `validate:"omitempty,startswith=/" yaml:"rewrite,omitempty"`
+		Targets     []string `yaml:"targets"`
+		StripPrefix bool     `validate:"boolean" yaml:"stripPrefix,omitempty"`
 	}
 )
 
@@ -80,6 +100,7 @@ func New(log zerolog.Logger, name string, provider *config.ListTargetProviderCon
 		file:          file,
 		log:           newlog,
 		name:          name,
+		config:        *provider,
 		configProxies: proxiesList,
 		proxies:       make(map[string]proxyConfig),
 		eventsChan:    make(chan targetproviders.TargetEvent),
@@ -92,9 +113,20 @@ func New(log zerolog.Logger, name string, provider *config.ListTargetProviderCon
 		return nil, fmt.Errorf("error loading defaults: %w", err)
 	}
 
+	c.logValidation()
+
 	return c, nil
 }
 
+// logValidation method logs the errors of the proxies in the list file. Ports
+// with invalid values start with safe defaults, like Funnel ports with invalid
+// CIDRs that deny all the internet clients.
+func (c *Client) logValidation() {
+	if err := c.Validate(); err != nil {
+		c.log.Error().Err(err).Msg("invalid proxies in list file")
+	}
+}
+
 func (c *Client) WatchEvents(_ context.Context, eventsChan chan targetproviders.TargetEvent, errChan chan error) {
 	c.log.Debug().Msg("Start WatchEvents")
 
@@ -120,14 +152,10 @@ func (c *Client) GetDefaultProxyProviderName() string {
 	return c.config.DefaultProxyProvider
 }
 
+// Close method implements TargetProvider Close method.
+// It stops sending events on list file changes, proxies are stopped by the ProxyManager.
 func (c *Client) Close() {
-	for name := range c.proxies {
-		c.eventsChan <- targetproviders.TargetEvent{
-			ID:             name,
-			TargetProvider: c,
-			Action:         targetproviders.ActionStopProxy,
-		}
-	}
+	c.file.OnChange(nil)
 }
 
 func (c *Client) AddTarget(id string) (*model.Config, error) {
@@ -172,8 +200,6 @@ func (c *Client) newProxyConfig(name string, p proxyConfig) (*model.Config, erro
 		proxyProvider = p.ProxyProvider
 	}
 
-	proxyAccessLog := model.DefaultProxyAccessLog
-
 	pcfg, err := model.NewConfig()
 	if err != nil {
 		return nil, err
@@ -184,9 +210,11 @@ func (c *Client) newProxyConfig(name string, p proxyConfig) (*model.Config, erro
 	pcfg.TargetProvider = c.name
 	pcfg.Tailscale = p.Tailscale
 	pcfg.ProxyProvider = proxyProvider
-	pcfg.ProxyAccessLog = proxyAccessLog
+	pcfg.AccessLog = p.AccessLog
 	pcfg.Ports = c.getPorts(p.Ports)
 	pcfg.Dashboard = p.Dashboard
+	pcfg.AccessControl = p.AccessControl
+	pcfg.RateLimit = p.RateLimit
 
 	c.addTarget(p, name)
 
@@ -207,6 +235,7 @@ func (c *Client) onFileChange(e fsnotify.Event) {
 	if err := c.file.Load(); err != nil {
 		c.log.Error().Err(err).Msg("error loading config")
 	}
+	c.logValidation()
 
 	// delete proxies that don't exist in new config
 	for name := range oldConfigProxies {
@@ -232,15 +261,36 @@ func (c *Client) onFileChange(e fsnotify.Event) {
 		// restart if the proxy configuration changed
 		//
 		if !reflect.DeepEqual(c.configProxies[name], oldConfigProxies[name]) {
-			c.eventsChan <- targetproviders.TargetEvent{
-				ID:             name,
-				TargetProvider: c,
-				Action:         targetproviders.ActionRestartProxy,
+			for _, event := range c.getChangeEvents(name, oldConfigProxies[name], c.configProxies[name]) {
+				c.eventsChan <- event
 			}
 		}
 	}
 }
 
+// getChangeEvents method returns the events to apply the changes of a proxy.
+// Running proxies with only port changes get port events, so the other ports
+// and the Tailscale node keep running.
+func (c *Client) getChangeEvents(name string, oldProxy, newProxy proxyConfig) []targetproviders.TargetEvent {
+	c.mtx.Lock()
+	_, running := c.proxies[name]
+	c.mtx.Unlock()
+
+	oldPorts, newPorts := oldProxy.Ports, newProxy.Ports
+	oldProxy.Ports, newProxy.Ports = nil, nil
+
+	if running && reflect.DeepEqual(oldProxy, newProxy) {
+		c.log.Info().Str("proxy", name).Msg("ports changed")
+		return targetproviders.PortEvents(c, name, c.getPorts(oldPorts), c.getPorts(newPorts))
+	}
+
+	return []targetproviders.TargetEvent{{
+		ID:             name,
+		TargetProvider: c,
+		Action:         targetproviders.ActionRestartProxy,
+	}}
+}
+
 // addTarget method add a target the proxies map
 func (c *Client) addTarget(cfg proxyConfig, name string) {
 	c.mtx.Lock()
@@ -260,26 +310,85 @@ func (c *Client) getPorts(l map[string]port) model.PortConfigList {
 
 		port.IsRedirect = v.IsRedirect
 
-		for _, target := range v.Targets {
-			targetURL, err := url.Parse(target)
-			if err != nil || targetURL.Scheme == "" || targetURL.Host == "" {
-				c.log.Error().Err(err).Str("port", k).Str("targetUrl", target).Msg("Invalid target URL")
-				// don't add this port and continue with other targets
-				continue
-			}
-
+		for _, targetURL := range c.getTargets(k, v.Targets) {
 			port.AddTarget(targetURL)
 		}
 
-		if len(port.GetTargets()) == 0 {
+		port.Routes = c.getRoutes(k, v.Routes)
+
+		if len(port.GetTargets()) == 0 && len(port.Routes) == 0 {
 			c.log.Error().Str("port", k).Msg("no targets found for port")
 			continue
 		}
 
 		port.TLSValidate = v.TLSValidate
 		port.Tailscale = v.Tailscale
+		port.HealthCheck = v.HealthCheck
+		port.IdleTimeout = v.IdleTimeout
+		port.AccessControl = v.AccessControl
+		port.Headers = v.Headers
+		port.Transport = v.Transport
+		port.RateLimit = v.RateLimit
+		port.Cache = v.Cache
+		if v.LoadBalance != "" {
+			port.LoadBalance = v.LoadBalance
+		}
 
 		ports[k] = port
 	}
 	return ports
 }
+
+// getRoutes returns the routes of a port from the config
+func (c *Client) getRoutes(portName string, l []route) []model.Route {
+	routes := make([]model.Route, 0, len(l))
+	for _, v := range l {
+		r := model.Route{
+			Path:        v.Path,
+			StripPrefix: v.StripPrefix,
+			Rewrite:     v.Rewrite,
+		}
+
+		for _, targetURL := range c.getTargets(portName, v.Targets) {
+			r.AddTarget(targetURL)
+		}
+
+		if len(r.GetTargets()) == 0 {
+			c.log.Error().Str("port", portName).Str("route", v.Path).Msg("no targets found for route")
+			continue
+		}
+
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// getTargets returns the valid target URLs of a port
+func (c *Client) getTargets(portName string, l []string) []*url.URL {
+	targets := make([]*url.URL, 0, len(l))
+	for _, target := range l {
+		targetURL, err := parseTarget(target)
+		if err != nil {
+			c.log.Error().Err(err).Str("port", portName).Str("targetUrl", target).Msg("Invalid target URL")
+			// don't add this target and continue with other targets
+			continue
+		}
+
+		targets = append(targets, targetURL)
+	}
+	return targets
+}
+
+// parseTarget function returns the URL of a target, that must have a scheme and a host.
+func parseTarget(target string) (*url.URL, error) {
+	targetURL, err := url.Parse(target)
+	if err != nil {
+		return nil, err
+	}
+
+	if targetURL.Scheme == "" || targetURL.Host == "" {
+		return nil, ErrInvalidTarget
+	}
+
+	return targetURL, nil
+}
@@ -0,0 +1,162 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package list
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+var (
+	ErrInvalidTarget         = errors.New("target URL must have a scheme and a host")
+	ErrNoPorts               = errors.New("no ports defined")
+	ErrNoTargets             = errors.New("no targets or routes defined")
+	ErrInvalidLoadBalance    = errors.New("invalid load balance strategy")
+	ErrProxyProviderNotFound = errors.New("proxy provider not found")
+	ErrInvalidRateLimit      = errors.New("rate limit requests, period and burst can't be negative")
+	ErrInvalidFunnelCIDR     = model.ErrInvalidFunnelCIDR
+	ErrInvalidBasicAuth      = model.ErrInvalidFunnelBasicAuth
+	ErrInvalidAccessLog      = errors.New("invalid access log format or output")
+	ErrInvalidCache          = errors.New("cache sizes can't be negative")
+)
+
+// Names method returns the sorted names of the proxies in the list file.
+func (c *Client) Names() []string {
+	return slices.Sorted(maps.Keys(c.configProxies))
+}
+
+// Validate method returns the errors of the proxies in the list file,
+// including the ports and targets that would be skipped when starting them.
+func (c *Client) Validate() error {
+	var errs error
+
+	for _, name := range c.Names() {
+		p := c.configProxies[name]
+
+		if p.ProxyProvider != "" && !config.Get().HasProxyProvider(p.ProxyProvider) {
+			errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: %w: %s", c.name, name, ErrProxyProviderNotFound, p.ProxyProvider))
+		}
+
+		if !validRateLimit(p.RateLimit) {
+			errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: %w", c.name, name, ErrInvalidRateLimit))
+		}
+
+		if !validAccessLog(p.AccessLog) {
+			errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: %w", c.name, name, ErrInvalidAccessLog))
+		}
+
+		if len(p.Ports) == 0 {
+			errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: %w", c.name, name, ErrNoPorts))
+		}
+
+		for _, portName := range slices.Sorted(maps.Keys(p.Ports)) {
+			for _, err := range validatePort(portName, p.Ports[portName]) {
+				errs = errors.Join(errs, fmt.Errorf("list %s: proxy %s: port %s: %w", c.name, name, portName, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validatePort function returns the errors of a port of the list file.
+func validatePort(name string, p port) []error {
+	var errs []error
+
+	if _, err := model.NewPortShortLabel(name); err != nil {
+		errs = append(errs, err)
+	}
+
+	switch p.LoadBalance {
+	case "", model.LoadBalanceRoundRobin, model.LoadBalanceLeastConn, model.LoadBalanceRandom:
+	default:
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidLoadBalance, p.LoadBalance))
+	}
+
+	if !validRateLimit(p.RateLimit) {
+		errs = append(errs, ErrInvalidRateLimit)
+	}
+
+	if p.Cache.MaxSize < 0 || p.Cache.MaxEntrySize < 0 {
+		errs = append(errs, ErrInvalidCache)
+	}
+
+	errs = append(errs, validateFunnelProtection(p.Tailscale.Protection)...)
+
+	valid := len(p.Routes) > 0
+	for _, target := range p.Targets {
+		if _, err := parseTarget(target); err != nil {
+			errs = append(errs, fmt.Errorf("target %s: %w", target, err))
+			continue
+		}
+		valid = true
+	}
+
+	for _, r := range p.Routes {
+		if !strings.HasPrefix(r.Path, "/") {
+			errs = append(errs, fmt.Errorf("route %s: %w", r.Path, model.ErrInvalidRoutePath))
+		}
+		if r.Rewrite != "" && !strings.HasPrefix(r.Rewrite, "/") {
+			errs = append(errs, fmt.Errorf("route %s: rewrite %s: %w", r.Path, r.Rewrite, model.ErrInvalidRoutePath))
+		}
+		if len(r.Targets) == 0 {
+			errs = append(errs, fmt.Errorf("route %s: %w", r.Path, ErrNoTargets))
+		}
+		for _, target := range r.Targets {
+			if _, err := parseTarget(target); err != nil {
+				errs = append(errs, fmt.Errorf("route %s: target %s: %w", r.Path, target, err))
+			}
+		}
+	}
+
+	if !valid {
+		errs = append(errs, ErrNoTargets)
+	}
+
+	return errs
+}
+
+// validRateLimit function returns false if a value of the rate limit is negative.
+func validRateLimit(r model.RateLimit) bool {
+	return r.Requests >= 0 && r.Period >= 0 && r.Burst >= 0
+}
+
+// validAccessLog function returns false if the access log format or output
+// isn't supported.
+func validAccessLog(a model.AccessLog) bool {
+	switch a.Format {
+	case "", model.AccessLogFormatCommon, model.AccessLogFormatCombined, model.AccessLogFormatJSON:
+	default:
+		return false
+	}
+
+	switch a.Output {
+	case "", model.AccessLogOutputLog, model.AccessLogOutputFile, model.AccessLogOutputSyslog:
+		return true
+	default:
+		return false
+	}
+}
+
+// validateFunnelProtection function returns the errors of the Funnel protections of a port.
+func validateFunnelProtection(f model.FunnelProtection) []error {
+	var errs []error
+
+	// each error of the protections is reported with the port
+	if joined, ok := f.Validate().(interface{ Unwrap() []error }); ok {
+		errs = append(errs, joined.Unwrap()...)
+	}
+
+	if !validRateLimit(f.RateLimit) || !validRateLimit(f.FailureLimit) {
+		errs = append(errs, fmt.Errorf("funnel: %w", ErrInvalidRateLimit))
+	}
+
+	return errs
+}
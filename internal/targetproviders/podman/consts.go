@@ -0,0 +1,17 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package podman
+
+const (
+	// libpod REST API, supported by Podman 4 and later
+	libpodAPIPrefix = "/v4.0.0/libpod"
+
+	// libpod events, containers emit "died" instead of the Docker "die"
+	eventTypeContainer = "container"
+	eventActionStart   = "start"
+	eventActionDied    = "died"
+
+	// default network of rootful Podman, used to reach containers in host mode
+	defaultNetwork = "podman"
+)
@@ -0,0 +1,323 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+
+	ctypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/network"
+	"github.com/docker/go-connections/nat"
+)
+
+type (
+	// libpodClient struct is a client of the Podman libpod REST API.
+	libpodClient struct {
+		client  *http.Client
+		baseURL string
+	}
+
+	// listContainer struct is a container returned by the list endpoint.
+	listContainer struct {
+		Labels map[string]string `json:"Labels"`
+		ID     string            `json:"Id"`
+	}
+
+	// inspectContainer struct is the libpod container inspect data used by the
+	// provider. Containers of a pod have the network data in the pod infra container.
+	inspectContainer struct {
+		Config struct {
+			Labels   map[string]string `json:"Labels"`
+			Hostname string            `json:"Hostname"`
+			Image    string            `json:"Image"`
+		} `json:"Config"`
+		HostConfig struct {
+			PortBindings map[string][]portBinding `json:"PortBindings"`
+			NetworkMode  string                   `json:"NetworkMode"`
+		} `json:"HostConfig"`
+		NetworkSettings struct {
+			Ports    map[string][]portBinding `json:"Ports"`
+			Networks map[string]struct {
+				IPAddress string `json:"IPAddress"`
+				Gateway   string `json:"Gateway"`
+			} `json:"Networks"`
+		} `json:"NetworkSettings"`
+		ID   string `json:"Id"`
+		Name string `json:"Name"`
+		Pod  string `json:"Pod"`
+	}
+
+	// portBinding struct is a published port of a container.
+	portBinding struct {
+		HostIP   string `json:"HostIp"`
+		HostPort string `json:"HostPort"`
+	}
+
+	// inspectPod struct is the libpod pod inspect data.
+	inspectPod struct {
+		InfraContainerID string `json:"InfraContainerID"`
+	}
+
+	// inspectNetwork struct is the libpod network inspect data.
+	inspectNetwork struct {
+		Subnets []struct {
+			Gateway string `json:"gateway"`
+		} `json:"subnets"`
+	}
+
+	// event struct is a libpod event.
+	event struct {
+		Type   string `json:"Type"`
+		Action string `json:"Action"`
+		Actor  struct {
+			Attributes map[string]string `json:"Attributes"`
+			ID         string            `json:"ID"`
+		} `json:"Actor"`
+	}
+
+	// errorResponse struct is the body of libpod errors.
+	errorResponse struct {
+		Message string `json:"message"`
+	}
+)
+
+var (
+	ErrUnsupportedHost = errors.New("unsupported podman host, use unix://, tcp://, http:// or https://")
+	ErrLibpodRequest   = errors.New("podman request failed")
+)
+
+// newLibpodClient function returns a libpod client for the host, a unix
+// socket like unix:///run/podman/podman.sock or a tcp address.
+func newLibpodClient(host string) (*libpodClient, error) {
+	u, err := url.Parse(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid podman host: %w", err)
+	}
+
+	switch u.Scheme {
+	case "unix":
+		dialer := &net.Dialer{}
+		transport := &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", u.Path)
+			},
+		}
+		return &libpodClient{
+			client:  &http.Client{Transport: transport},
+			baseURL: "http://podman" + libpodAPIPrefix,
+		}, nil
+
+	case "tcp", "http":
+		return &libpodClient{client: &http.Client{}, baseURL: "http://" + u.Host + libpodAPIPrefix}, nil
+
+	case "https":
+		return &libpodClient{client: &http.Client{}, baseURL: "https://" + u.Host + libpodAPIPrefix}, nil
+	}
+
+	return nil, ErrUnsupportedHost
+}
+
+// listContainers method returns the running containers with the label.
+func (l *libpodClient) listContainers(ctx context.Context, label string) ([]listContainer, error) {
+	var containers []listContainer
+
+	err := l.getJSON(ctx, "/containers/json", url.Values{"filters": {filters("label", label)}}, &containers)
+
+	return containers, err
+}
+
+// inspectContainer method returns the inspect data of a container.
+func (l *libpodClient) inspectContainer(ctx context.Context, id string) (inspectContainer, error) {
+	var container inspectContainer
+
+	err := l.getJSON(ctx, "/containers/"+url.PathEscape(id)+"/json", nil, &container)
+
+	return container, err
+}
+
+// inspectPod method returns the inspect data of a pod.
+func (l *libpodClient) inspectPod(ctx context.Context, id string) (inspectPod, error) {
+	var pod inspectPod
+
+	err := l.getJSON(ctx, "/pods/"+url.PathEscape(id)+"/json", nil, &pod)
+
+	return pod, err
+}
+
+// inspectNetwork method returns the inspect data of a network.
+func (l *libpodClient) inspectNetwork(ctx context.Context, name string) (inspectNetwork, error) {
+	var network inspectNetwork
+
+	err := l.getJSON(ctx, "/networks/"+url.PathEscape(name)+"/json", nil, &network)
+
+	return network, err
+}
+
+// events method streams the container events with the label until the
+// context is canceled or the stream ends.
+func (l *libpodClient) events(ctx context.Context, label string, actions ...string) (<-chan event, <-chan error) {
+	eventsChan := make(chan event)
+	errChan := make(chan error, 1)
+
+	pairs := []string{"type", eventTypeContainer, "label", label}
+	for _, action := range actions {
+		pairs = append(pairs, "event", action)
+	}
+
+	query := url.Values{
+		"stream":  {"true"},
+		"filters": {filters(pairs...)},
+	}
+
+	go func() {
+		defer close(eventsChan)
+
+		resp, err := l.get(ctx, "/events", query)
+		if err != nil {
+			errChan <- err
+			return
+		}
+		defer resp.Body.Close()
+
+		decoder := json.NewDecoder(resp.Body)
+		for {
+			var e event
+			if err := decoder.Decode(&e); err != nil {
+				if ctx.Err() == nil && !errors.Is(err, io.EOF) {
+					errChan <- fmt.Errorf("error reading podman events: %w", err)
+				}
+				return
+			}
+
+			select {
+			case eventsChan <- e:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return eventsChan, errChan
+}
+
+// getJSON method decodes the JSON response of a GET request into v.
+func (l *libpodClient) getJSON(ctx context.Context, path string, query url.Values, v any) error {
+	resp, err := l.get(ctx, path, query)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		return fmt.Errorf("error decoding podman response: %w", err)
+	}
+
+	return nil
+}
+
+// get method sends a GET request and returns the response if successful.
+func (l *libpodClient) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	reqURL := l.baseURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error creating podman request: %w", err)
+	}
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to podman: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+
+		var e errorResponse
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+
+		return nil, fmt.Errorf("%w: %s %s", ErrLibpodRequest, resp.Status, e.Message)
+	}
+
+	return resp, nil
+}
+
+// setNetwork method replaces the network data with the data of the pod infra
+// container, as the containers of a pod share its network.
+func (i *inspectContainer) setNetwork(infra inspectContainer) {
+	i.HostConfig.NetworkMode = infra.HostConfig.NetworkMode
+	i.HostConfig.PortBindings = infra.HostConfig.PortBindings
+	i.NetworkSettings = infra.NetworkSettings
+}
+
+// toDocker method returns the Docker inspect data of the container, used to
+// read the container labels like the Docker provider.
+func (i *inspectContainer) toDocker() ctypes.InspectResponse {
+	dcontainer := ctypes.InspectResponse{
+		ContainerJSONBase: &ctypes.ContainerJSONBase{
+			ID:   i.ID,
+			Name: i.Name,
+			HostConfig: &ctypes.HostConfig{
+				NetworkMode:  ctypes.NetworkMode(i.HostConfig.NetworkMode),
+				PortBindings: toPortMap(i.HostConfig.PortBindings),
+			},
+		},
+		Config: &ctypes.Config{
+			Hostname: i.Config.Hostname,
+			Image:    i.Config.Image,
+			Labels:   i.Config.Labels,
+		},
+		NetworkSettings: &ctypes.NetworkSettings{
+			Networks: make(map[string]*network.EndpointSettings),
+		},
+	}
+
+	dcontainer.NetworkSettings.Ports = toPortMap(i.NetworkSettings.Ports)
+	for name, n := range i.NetworkSettings.Networks {
+		dcontainer.NetworkSettings.Networks[name] = &network.EndpointSettings{
+			IPAddress: n.IPAddress,
+			Gateway:   n.Gateway,
+		}
+	}
+
+	return dcontainer
+}
+
+// toPortMap function returns the Docker port map of the libpod ports.
+func toPortMap(ports map[string][]portBinding) nat.PortMap {
+	portMap := make(nat.PortMap, len(ports))
+	for port, bindings := range ports {
+		if bindings == nil {
+			portMap[nat.Port(port)] = nil
+			continue
+		}
+		for _, b := range bindings {
+			portMap[nat.Port(port)] = append(portMap[nat.Port(port)], nat.PortBinding{HostIP: b.HostIP, HostPort: b.HostPort})
+		}
+	}
+
+	return portMap
+}
+
+// filters function returns the JSON filters query of libpod from key and
+// value pairs.
+func filters(pairs ...string) string {
+	f := make(map[string][]string)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		f[pairs[i]] = append(f[pairs[i]], pairs[i+1])
+	}
+
+	b, _ := json.Marshal(f)
+
+	return string(b)
+}
@@ -0,0 +1,234 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package podman
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+
+	"github.com/rs/zerolog"
+)
+
+type (
+	// Client struct implements TargetProvider
+	Client struct {
+		libpod                *libpodClient
+		log                   zerolog.Logger
+		containers            map[string]string
+		name                  string
+		defaultTargetHostname string
+		defaultProxyProvider  string
+		defaultBridgeAddress  string
+		tryInternalNetwork    bool
+
+		mutex sync.Mutex
+	}
+)
+
+var _ targetproviders.TargetProvider = (*Client)(nil)
+
+// New function returns a new Podman TargetProvider
+func New(log zerolog.Logger, name string, provider *config.PodmanTargetProviderConfig) (*Client, error) {
+	newlog := log.With().Str("podman", name).Logger()
+
+	libpod, err := newLibpodClient(provider.Host)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Client{
+		libpod:                libpod,
+		log:                   newlog,
+		name:                  name,
+		defaultTargetHostname: provider.TargetHostname,
+		defaultProxyProvider:  provider.DefaultProxyProvider,
+		tryInternalNetwork:    provider.TryInternalNetwork,
+		containers:            make(map[string]string),
+	}
+
+	c.setDefaultBridgeAddress()
+
+	return c, nil
+}
+
+// Close method implements TargetProvider Close method.
+func (c *Client) Close() {
+	c.libpod.client.CloseIdleConnections()
+}
+
+// AddTarget method implements TargetProvider AddTarget method
+func (c *Client) AddTarget(id string) (*model.Config, error) {
+	ctx := context.Background()
+
+	pcontainer, err := c.inspect(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	pcfg, err := docker.NewContainerProxyConfig(c.log, pcontainer.toDocker(), c.tryInternalNetwork,
+		docker.WithDefaultBridgeAddress(c.defaultBridgeAddress),
+		docker.WithDefaultTargetHostname(c.defaultTargetHostname),
+		docker.WithTargetProviderName(c.name),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error getting proxy config: %w", err)
+	}
+
+	c.mutex.Lock()
+	c.containers[id] = pcontainer.Name
+	c.mutex.Unlock()
+
+	return pcfg, nil
+}
+
+// inspect method returns the container, with the network of its pod if it
+// belongs to one.
+func (c *Client) inspect(ctx context.Context, id string) (inspectContainer, error) {
+	pcontainer, err := c.libpod.inspectContainer(ctx, id)
+	if err != nil {
+		return pcontainer, fmt.Errorf("error inspecting container: %w", err)
+	}
+
+	if pcontainer.Pod == "" {
+		return pcontainer, nil
+	}
+
+	pod, err := c.libpod.inspectPod(ctx, pcontainer.Pod)
+	if err != nil {
+		return pcontainer, fmt.Errorf("error inspecting pod: %w", err)
+	}
+
+	if pod.InfraContainerID != "" && pod.InfraContainerID != pcontainer.ID {
+		infra, err := c.libpod.inspectContainer(ctx, pod.InfraContainerID)
+		if err != nil {
+			return pcontainer, fmt.Errorf("error inspecting pod infra container: %w", err)
+		}
+		pcontainer.setNetwork(infra)
+	}
+
+	return pcontainer, nil
+}
+
+// DeleteProxy method implements TargetProvider DeleteProxy method
+func (c *Client) DeleteProxy(id string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if _, ok := c.containers[id]; !ok {
+		return fmt.Errorf("container %s not found", id)
+	}
+
+	delete(c.containers, id)
+
+	return nil
+}
+
+// GetDefaultProxyProviderName method implements TargetProvider GetDefaultProxyProviderName method
+func (c *Client) GetDefaultProxyProviderName() string {
+	return c.defaultProxyProvider
+}
+
+// RemoveTarget method implements TargetProvider RemoveTarget method
+func (c *Client) RemoveTarget(id string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	delete(c.containers, id)
+}
+
+// WatchEvents method implements TargetProvider WatchEvents method
+func (c *Client) WatchEvents(ctx context.Context, eventsChan chan targetproviders.TargetEvent, errChan chan error) {
+	podmanEventsChan, podmanErrChan := c.libpod.events(ctx, docker.LabelIsEnabled, eventActionStart, eventActionDied)
+
+	go func() {
+		defer func() {
+			close(eventsChan)
+			close(errChan)
+		}()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case e, ok := <-podmanEventsChan:
+				if !ok {
+					// the stream ended, report the error if any
+					select {
+					case err := <-podmanErrChan:
+						errChan <- err
+					default:
+					}
+					return
+				}
+
+				switch e.Action {
+				case eventActionStart:
+					c.log.Info().Str("container", e.Actor.Attributes["name"]).Msg("Container started")
+					eventsChan <- c.getEvent(e.Actor.ID, targetproviders.ActionStartProxy)
+				case eventActionDied:
+					c.log.Info().Str("container", e.Actor.Attributes["name"]).Msg("Container stopped")
+					eventsChan <- c.getEvent(e.Actor.ID, targetproviders.ActionStopProxy)
+				}
+			}
+		}
+	}()
+
+	go c.startAllProxies(ctx, eventsChan, errChan)
+}
+
+// startAllProxies method sends start events for the running containers.
+func (c *Client) startAllProxies(ctx context.Context, eventsChan chan targetproviders.TargetEvent, errChan chan error) {
+	containers, err := c.libpod.listContainers(ctx, docker.LabelIsEnabled)
+	if err != nil {
+		select {
+		case errChan <- fmt.Errorf("error listing containers: %w", err):
+		case <-ctx.Done():
+		}
+		return
+	}
+
+	for _, container := range containers {
+		select {
+		case eventsChan <- c.getEvent(container.ID, targetproviders.ActionStartProxy):
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// getEvent method returns a targetproviders.TargetEvent for a container
+func (c *Client) getEvent(id string, action targetproviders.ActionType) targetproviders.TargetEvent {
+	return targetproviders.TargetEvent{
+		TargetProvider: c,
+		ID:             id,
+		Action:         action,
+	}
+}
+
+// setDefaultBridgeAddress method sets the gateway of the default Podman
+// network, used to reach containers in host network mode.
+func (c *Client) setDefaultBridgeAddress() {
+	network, err := c.libpod.inspectNetwork(context.Background(), defaultNetwork)
+	if err != nil {
+		// rootless Podman doesn't use the default network
+		c.log.Debug().Err(err).Msg("Default Podman network not found")
+		return
+	}
+
+	for _, subnet := range network.Subnets {
+		if subnet.Gateway != "" {
+			c.log.Info().Str("defaultIPAdress", subnet.Gateway).Msg("Default Network found")
+			c.defaultBridgeAddress = strings.TrimSpace(subnet.Gateway)
+			return
+		}
+	}
+}
@@ -0,0 +1,197 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package podman
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/targetproviders"
+	"github.com/xybydy/tsdproxy/internal/targetproviders/docker"
+)
+
+const eventTimeout = 5 * time.Second
+
+// startLibpod function starts a fake libpod API on a unix socket with a web
+// container of a pod, and returns the host of the socket.
+func startLibpod(t *testing.T) string {
+	t.Helper()
+
+	web := map[string]any{
+		"Id":   "web",
+		"Name": "web",
+		"Pod":  "pod",
+		"Config": map[string]any{
+			"Image":  "nginx",
+			"Labels": map[string]string{docker.LabelEnable: "true", docker.LabelPort + "1": "443/https:80/http"},
+		},
+		"HostConfig": map[string]any{"NetworkMode": "bridge"},
+	}
+	// the pod infra container publishes the ports of the pod
+	infra := map[string]any{
+		"Id":         "infra",
+		"HostConfig": map[string]any{"NetworkMode": "bridge"},
+		"NetworkSettings": map[string]any{
+			"Ports": map[string]any{"80/tcp": []map[string]string{{"HostIp": "", "HostPort": "8080"}}},
+		},
+	}
+
+	mux := http.NewServeMux()
+	reply := func(path string, v any) {
+		mux.HandleFunc("GET "+libpodAPIPrefix+path, func(w http.ResponseWriter, _ *http.Request) {
+			_ = json.NewEncoder(w).Encode(v)
+		})
+	}
+	reply("/networks/podman/json", map[string]any{"subnets": []map[string]string{{"gateway": "10.88.0.1"}}})
+	reply("/containers/json", []map[string]string{{"Id": "web"}})
+	reply("/containers/web/json", web)
+	reply("/containers/infra/json", infra)
+	reply("/pods/pod/json", map[string]string{"InfraContainerID": "infra"})
+	mux.HandleFunc("GET "+libpodAPIPrefix+"/events", func(w http.ResponseWriter, r *http.Request) {
+		enc := json.NewEncoder(w)
+		for _, action := range []string{eventActionStart, eventActionDied} {
+			_ = enc.Encode(map[string]any{"Type": eventTypeContainer, "Action": action, "Actor": map[string]any{"ID": "web"}})
+		}
+		w.(http.Flusher).Flush()
+		<-r.Context().Done()
+	})
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_ = json.NewEncoder(w).Encode(errorResponse{Message: "no such container"})
+	})
+
+	// unix socket paths are limited in length, t.TempDir is often too long
+	dir, err := os.MkdirTemp("", "podman")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	socket := filepath.Join(dir, "podman.sock")
+	l, err := net.Listen("unix", socket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewUnstartedServer(mux)
+	srv.Listener = l
+	srv.Start()
+	t.Cleanup(srv.Close)
+
+	return "unix://" + socket
+}
+
+// newTestClient function returns a Podman provider of the fake libpod API.
+func newTestClient(t *testing.T) *Client {
+	t.Helper()
+
+	c, err := New(zerolog.Nop(), "podman", &config.PodmanTargetProviderConfig{
+		Host:           startLibpod(t),
+		TargetHostname: "host.containers.internal",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+
+	return c
+}
+
+func TestNewLibpodClient(t *testing.T) {
+	for host, want := range map[string]string{
+		"unix:///run/podman/podman.sock": "http://podman" + libpodAPIPrefix,
+		"tcp://podman:8080":              "http://podman:8080" + libpodAPIPrefix,
+		"https://podman:8443":            "https://podman:8443" + libpodAPIPrefix,
+	} {
+		l, err := newLibpodClient(host)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if l.baseURL != want {
+			t.Errorf("%s: got %s, want %s", host, l.baseURL, want)
+		}
+	}
+
+	if _, err := newLibpodClient("ssh://podman"); !errors.Is(err, ErrUnsupportedHost) {
+		t.Errorf("ssh host: got %v", err)
+	}
+}
+
+func TestAddTarget(t *testing.T) {
+	c := newTestClient(t)
+
+	if c.defaultBridgeAddress != "10.88.0.1" {
+		t.Errorf("default bridge address: got %q", c.defaultBridgeAddress)
+	}
+
+	pcfg, err := c.AddTarget("web")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pcfg.Hostname != "web" || pcfg.TargetProvider != "podman" {
+		t.Errorf("proxy: got %s of %s", pcfg.Hostname, pcfg.TargetProvider)
+	}
+
+	// the port is published by the pod infra container
+	port, ok := pcfg.Ports[docker.LabelPort+"1"]
+	if !ok {
+		t.Fatalf("ports: got %v", pcfg.Ports)
+	}
+	if target := port.GetFirstTarget().String(); target != "http://host.containers.internal:8080" {
+		t.Errorf("target: got %s", target)
+	}
+
+	if err := c.DeleteProxy("web"); err != nil {
+		t.Error(err)
+	}
+	if err := c.DeleteProxy("web"); err == nil {
+		t.Error("deleted an unknown container")
+	}
+
+	if _, err := c.AddTarget("missing"); !errors.Is(err, ErrLibpodRequest) {
+		t.Errorf("missing container: got %v", err)
+	}
+}
+
+func TestWatchEvents(t *testing.T) {
+	c := newTestClient(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	events := make(chan targetproviders.TargetEvent)
+	errs := make(chan error)
+	c.WatchEvents(ctx, events, errs)
+
+	// the running container and the start and died events of the stream
+	actions := make(map[targetproviders.ActionType]int)
+	for range 3 {
+		select {
+		case e := <-events:
+			if e.ID != "web" || e.TargetProvider != c {
+				t.Errorf("event: got %+v", e)
+			}
+			actions[e.Action]++
+		case err := <-errs:
+			t.Fatal(err)
+		case <-time.After(eventTimeout):
+			t.Fatal("no event")
+		}
+	}
+
+	if actions[targetproviders.ActionStartProxy] != 2 || actions[targetproviders.ActionStopProxy] != 1 {
+		t.Errorf("actions: got %v", actions)
+	}
+}
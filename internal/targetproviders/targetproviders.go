@@ -5,6 +5,7 @@ package targetproviders
 
 import (
 	"context"
+	"reflect"
 
 	"github.com/xybydy/tsdproxy/internal/model"
 )
@@ -36,6 +37,41 @@ type (
 	TargetEvent struct {
 		TargetProvider TargetProvider
 		ID             string
-		Action         ActionType
+		// Port is the port name of the port actions
+		Port   string
+		Action ActionType
 	}
 )
+
+// PortEvents function returns the port events that update the ports of a
+// running proxy from oldPorts to newPorts.
+func PortEvents(provider TargetProvider, id string, oldPorts, newPorts model.PortConfigList) []TargetEvent {
+	var events []TargetEvent
+
+	newEvent := func(port string, action ActionType) TargetEvent {
+		return TargetEvent{
+			TargetProvider: provider,
+			ID:             id,
+			Port:           port,
+			Action:         action,
+		}
+	}
+
+	for name := range oldPorts {
+		if _, ok := newPorts[name]; !ok {
+			events = append(events, newEvent(name, ActionStopPort))
+		}
+	}
+
+	for name, port := range newPorts {
+		oldPort, ok := oldPorts[name]
+		switch {
+		case !ok:
+			events = append(events, newEvent(name, ActionStartPort))
+		case !reflect.DeepEqual(oldPort, port):
+			events = append(events, newEvent(name, ActionRestartPort))
+		}
+	}
+
+	return events
+}
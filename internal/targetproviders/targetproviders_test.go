@@ -0,0 +1,52 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package targetproviders
+
+import (
+	"maps"
+	"testing"
+
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+func newPort(t *testing.T, label string) model.PortConfig {
+	t.Helper()
+
+	pconfig, err := model.NewPortLongLabel(label)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pconfig
+}
+
+func TestPortEvents(t *testing.T) {
+	oldPorts := model.PortConfigList{
+		"web":     newPort(t, "443/https:80/http"),
+		"admin":   newPort(t, "8443/https:8080/http"),
+		"metrics": newPort(t, "9090/https:9090/http"),
+	}
+	newPorts := model.PortConfigList{
+		"web":   newPort(t, "443/https:80/http"),
+		"admin": newPort(t, "8443/https:8081/http"),
+		"db":    newPort(t, "5432/tcp:5432/tcp"),
+	}
+
+	got := make(map[string]ActionType)
+	for _, e := range PortEvents(nil, "nginx", oldPorts, newPorts) {
+		if e.ID != "nginx" {
+			t.Errorf("event id: got %s", e.ID)
+		}
+		got[e.Port] = e.Action
+	}
+
+	want := map[string]ActionType{"admin": ActionRestartPort, "db": ActionStartPort, "metrics": ActionStopPort}
+	if !maps.Equal(got, want) {
+		t.Errorf("events: got %v, want %v", got, want)
+	}
+
+	if events := PortEvents(nil, "nginx", oldPorts, oldPorts); len(events) != 0 {
+		t.Errorf("events without changes: got %d", len(events))
+	}
+}
@@ -0,0 +1,68 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/xybydy/tsdproxy/internal/core"
+	"github.com/xybydy/tsdproxy/internal/model"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Span attributes added by TSDProxy.
+const (
+	AttributeProxy  = attribute.Key("tsdproxy.proxy")
+	AttributePort   = attribute.Key("tsdproxy.port")
+	AttributeTarget = attribute.Key("tsdproxy.target")
+)
+
+// Middleware function starts a server span for each request proxied by a port.
+// The span continues the trace of the client if the request has trace context headers.
+func Middleware(proxy, port string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+		ctx, span := Tracer().Start(ctx, r.Method+" "+port,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				AttributeProxy.String(proxy),
+				AttributePort.String(port),
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.ServerAddress(r.Host),
+				semconv.ClientAddress(r.RemoteAddr),
+			),
+		)
+		defer span.End()
+
+		rw := core.NewResponseRecorder(w)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(rw.Status))
+		if rw.Status >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.Status))
+		}
+	})
+}
+
+// InjectRequest function adds the target and the Tailscale user to the span of
+// the incoming request and propagates its trace context to the outgoing request.
+func InjectRequest(in, out *http.Request, target string) {
+	ctx := in.Context()
+	span := trace.SpanFromContext(ctx)
+
+	span.SetAttributes(AttributeTarget.String(target))
+	if user, ok := model.WhoisFromContext(ctx); ok {
+		span.SetAttributes(semconv.UserName(user.Username))
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(out.Header))
+}
@@ -0,0 +1,131 @@
+// SPDX-FileCopyrightText: 2026 Fatih Ka. <xybydy@gmail.com>
+// SPDX-License-Identifier: MIT
+
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/xybydy/tsdproxy/internal/config"
+	"github.com/xybydy/tsdproxy/internal/model"
+)
+
+// clientTraceparent is the trace context sent by the client of the tests.
+const clientTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// recordSpans function sets a global tracer provider that records the spans,
+// restored when the test ends.
+func recordSpans(t *testing.T) *tracetest.SpanRecorder {
+	t.Helper()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prevProvider, prevPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTracerProvider(prevProvider)
+		otel.SetTextMapPropagator(prevPropagator)
+		_ = provider.Shutdown(context.Background())
+	})
+
+	return recorder
+}
+
+// attributes function returns the attributes of a span as a map.
+func attributes(span sdktrace.ReadOnlySpan) map[attribute.Key]attribute.Value {
+	attrs := make(map[attribute.Key]attribute.Value)
+	for _, kv := range span.Attributes() {
+		attrs[kv.Key] = kv.Value
+	}
+
+	return attrs
+}
+
+func TestMiddleware(t *testing.T) {
+	recorder := recordSpans(t)
+
+	var outgoing http.Header
+	handler := Middleware("web", "443/https", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		out := httptest.NewRequest(http.MethodGet, "http://10.0.0.1/", nil)
+		InjectRequest(r, out, "http://10.0.0.1")
+		outgoing = out.Header
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+
+	r := httptest.NewRequest(http.MethodGet, "/index.html", nil)
+	r.Header.Set("Traceparent", clientTraceparent)
+	r = r.WithContext(model.WhoisNewContext(r.Context(), model.Whois{ID: "1", Username: "alice@example.com"}))
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("spans: got %d, want 1", len(spans))
+	}
+	span := spans[0]
+
+	if span.SpanKind() != trace.SpanKindServer || span.Name() != "GET 443/https" {
+		t.Errorf("span: got %s %s", span.SpanKind(), span.Name())
+	}
+	if got := span.SpanContext().TraceID().String(); got != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("client trace not continued: got trace %s", got)
+	}
+	if span.Status().Code != codes.Error {
+		t.Errorf("5xx status not recorded as error: %v", span.Status())
+	}
+
+	attrs := attributes(span)
+	for key, want := range map[attribute.Key]string{
+		AttributeProxy:  "web",
+		AttributePort:   "443/https",
+		AttributeTarget: "http://10.0.0.1",
+		"user.name":     "alice@example.com",
+	} {
+		if got := attrs[key].AsString(); got != want {
+			t.Errorf("attribute %s: got %q, want %q", key, got, want)
+		}
+	}
+	if got := attrs["http.response.status_code"].AsInt64(); got != http.StatusBadGateway {
+		t.Errorf("status code attribute: got %d", got)
+	}
+
+	// the target receives the trace context of the proxy span
+	sc := trace.SpanContextFromContext(otel.GetTextMapPropagator().Extract(context.Background(),
+		propagation.HeaderCarrier(outgoing)))
+	if sc.TraceID() != span.SpanContext().TraceID() || sc.SpanID() != span.SpanContext().SpanID() {
+		t.Errorf("trace context not propagated to the target: %v", outgoing)
+	}
+}
+
+func TestInitDisabled(t *testing.T) {
+	shutdown, err := Init(context.Background(), config.TracingConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := shutdown(context.Background()); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestSampleRatio(t *testing.T) {
+	ratio := 0.0
+
+	if got := (config.TracingConfig{}).GetSampleRatio(); got != 1 {
+		t.Errorf("default sample ratio: got %v, want 1", got)
+	}
+	if got := (config.TracingConfig{SampleRatio: &ratio}).GetSampleRatio(); got != 0 {
+		t.Errorf("sample ratio: got %v, want 0", got)
+	}
+}
@@ -59,3 +59,8 @@ func GuessIcon(name string) string {
 	icon := strings.TrimPrefix(foundFile, "dist/icons/")
 	return strings.TrimSuffix(icon, ".svg")
 }
+
+// Icon function returns the SVG image of an icon, like mdi/server.
+func Icon(name string) ([]byte, error) {
+	return dist.ReadFile("dist/icons/" + name + ".svg")
+}